@@ -1,18 +1,24 @@
 package api
 
 import (
+	"context"
 	"log"
 	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/shani34/book-management-system/config"
 	"github.com/shani34/book-management-system/internal/handlers"
 	"github.com/shani34/book-management-system/internal/middleware"
+	"github.com/shani34/book-management-system/internal/models"
+	"github.com/shani34/book-management-system/internal/policy"
 	"github.com/shani34/book-management-system/internal/repositories"
 	"github.com/shani34/book-management-system/internal/services"
+	"github.com/shani34/book-management-system/pkg/cache"
 	"github.com/shani34/book-management-system/pkg/db"
-	"github.com/shani34/book-management-system/pkg/kafka"
-	"github.com/shani34/book-management-system/pkg/redis"
+	"github.com/shani34/book-management-system/pkg/events"
+	"github.com/shani34/book-management-system/pkg/jwt"
+	"github.com/shani34/book-management-system/pkg/ratelimit"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	"go.uber.org/zap"
@@ -20,6 +26,9 @@ import (
 
 func SetupRouter() *gin.Engine {
 	router := gin.Default()
+	if err := router.SetTrustedProxies(config.Get().Server.TrustedProxies); err != nil {
+		log.Fatalf("invalid TRUSTED_PROXIES: %v", err)
+	}
 
 	logger, err := zap.NewProduction()
 	if err!=nil{
@@ -29,9 +38,9 @@ func SetupRouter() *gin.Engine {
 
 	router.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"*"}, 
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "X-API-Key", "X-Tenant-ID", "If-Match", "If-None-Match"},
+		ExposeHeaders:    []string{"Content-Length", "Link", "ETag", "Retry-After", "X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}))
@@ -41,30 +50,172 @@ func SetupRouter() *gin.Engine {
 	router.Use(gin.Recovery())
 
 	// Initialize dependencies
-	db, err := db.InitDB()
-	if err != nil {
-		gin.Logger()
+	stores := newStores(logger)
+	bookRepo := stores.books
+	bookCache := cache.InitCache()
+	// Without a sink the relay is not started, so events stay pending in
+	// the outbox rather than being marked sent without going anywhere.
+	publisher, err := events.InitPublisher()
+	switch {
+	case err != nil:
+		logger.Error("failed to initialize event publisher, events will stay in the outbox", zap.Error(err))
+	case publisher == events.Noop{}:
+		logger.Warn("no events sink configured, events will stay in the outbox")
+	default:
+		relay := services.NewOutboxRelay(bookRepo, publisher, logger, config.Get().Events.RelayInterval)
+		go relay.Run(context.Background())
 	}
-	redisClient, err := redis.InitRedis()
-	if err != nil {
-		gin.Logger()
-	}
-	kafka.InitKafkaProducer()
-	bookRepo := repositories.NewBookRepository(db)
 
-	bookService := services.NewBookService(bookRepo, redisClient)
+	bookService := services.NewBookService(bookRepo, bookCache)
 	bookHandler := handlers.NewBookHandler(bookService,logger)
+	authorService := services.NewAuthorService(stores.authors, bookRepo, bookCache)
+	authorHandler := handlers.NewAuthorHandler(authorService, logger)
+	tagService := services.NewTagService(stores.tags, bookRepo, bookCache)
+	tagHandler := handlers.NewTagHandler(tagService, logger)
+	editionService := services.NewEditionService(stores.editions, bookRepo, bookCache)
+	editionHandler := handlers.NewEditionHandler(editionService, logger)
+	circulationService := services.NewCirculationService(stores.circulation, bookRepo, config.Get().Holds.PickupWindow)
+	expirer := services.NewHoldExpirer(circulationService, logger, config.Get().Holds.ExpiryInterval)
+	go expirer.Run(context.Background())
+	circulationHandler := handlers.NewCirculationHandler(circulationService, logger)
+	memberService := services.NewMemberService(stores.members)
+	memberHandler := handlers.NewMemberHandler(memberService, logger)
+	fineService := services.NewFineService(stores.circulation)
+	fineHandler := handlers.NewFineHandler(fineService, logger)
+	apiKeyService := services.NewAPIKeyService(stores.apiKeys)
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyService, logger)
 
 	// API routes
 	v1 := router.Group("/api/v1")
+	auth := config.Get().Auth
+	limit, authFailures := newRateLimiter(logger)
+	var bookPolicy *policy.Policy
+	if auth.Enabled {
+		v1.Use(authFailures)
+		bookPolicy = newPolicy(logger)
+		v1.Use(middleware.Authenticate(newVerifier(logger), apiKeyService, bookPolicy, logger))
+	} else {
+		logger.Warn("authentication is disabled, anyone can change data")
+	}
+	v1.Use(middleware.ResolveTenant(config.Get().Tenancy, logger))
+	// scope checks the caller's scopes for a route group. Only the
+	// catalogue (books, authors and tags) can be opened to anonymous reads;
+	// circulation data always needs credentials.
+	scope := func(catalogue bool) gin.HandlerFunc {
+		if !auth.Enabled {
+			return func(*gin.Context) {}
+		}
+		return middleware.RequireMethodScope(models.ScopeBooksRead, models.ScopeBooksWrite, catalogue && auth.PublicReads)
+	}
+	can := func(perm policy.Permission) gin.HandlerFunc {
+		return middleware.Authorize(bookPolicy, perm, logger)
+	}
 	{
-		books := v1.Group("/books")
+		books := v1.Group("/books", scope(true), limit("books"))
+		{
+			books.GET("", can(policy.BookRead), bookHandler.GetBooks)
+			books.POST("", can(policy.BookCreate), bookHandler.CreateBook)
+			books.GET("/search", can(policy.BookRead), bookHandler.SearchBooks)
+			books.POST("/import", can(policy.BookCreate), bookHandler.ImportBooks)
+			books.GET("/export", can(policy.BookRead), bookHandler.ExportBooks)
+			books.GET("/trash", can(policy.BookRead), bookHandler.ListTrash)
+			books.GET("/isbn/:isbn", can(policy.BookRead), bookHandler.GetBookByISBN)
+			books.DELETE("/trash/:id", can(policy.BookPurge), bookHandler.PurgeBook)
+			books.GET("/:id", can(policy.BookRead), bookHandler.GetBook)
+			books.PUT("/:id", can(policy.BookUpdate), bookHandler.UpdateBook)
+			books.PATCH("/:id", can(policy.BookUpdate), bookHandler.PatchBook)
+			books.DELETE("/:id", can(policy.BookDelete), bookHandler.DeleteBook)
+			books.POST("/:id/restore", can(policy.BookDelete), bookHandler.RestoreBook)
+			books.PUT("/:id/tags/:tag_id", can(policy.BookUpdate), bookHandler.AttachTag)
+			books.DELETE("/:id/tags/:tag_id", can(policy.BookUpdate), bookHandler.DetachTag)
+			books.GET("/:id/editions", editionHandler.GetEditions)
+			books.POST("/:id/editions", can(policy.BookUpdate), editionHandler.CreateEdition)
+			books.GET("/:id/editions/:edition_id", editionHandler.GetEdition)
+			books.PUT("/:id/editions/:edition_id", can(policy.BookUpdate), editionHandler.UpdateEdition)
+			books.DELETE("/:id/editions/:edition_id", can(policy.BookUpdate), editionHandler.DeleteEdition)
+			books.GET("/:id/copies", circulationHandler.GetCopies)
+			books.POST("/:id/copies", can(policy.CopyWrite), circulationHandler.CreateCopy)
+			books.GET("/:id/holds", scope(false), circulationHandler.GetBookHolds)
+			books.POST("/:id/holds", can(policy.HoldWrite), circulationHandler.PlaceHold)
+		}
+
+		copies := v1.Group("/copies", scope(false), limit("copies"))
+		{
+			copies.GET("/:id", circulationHandler.GetCopy)
+			copies.PUT("/:id", can(policy.CopyWrite), circulationHandler.UpdateCopy)
+			copies.DELETE("/:id", can(policy.CopyWrite), circulationHandler.DeleteCopy)
+		}
+
+		loans := v1.Group("/loans", scope(false), limit("loans"))
+		{
+			loans.GET("", circulationHandler.GetLoans)
+			loans.POST("", can(policy.LoanWrite), circulationHandler.Checkout)
+			loans.GET("/:id", circulationHandler.GetLoan)
+			loans.POST("/:id/return", can(policy.LoanWrite), circulationHandler.ReturnLoan)
+		}
+
+		holds := v1.Group("/holds", scope(false), limit("holds"))
+		{
+			holds.GET("", circulationHandler.GetHolds)
+			holds.GET("/:id", circulationHandler.GetHold)
+			holds.POST("/:id/cancel", can(policy.HoldWrite), circulationHandler.CancelHold)
+		}
+
+		members := v1.Group("/members", scope(false), limit("members"))
+		{
+			members.GET("", memberHandler.GetMembers)
+			members.POST("", can(policy.MemberWrite), memberHandler.CreateMember)
+			members.GET("/:id", memberHandler.GetMember)
+			members.PUT("/:id", can(policy.MemberWrite), memberHandler.UpdateMember)
+			members.DELETE("/:id", can(policy.MemberWrite), memberHandler.DeleteMember)
+			members.GET("/:id/fines", fineHandler.GetMemberFines)
+			members.GET("/:id/fines/summary", fineHandler.GetFineSummary)
+			members.GET("/:id/ledger", fineHandler.GetLedger)
+		}
+
+		fines := v1.Group("/fines", scope(false), limit("fines"))
+		{
+			fines.GET("/:id", fineHandler.GetFine)
+			fines.POST("/:id/pay", can(policy.FinePay), fineHandler.PayFine)
+			fines.POST("/:id/waive", can(policy.FineWaive), fineHandler.WaiveFine)
+		}
+
+		tiers := v1.Group("/tiers", scope(false), limit("tiers"))
+		{
+			tiers.GET("", memberHandler.GetTiers)
+			tiers.POST("", can(policy.TierWrite), memberHandler.CreateTier)
+			tiers.GET("/:id", memberHandler.GetTier)
+			tiers.PUT("/:id", can(policy.TierWrite), memberHandler.UpdateTier)
+		}
+
+		authors := v1.Group("/authors", scope(true), limit("authors"))
+		{
+			authors.GET("", authorHandler.GetAuthors)
+			authors.POST("", can(policy.AuthorWrite), authorHandler.CreateAuthor)
+			authors.GET("/:id", authorHandler.GetAuthor)
+			authors.PUT("/:id", can(policy.AuthorWrite), authorHandler.UpdateAuthor)
+			authors.DELETE("/:id", can(policy.AuthorWrite), authorHandler.DeleteAuthor)
+			authors.GET("/:id/books", authorHandler.GetAuthorBooks)
+		}
+
+		admin := v1.Group("/admin", scope(false), limit("admin"))
+		if auth.Enabled {
+			admin.Use(middleware.RequireScope(models.ScopeAdmin))
+		}
+		{
+			admin.GET("/api-keys", apiKeyHandler.GetAPIKeys)
+			admin.POST("/api-keys", apiKeyHandler.IssueAPIKey)
+			admin.GET("/api-keys/:id", apiKeyHandler.GetAPIKey)
+			admin.POST("/api-keys/:id/revoke", apiKeyHandler.RevokeAPIKey)
+		}
+
+		tags := v1.Group("/tags", scope(true), limit("tags"))
 		{
-			books.GET("", bookHandler.GetBooks)
-			books.POST("", bookHandler.CreateBook)
-			books.GET("/:id", bookHandler.GetBook)
-			books.PUT("/:id", bookHandler.UpdateBook)
-			books.DELETE("/:id", bookHandler.DeleteBook)
+			tags.GET("", tagHandler.GetTags)
+			tags.POST("", can(policy.TagWrite), tagHandler.CreateTag)
+			tags.GET("/:id", tagHandler.GetTag)
+			tags.PUT("/:id", can(policy.TagWrite), tagHandler.UpdateTag)
+			tags.DELETE("/:id", can(policy.TagWrite), tagHandler.DeleteTag)
 		}
 	}
 
@@ -73,3 +224,124 @@ func SetupRouter() *gin.Engine {
 
 	return router
 }
+
+// newVerifier builds the token verifier from config. Without a secret or a
+// JWKS file no bearer token can be valid, leaving API keys as the only way
+// in.
+func newVerifier(logger *zap.Logger) *jwt.Verifier {
+	auth := config.Get().Auth
+	verifierConfig := jwt.VerifierConfig{
+		Secret:   []byte(auth.JWTSecret),
+		Issuer:   auth.Issuer,
+		Audience: auth.Audience,
+		Leeway:   auth.Leeway,
+	}
+	if auth.JWKSFile != "" {
+		keys, err := jwt.LoadJWKS(auth.JWKSFile)
+		if err != nil {
+			logger.Fatal("failed to load JWKS", zap.String("file", auth.JWKSFile), zap.Error(err))
+		}
+		verifierConfig.Keys = keys
+	}
+	if len(verifierConfig.Secret) == 0 && len(verifierConfig.Keys) == 0 {
+		logger.Warn("no AUTH_JWT_SECRET or AUTH_JWKS_FILE configured, bearer tokens will be rejected")
+	}
+	return jwt.NewVerifier(verifierConfig)
+}
+
+// newPolicy loads the book access policy from config.
+func newPolicy(logger *zap.Logger) *policy.Policy {
+	rbac := config.Get().RBAC
+	spec := rbac.Roles
+	if spec == "" {
+		spec = policy.DefaultRoles
+	}
+	p, err := policy.Parse(spec, rbac.DefaultRole)
+	if err != nil {
+		logger.Fatal("failed to load access policy", zap.Error(err))
+	}
+	logger.Info("loaded access policy", zap.Strings("roles", p.Roles()), zap.String("default_role", p.DefaultRole()))
+	return p
+}
+
+// newRateLimiter loads the rate limits from config. It returns a function
+// giving the limiting middleware of a route group, which does nothing for
+// groups without a limit, and the middleware limiting failed
+// authentication attempts.
+func newRateLimiter(logger *zap.Logger) (func(group string) gin.HandlerFunc, gin.HandlerFunc) {
+	cfg := config.Get().RateLimit
+	if !cfg.Enabled {
+		logger.Warn("rate limiting is disabled")
+		return func(string) gin.HandlerFunc { return func(*gin.Context) {} }, func(*gin.Context) {}
+	}
+	spec := cfg.Limits
+	if spec == "" {
+		spec = ratelimit.DefaultLimits
+	}
+	limits, err := ratelimit.ParseLimits(spec)
+	if err != nil {
+		logger.Fatal("failed to load rate limits", zap.Error(err))
+	}
+	limiter := ratelimit.InitLimiter()
+	forGroup := func(group string) gin.HandlerFunc {
+		limit, ok := limits.For(group)
+		if !ok {
+			return func(*gin.Context) {}
+		}
+		return middleware.RateLimit(limiter, group, limit, logger)
+	}
+
+	failures, ok := limits[ratelimit.AuthFailuresGroup]
+	if !ok {
+		failures = ratelimit.DefaultAuthFailures
+	}
+	return forGroup, middleware.LimitAuthFailures(limiter, failures, logger)
+}
+
+// stores holds one store per resource, all on the same backend since the
+// resources link to each other.
+type stores struct {
+	books    repositories.BookStore
+	authors  repositories.AuthorStore
+	tags     repositories.TagStore
+	editions repositories.EditionStore
+
+	circulation repositories.CirculationStore
+	members     repositories.MemberStore
+	apiKeys     repositories.APIKeyStore
+}
+
+// newStores picks the storage backend from config. The in-memory store
+// lets the API run without Postgres, e.g. for local development and tests.
+func newStores(logger *zap.Logger) stores {
+	switch config.Get().Storage.Driver {
+	case "memory":
+		logger.Info("using in-memory book store")
+		memory := repositories.NewMemoryDB()
+		return stores{
+			books:    repositories.NewMemoryBookRepository(memory),
+			authors:  repositories.NewMemoryAuthorRepository(memory),
+			tags:     repositories.NewMemoryTagRepository(memory),
+			editions: repositories.NewMemoryEditionRepository(memory),
+
+			circulation: repositories.NewMemoryCirculationRepository(memory),
+			members:     repositories.NewMemoryMemberRepository(memory),
+			apiKeys:     repositories.NewMemoryAPIKeyRepository(memory),
+		}
+	default:
+		database, err := db.InitDB()
+		if err != nil {
+			logger.Fatal("failed to initialize database", zap.Error(err))
+		}
+		return stores{
+			books:    repositories.NewBookRepository(database),
+			authors:  repositories.NewAuthorRepository(database),
+			tags:     repositories.NewTagRepository(database),
+			editions: repositories.NewEditionRepository(database),
+
+			circulation: repositories.NewCirculationRepository(database),
+			members:     repositories.NewMemberRepository(database),
+			apiKeys:     repositories.NewAPIKeyRepository(database),
+		}
+	}
+}
@@ -0,0 +1,319 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shani34/book-management-system/config"
+	"github.com/shani34/book-management-system/pkg/jwt"
+)
+
+const testSecret = "test-secret"
+
+// newTestRouter builds the router on the in-memory backends with env
+// applied over the test defaults.
+func newTestRouter(t *testing.T, env map[string]string) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	// LoadEnv insists on a .env file in the working directory.
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".env"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	defaults := map[string]string{
+		"STORAGE_DRIVER":    "memory",
+		"CACHE_DRIVER":      "memory",
+		"EVENTS_SINK":       "none",
+		"RATE_LIMIT_DRIVER": "memory",
+		"AUTH_ENABLED":      "true",
+		"AUTH_JWT_SECRET":   testSecret,
+	}
+	for key, value := range defaults {
+		t.Setenv(key, value)
+	}
+	for key, value := range env {
+		t.Setenv(key, value)
+	}
+	config.LoadEnv()
+	return SetupRouter()
+}
+
+// testToken signs a token for subject with the given extra claims.
+func testToken(t *testing.T, subject string, claims jwt.Claims) string {
+	t.Helper()
+	all := jwt.Claims{"sub": subject, "exp": time.Now().Add(time.Hour).Unix()}
+	for name, value := range claims {
+		all[name] = value
+	}
+	token, err := jwt.Sign(all, jwt.HS256, []byte(testSecret), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+// TestWritesNeedPermission checks that a reader who holds the write scope
+// is still refused every write by the role policy.
+func TestWritesNeedPermission(t *testing.T) {
+	router := newTestRouter(t, nil)
+	token := testToken(t, "reader", jwt.Claims{"scope": "books:read books:write", "role": "reader"})
+
+	checked := 0
+	for _, route := range router.Routes() {
+		if route.Method == http.MethodGet || route.Method == http.MethodOptions ||
+			!strings.HasPrefix(route.Path, "/api/v1/") || strings.HasPrefix(route.Path, "/api/v1/admin/") {
+			continue
+		}
+		path := route.Path
+		for _, param := range []string{":id", ":tag_id", ":edition_id", ":isbn"} {
+			path = strings.ReplaceAll(path, param, "1")
+		}
+
+		request := httptest.NewRequest(route.Method, path, strings.NewReader("{}"))
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set("Authorization", "Bearer "+token)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+
+		if recorder.Code != http.StatusForbidden || !strings.Contains(recorder.Body.String(), "missing_permission") {
+			t.Errorf("%s %s as reader: got %d %s, want 403 missing_permission",
+				route.Method, route.Path, recorder.Code, recorder.Body)
+		}
+		checked++
+	}
+	if checked < 30 {
+		t.Errorf("checked only %d write routes", checked)
+	}
+}
+
+// TestTokenWithoutScopeGetsRoleScopes checks that a token without a scope
+// claim may do what its role allows, and that an explicit scope still
+// narrows it.
+func TestTokenWithoutScopeGetsRoleScopes(t *testing.T) {
+	tests := []struct {
+		name   string
+		claims jwt.Claims
+		read   int
+		write  int
+		admin  int
+	}{
+		{"no role", jwt.Claims{}, http.StatusOK, http.StatusForbidden, http.StatusForbidden},
+		{"reader", jwt.Claims{"role": "reader"}, http.StatusOK, http.StatusForbidden, http.StatusForbidden},
+		{"librarian", jwt.Claims{"role": "librarian"}, http.StatusOK, http.StatusCreated, http.StatusForbidden},
+		{"admin", jwt.Claims{"roles": []string{"admin"}}, http.StatusOK, http.StatusCreated, http.StatusOK},
+		{"unknown role", jwt.Claims{"role": "janitor"}, http.StatusForbidden, http.StatusForbidden, http.StatusForbidden},
+		{"admin with read scope", jwt.Claims{"role": "admin", "scope": "books:read"}, http.StatusOK, http.StatusForbidden, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newTestRouter(t, nil)
+			token := testToken(t, "user", tt.claims)
+			serve := func(method, path, body string) int {
+				request := httptest.NewRequest(method, path, strings.NewReader(body))
+				request.Header.Set("Content-Type", "application/json")
+				request.Header.Set("Authorization", "Bearer "+token)
+				recorder := httptest.NewRecorder()
+				router.ServeHTTP(recorder, request)
+				return recorder.Code
+			}
+
+			if got := serve(http.MethodGet, "/api/v1/books", ""); got != tt.read {
+				t.Errorf("read: got %d, want %d", got, tt.read)
+			}
+			if got := serve(http.MethodPost, "/api/v1/books", `{"title":"Dune","author":"Frank Herbert"}`); got != tt.write {
+				t.Errorf("write: got %d, want %d", got, tt.write)
+			}
+			if got := serve(http.MethodGet, "/api/v1/admin/api-keys", ""); got != tt.admin {
+				t.Errorf("admin: got %d, want %d", got, tt.admin)
+			}
+		})
+	}
+}
+
+func TestPublicReads(t *testing.T) {
+	tests := []struct {
+		publicReads string
+		path        string
+		want        int
+	}{
+		{"false", "/api/v1/books", http.StatusUnauthorized},
+		{"false", "/api/v1/authors", http.StatusUnauthorized},
+		{"true", "/api/v1/books", http.StatusOK},
+		{"true", "/api/v1/authors", http.StatusOK},
+		{"true", "/api/v1/tags", http.StatusOK},
+		{"true", "/api/v1/books/1/holds", http.StatusUnauthorized},
+		{"true", "/api/v1/members", http.StatusUnauthorized},
+		{"true", "/api/v1/loans", http.StatusUnauthorized},
+		{"true", "/api/v1/holds", http.StatusUnauthorized},
+		{"true", "/api/v1/fines/1", http.StatusUnauthorized},
+		{"true", "/api/v1/tiers", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.publicReads+tt.path, func(t *testing.T) {
+			router := newTestRouter(t, map[string]string{"AUTH_PUBLIC_READS": tt.publicReads})
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if recorder.Code != tt.want {
+				t.Errorf("anonymous GET %s: got %d, want %d", tt.path, recorder.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestFailedAuthIsLimited(t *testing.T) {
+	router := newTestRouter(t, map[string]string{"RATE_LIMITS": "default=1000/1m;auth=3/1m"})
+	get := func(token, forwardedFor string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, "/api/v1/books", nil)
+		request.Header.Set("Authorization", "Bearer "+token)
+		// No proxy is trusted, so this must not give each request its own
+		// client IP.
+		request.Header.Set("X-Forwarded-For", forwardedFor)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	for i, ip := range []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"} {
+		if recorder := get("bad-token", ip); recorder.Code != http.StatusUnauthorized {
+			t.Fatalf("bad token %d: got %d, want 401", i+1, recorder.Code)
+		}
+	}
+
+	good := testToken(t, "reader", jwt.Claims{"role": "reader"})
+	recorder := get(good, "10.0.0.4")
+	if recorder.Code != http.StatusTooManyRequests {
+		t.Fatalf("valid token after too many failures: got %d, want 429", recorder.Code)
+	}
+	if recorder.Header().Get("Retry-After") == "" {
+		t.Error("429 without Retry-After")
+	}
+}
+
+func TestSuccessfulAuthIsNotCountedAsFailure(t *testing.T) {
+	router := newTestRouter(t, map[string]string{"RATE_LIMITS": "default=1000/1m;auth=2/1m"})
+	token := testToken(t, "reader", jwt.Claims{"role": "reader"})
+	for i := 0; i < 5; i++ {
+		request := httptest.NewRequest(http.MethodGet, "/api/v1/books", nil)
+		request.Header.Set("Authorization", "Bearer "+token)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("request %d: got %d, want 200", i+1, recorder.Code)
+		}
+	}
+}
+
+func TestImportTooLarge(t *testing.T) {
+	router := newTestRouter(t, nil)
+	token := testToken(t, "admin", jwt.Claims{"scope": "books:read books:write", "role": "admin"})
+	// Blank lines are skipped, so only the size limit can refuse this.
+	upload := strings.Repeat("\n", 33<<20)
+
+	var form bytes.Buffer
+	writer := multipart.NewWriter(&form)
+	part, err := writer.CreateFormFile("file", "books.ndjson")
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write([]byte(upload))
+	writer.Close()
+
+	for name, request := range map[string]*http.Request{
+		"raw":       httptest.NewRequest(http.MethodPost, "/api/v1/books/import?format=ndjson", strings.NewReader(upload)),
+		"multipart": httptest.NewRequest(http.MethodPost, "/api/v1/books/import", &form),
+	} {
+		if name == "multipart" {
+			request.Header.Set("Content-Type", writer.FormDataContentType())
+		} else {
+			request.Header.Set("Content-Type", "application/x-ndjson")
+		}
+		request.Header.Set("Authorization", "Bearer "+token)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		if recorder.Code != http.StatusRequestEntityTooLarge {
+			t.Errorf("%s upload over the limit: got %d %s, want 413", name, recorder.Code, recorder.Body)
+		}
+	}
+}
+
+// TestTenantAdminCannotEscapeTenant checks that an admin bound to a tenant
+// only issues, sees and revokes that tenant's API keys.
+func TestTenantAdminCannotEscapeTenant(t *testing.T) {
+	router := newTestRouter(t, nil)
+	root := testToken(t, "root", jwt.Claims{"scope": "admin", "role": "admin"})
+	north := testToken(t, "north-admin", jwt.Claims{"scope": "admin", "role": "admin", "tenant": "north"})
+
+	call := func(token, method, path, body string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(method, path, strings.NewReader(body))
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set("Authorization", "Bearer "+token)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		return recorder
+	}
+	issue := func(token, tenant string) *httptest.ResponseRecorder {
+		return call(token, http.MethodPost, "/api/v1/admin/api-keys",
+			fmt.Sprintf(`{"name":"k","scopes":["admin"],"tenant_id":%q}`, tenant))
+	}
+	keyID := func(recorder *httptest.ResponseRecorder) uint {
+		var key struct{ ID uint }
+		if err := json.Unmarshal(recorder.Body.Bytes(), &key); err != nil {
+			t.Fatalf("decoding %s: %v", recorder.Body, err)
+		}
+		return key.ID
+	}
+
+	foreign := issue(root, "south")
+	if foreign.Code != http.StatusCreated {
+		t.Fatalf("unbound admin issuing for south: got %d %s", foreign.Code, foreign.Body)
+	}
+	foreignID := keyID(foreign)
+
+	for _, tenant := range []string{"", "south"} {
+		if recorder := issue(north, tenant); recorder.Code != http.StatusForbidden {
+			t.Errorf("north admin issuing tenant_id %q: got %d %s, want 403", tenant, recorder.Code, recorder.Body)
+		}
+	}
+	own := issue(north, "north")
+	if own.Code != http.StatusCreated {
+		t.Fatalf("north admin issuing for north: got %d %s", own.Code, own.Body)
+	}
+	ownID := keyID(own)
+
+	var listed []struct{ ID uint }
+	recorder := call(north, http.MethodGet, "/api/v1/admin/api-keys", "")
+	if err := json.Unmarshal(recorder.Body.Bytes(), &listed); err != nil || len(listed) != 1 || listed[0].ID != ownID {
+		t.Errorf("north admin listing keys: got %s, want only key %d", recorder.Body, ownID)
+	}
+	path := fmt.Sprintf("/api/v1/admin/api-keys/%d", foreignID)
+	if recorder := call(north, http.MethodGet, path, ""); recorder.Code != http.StatusNotFound {
+		t.Errorf("north admin reading south's key: got %d, want 404", recorder.Code)
+	}
+	if recorder := call(north, http.MethodPost, path+"/revoke", ""); recorder.Code != http.StatusNotFound {
+		t.Errorf("north admin revoking south's key: got %d, want 404", recorder.Code)
+	}
+	recorder = call(root, http.MethodGet, path, "")
+	if recorder.Code != http.StatusOK || strings.Contains(recorder.Body.String(), "revoked_at") {
+		t.Errorf("south's key after north's attempts: got %d %s, want it unrevoked", recorder.Code, recorder.Body)
+	}
+}
@@ -21,6 +21,9 @@ import (
 // @securityDefinitions.apikey BearerAuth
 // @in header
 // @name Authorization
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
 // @swagger 2.0  // <-- Add this line to specify Swagger version
 func main() {
     // Load environment variables
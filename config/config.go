@@ -10,10 +10,18 @@ import (
 )
 
 type Config struct {
-	DB     DBConfig
-	Redis  RedisConfig
-	Kafka  KafkaConfig
-	Server ServerConfig
+	DB        DBConfig
+	Storage   StorageConfig
+	Redis     RedisConfig
+	Cache     CacheConfig
+	Kafka     KafkaConfig
+	Events    EventsConfig
+	Server    ServerConfig
+	Holds     HoldsConfig
+	Auth      AuthConfig
+	RBAC      RBACConfig
+	Tenancy   TenancyConfig
+	RateLimit RateLimitConfig
 }
 
 type DBConfig struct {
@@ -25,6 +33,11 @@ type DBConfig struct {
 	SSLMode  string
 }
 
+// StorageConfig selects the BookStore implementation: "postgres" or "memory".
+type StorageConfig struct {
+	Driver string
+}
+
 type RedisConfig struct {
 	Host     string
 	Port     string
@@ -32,16 +45,93 @@ type RedisConfig struct {
 	DB       int
 }
 
+// CacheConfig selects the cache backend: "redis" (falling back to the
+// in-process LRU when Redis is unreachable), "memory" or "none".
+type CacheConfig struct {
+	Driver string
+	Size   int
+}
+
 type KafkaConfig struct {
 	Brokers []string
 	Username string
 	Password string
+	TLS      bool
+	CAFile   string
+	CertFile string
+	KeyFile  string
+	// InsecureSkipVerify accepts any broker certificate. Only for brokers
+	// whose certificate doesn't match their address.
+	InsecureSkipVerify bool
+}
+
+// EventsConfig selects where book events are published: "kafka", "file"
+// (NDJSON appended to File), "memory" or "none". RelayInterval is how often
+// the outbox is polled for undelivered events.
+type EventsConfig struct {
+	Sink          string
+	File          string
+	RelayInterval time.Duration
+}
+
+// HoldsConfig sets how long a member has to collect a copy set aside for
+// their hold (PickupWindow), and how often uncollected holds are expired.
+type HoldsConfig struct {
+	PickupWindow   time.Duration
+	ExpiryInterval time.Duration
+}
+
+// AuthConfig controls authentication. Callers send an API key or a bearer
+// token, HS256-signed with JWTSecret or RS256-signed by a key in JWKSFile.
+// Issuer and Audience, when set, must match the token's iss and aud
+// claims. With PublicReads, GET requests for the catalogue (books, authors
+// and tags) need no credentials at all.
+type AuthConfig struct {
+	Enabled     bool
+	PublicReads bool
+	JWTSecret   string
+	JWKSFile    string
+	Issuer      string
+	Audience    string
+	Leeway      time.Duration
+}
+
+// RBACConfig holds the role-to-permission mappings checked on book
+// operations, as "role=perm,perm;role=perm" (empty for the built-in
+// policy), and the role of authenticated callers who carry none.
+type RBACConfig struct {
+	Roles       string
+	DefaultRole string
+}
+
+// TenancyConfig says how a request's tenant is found. BaseDomain, when set,
+// makes the first label of a host under it the tenant, as in
+// school1.books.example.com. Requests naming no tenant get Default, or are
+// refused when Required is set.
+type TenancyConfig struct {
+	BaseDomain string
+	Default    string
+	Required   bool
 }
 
+// RateLimitConfig controls per-caller request limits. Driver is "redis"
+// (falling back to in-process counts when Redis is unreachable) or
+// "memory"; Limits sets them per route group as "group=requests/window;...",
+// empty for the built-in ones.
+type RateLimitConfig struct {
+	Enabled bool
+	Driver  string
+	Limits  string
+}
+
+// ServerConfig configures the HTTP server. TrustedProxies lists the
+// addresses or CIDRs of proxies whose X-Forwarded-For is believed when
+// working out the client IP; with none, the client IP is the peer address.
 type ServerConfig struct {
-	Port         string
-	ReadTimeout  time.Duration
-	WriteTimeout time.Duration
+	Port           string
+	ReadTimeout    time.Duration
+	WriteTimeout   time.Duration
+	TrustedProxies []string
 }
 
 var cfg *Config
@@ -61,21 +151,66 @@ func LoadEnv() {
 			Name:     getEnv("DB_NAME", "bookdb"),
 			SSLMode:  getEnv("DB_SSL_MODE", "disable"),
 		},
+		Storage: StorageConfig{
+			Driver: getEnv("STORAGE_DRIVER", "postgres"),
+		},
 		Redis: RedisConfig{
 			Host:     getEnv("REDIS_HOST", "localhost"),
 			Port:     getEnv("REDIS_PORT", "6379"),
 			Password: getEnv("REDIS_PASSWORD", ""),
 			DB:       getEnvAsInt("REDIS_DB", 0),
 		},
+		Cache: CacheConfig{
+			Driver: getEnv("CACHE_DRIVER", "redis"),
+			Size:   getEnvAsInt("CACHE_SIZE", 1024),
+		},
 		Kafka: KafkaConfig{
 			Brokers: getEnvAsSlice("KAFKA_BROKERS", []string{"localhost:9092"}),
 			Username: getEnv("username","username"),
 			Password: getEnv("password","password"),
+			TLS:      getEnvAsBool("KAFKA_TLS", true),
+			CAFile:   getEnv("KAFKA_CA_FILE", "pkg/kafka/ca.pem"),
+			CertFile: getEnv("KAFKA_CERT_FILE", "pkg/kafka/service.cert"),
+			KeyFile:  getEnv("KAFKA_KEY_FILE", "pkg/kafka/service.key"),
+			InsecureSkipVerify: getEnvAsBool("KAFKA_TLS_INSECURE_SKIP_VERIFY", false),
+		},
+		Events: EventsConfig{
+			Sink:          getEnv("EVENTS_SINK", "kafka"),
+			File:          getEnv("EVENTS_FILE", "events.ndjson"),
+			RelayInterval: getEnvAsDuration("EVENTS_RELAY_INTERVAL", time.Second),
 		},
 		Server: ServerConfig{
 			Port:         getEnv("SERVER_PORT", "8080"),
 			ReadTimeout:  getEnvAsDuration("SERVER_READ_TIMEOUT", 10*time.Second),
 			WriteTimeout: getEnvAsDuration("SERVER_WRITE_TIMEOUT", 10*time.Second),
+			TrustedProxies: getEnvAsSlice("TRUSTED_PROXIES", nil),
+		},
+		Auth: AuthConfig{
+			Enabled:     getEnvAsBool("AUTH_ENABLED", true),
+			PublicReads: getEnvAsBool("AUTH_PUBLIC_READS", false),
+			JWTSecret:   getEnv("AUTH_JWT_SECRET", ""),
+			JWKSFile:    getEnv("AUTH_JWKS_FILE", ""),
+			Issuer:      getEnv("AUTH_ISSUER", ""),
+			Audience:    getEnv("AUTH_AUDIENCE", ""),
+			Leeway:      getEnvAsDuration("AUTH_LEEWAY", 30*time.Second),
+		},
+		RBAC: RBACConfig{
+			Roles:       getEnv("RBAC_ROLES", ""),
+			DefaultRole: getEnv("RBAC_DEFAULT_ROLE", "reader"),
+		},
+		Tenancy: TenancyConfig{
+			BaseDomain: getEnv("TENANT_BASE_DOMAIN", ""),
+			Default:    getEnv("TENANT_DEFAULT", "default"),
+			Required:   getEnvAsBool("TENANT_REQUIRED", false),
+		},
+		RateLimit: RateLimitConfig{
+			Enabled: getEnvAsBool("RATE_LIMIT_ENABLED", true),
+			Driver:  getEnv("RATE_LIMIT_DRIVER", "redis"),
+			Limits:  getEnv("RATE_LIMITS", ""),
+		},
+		Holds: HoldsConfig{
+			PickupWindow:   getEnvAsDuration("HOLD_PICKUP_WINDOW", 72*time.Hour),
+			ExpiryInterval: getEnvAsDuration("HOLD_EXPIRY_INTERVAL", time.Minute),
 		},
 	}
 }
@@ -108,6 +243,18 @@ func getEnvAsInt(key string, defaultValue int) int {
 	return value
 }
 
+func getEnvAsBool(key string, defaultValue bool) bool {
+	valueStr := os.Getenv(key)
+	if valueStr == "" {
+		return defaultValue
+	}
+	value, err := strconv.ParseBool(valueStr)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
 func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
 	valueStr := os.Getenv(key)
 	if valueStr == "" {
@@ -126,4 +273,4 @@ func getEnvAsSlice(key string, defaultValue []string) []string {
 		return defaultValue
 	}
 	return strings.Split(valueStr, ",")
-}
\ No newline at end of file
+}
@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shani34/book-management-system/internal/middleware"
+	"github.com/shani34/book-management-system/internal/models"
+	"github.com/shani34/book-management-system/internal/services"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type APIKeyHandler struct {
+	service *services.APIKeyService
+	logger  *zap.Logger
+}
+
+func NewAPIKeyHandler(service *services.APIKeyService, logger *zap.Logger) *APIKeyHandler {
+	return &APIKeyHandler{
+		service: service,
+		logger:  logger.Named("handlers.APIKeyHandler"),
+	}
+}
+
+// GetAPIKeys godoc
+// @Summary List API keys
+// @Description Get paginated list of API keys, newest first, including revoked ones. Secrets are never returned. Callers bound to a tenant only see that tenant's keys.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param limit query int false "Limit"
+// @Param offset query int false "Offset"
+// @Success 200 {array} models.APIKey
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /admin/api-keys [get]
+func (h *APIKeyHandler) GetAPIKeys(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	keys, err := h.service.ForTenant(middleware.BoundTenant(c)).GetAPIKeys(limit, offset)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	h.logger.Info("Successfully retrieved API keys", zap.Int("count", len(keys)))
+	c.JSON(http.StatusOK, keys)
+}
+
+// GetAPIKey godoc
+// @Summary Get an API key
+// @Description Get API key by ID
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path int true "API key ID"
+// @Success 200 {object} models.APIKey
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /admin/api-keys/{id} [get]
+func (h *APIKeyHandler) GetAPIKey(c *gin.Context) {
+	id, ok := h.keyID(c)
+	if !ok {
+		return
+	}
+
+	key, err := h.service.ForTenant(middleware.BoundTenant(c)).GetAPIKey(id)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, key)
+}
+
+// IssueAPIKey godoc
+// @Summary Issue an API key
+// @Description Create a key with the given scopes (books:read, books:write, admin) and optional expiry. The key is only shown in this response. Callers bound to a tenant must set tenant_id to their own tenant.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param key body models.APIKeyRequest true "API key data"
+// @Success 201 {object} models.IssuedAPIKey
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /admin/api-keys [post]
+func (h *APIKeyHandler) IssueAPIKey(c *gin.Context) {
+	var request models.APIKeyRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		h.logger.Warn("Invalid request body", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	issued, err := h.service.ForTenant(middleware.BoundTenant(c)).IssueAPIKey(&request)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	h.logger.Info("API key issued",
+		zap.Uint("api_key_id", issued.ID),
+		zap.String("prefix", issued.Prefix),
+		zap.Strings("scopes", issued.Scopes),
+		zap.String("issued_by", middleware.Subject(c)),
+	)
+	c.JSON(http.StatusCreated, issued)
+}
+
+// RevokeAPIKey godoc
+// @Summary Revoke an API key
+// @Description Stop a key authenticating. Revoked keys stay listed. Callers bound to a tenant can only revoke that tenant's keys.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path int true "API key ID"
+// @Success 200 {object} models.APIKey
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /admin/api-keys/{id}/revoke [post]
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	id, ok := h.keyID(c)
+	if !ok {
+		return
+	}
+
+	key, err := h.service.ForTenant(middleware.BoundTenant(c)).RevokeAPIKey(id)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	h.logger.Info("API key revoked",
+		zap.Uint("api_key_id", id),
+		zap.String("revoked_by", middleware.Subject(c)),
+	)
+	c.JSON(http.StatusOK, key)
+}
+
+func (h *APIKeyHandler) keyID(c *gin.Context) (uint, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id < 0 {
+		h.logger.Warn("Invalid API key ID format",
+			zap.String("received_id", c.Param("id")),
+			zap.Error(err),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid API key ID format"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// writeError maps service errors onto responses; anything unexpected is
+// logged and reported as a 500.
+func (h *APIKeyHandler) writeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		h.logger.Warn("API key not found", zap.String("path", c.Request.URL.Path))
+		c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+	case errors.Is(err, services.ErrInvalidAPIKey):
+		h.logger.Warn("API key failed validation", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrForeignTenant):
+		h.logger.Warn("API key outside the caller's tenant", zap.String("subject", middleware.Subject(c)), zap.Error(err))
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, models.ErrAPIKeyRevoked):
+		h.logger.Warn("API key conflict", zap.String("path", c.Request.URL.Path), zap.Error(err))
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		h.logger.Error("API key request failed", zap.String("path", c.Request.URL.Path), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
@@ -0,0 +1,276 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shani34/book-management-system/internal/middleware"
+	"github.com/shani34/book-management-system/internal/models"
+	"github.com/shani34/book-management-system/internal/services"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type AuthorHandler struct {
+	service *services.AuthorService
+	logger  *zap.Logger
+}
+
+func NewAuthorHandler(service *services.AuthorService, logger *zap.Logger) *AuthorHandler {
+	return &AuthorHandler{
+		service: service,
+		logger:  logger.Named("handlers.AuthorHandler"),
+	}
+}
+
+// authors returns the author service for the request's tenant.
+func (h *AuthorHandler) authors(c *gin.Context) *services.AuthorService {
+	return h.service.ForTenant(middleware.Tenant(c))
+}
+
+// GetAuthors godoc
+// @Summary List authors
+// @Description Get paginated list of authors ordered by name
+// @Tags authors
+// @Accept json
+// @Produce json
+// @Param limit query int false "Limit"
+// @Param offset query int false "Offset"
+// @Success 200 {array} models.Author
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /authors [get]
+func (h *AuthorHandler) GetAuthors(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	h.logger.Info("Starting GetAuthors request",
+		zap.Int("limit", limit),
+		zap.Int("offset", offset),
+	)
+
+	authors, err := h.authors(c).GetAllAuthors(limit, offset)
+	if err != nil {
+		h.logger.Error("Failed to fetch authors", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	h.logger.Info("Successfully retrieved authors", zap.Int("count", len(authors)))
+	c.JSON(http.StatusOK, authors)
+}
+
+// GetAuthor godoc
+// @Summary Get an author
+// @Description Get author by ID
+// @Tags authors
+// @Accept json
+// @Produce json
+// @Param id path int true "Author ID"
+// @Success 200 {object} models.Author
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /authors/{id} [get]
+func (h *AuthorHandler) GetAuthor(c *gin.Context) {
+	id, ok := h.authorID(c)
+	if !ok {
+		return
+	}
+
+	author, err := h.authors(c).GetAuthorByID(id)
+	if err != nil {
+		h.writeError(c, "Failed to fetch author", id, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, author)
+}
+
+// GetAuthorBooks godoc
+// @Summary List an author's books
+// @Description Get paginated list of the books linked to an author
+// @Tags authors
+// @Accept json
+// @Produce json
+// @Param id path int true "Author ID"
+// @Param limit query int false "Limit"
+// @Param offset query int false "Offset"
+// @Success 200 {array} models.Book
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /authors/{id}/books [get]
+func (h *AuthorHandler) GetAuthorBooks(c *gin.Context) {
+	id, ok := h.authorID(c)
+	if !ok {
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	books, err := h.authors(c).GetAuthorBooks(id, limit, offset)
+	if err != nil {
+		h.writeError(c, "Failed to fetch author books", id, err)
+		return
+	}
+
+	h.logger.Info("Successfully retrieved author books",
+		zap.Uint("author_id", id),
+		zap.Int("count", len(books)),
+	)
+	c.JSON(http.StatusOK, books)
+}
+
+// CreateAuthor godoc
+// @Summary Create author
+// @Description Create new author
+// @Tags authors
+// @Accept json
+// @Produce json
+// @Param author body models.AuthorRequest true "Author data"
+// @Success 201 {object} models.Author
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /authors [post]
+func (h *AuthorHandler) CreateAuthor(c *gin.Context) {
+	var author models.Author
+	if err := c.ShouldBindJSON(&author); err != nil {
+		h.logger.Warn("Invalid request body", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	if err := h.authors(c).CreateAuthor(&author); err != nil {
+		h.writeError(c, "Failed to create author", 0, err)
+		return
+	}
+
+	h.logger.Info("Author created successfully", zap.Uint("author_id", author.ID))
+	c.JSON(http.StatusCreated, author)
+}
+
+// UpdateAuthor godoc
+// @Summary Update author
+// @Description Rename an existing author. Every linked book gets a new version and a book_updated event.
+// @Tags authors
+// @Accept json
+// @Produce json
+// @Param id path int true "Author ID"
+// @Param author body models.AuthorRequest true "Author data"
+// @Success 200 {object} models.Author
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /authors/{id} [put]
+func (h *AuthorHandler) UpdateAuthor(c *gin.Context) {
+	id, ok := h.authorID(c)
+	if !ok {
+		return
+	}
+
+	var author models.Author
+	if err := c.ShouldBindJSON(&author); err != nil {
+		h.logger.Warn("Invalid request body",
+			zap.Uint("author_id", id),
+			zap.Error(err),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	if err := h.authors(c).UpdateAuthor(id, &author); err != nil {
+		h.writeError(c, "Failed to update author", id, err)
+		return
+	}
+
+	h.logger.Info("Author updated successfully", zap.Uint("author_id", id))
+	c.JSON(http.StatusOK, author)
+}
+
+// DeleteAuthor godoc
+// @Summary Delete author
+// @Description Delete an author that no book links to
+// @Tags authors
+// @Accept json
+// @Produce json
+// @Param id path int true "Author ID"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /authors/{id} [delete]
+func (h *AuthorHandler) DeleteAuthor(c *gin.Context) {
+	id, ok := h.authorID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.authors(c).DeleteAuthor(id); err != nil {
+		h.writeError(c, "Failed to delete author", id, err)
+		return
+	}
+
+	h.logger.Info("Author deleted successfully", zap.Uint("author_id", id))
+	c.Status(http.StatusNoContent)
+}
+
+func (h *AuthorHandler) authorID(c *gin.Context) (uint, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id < 0 {
+		h.logger.Warn("Invalid author ID format",
+			zap.String("received_id", c.Param("id")),
+			zap.Error(err),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid author ID format"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// writeError maps service errors onto responses; anything unexpected is
+// logged with msg and reported as a 500.
+func (h *AuthorHandler) writeError(c *gin.Context, msg string, id uint, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		h.logger.Warn("Author not found", zap.Uint("author_id", id))
+		c.JSON(http.StatusNotFound, gin.H{"error": "author not found"})
+	case errors.Is(err, services.ErrInvalidAuthor):
+		h.logger.Warn("Author failed validation", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, models.ErrDuplicateAuthor), errors.Is(err, models.ErrAuthorHasBooks):
+		h.logger.Warn("Author conflict",
+			zap.Uint("author_id", id),
+			zap.Error(err),
+		)
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		h.logger.Error(msg,
+			zap.Uint("author_id", id),
+			zap.Error(err),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
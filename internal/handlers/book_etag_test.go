@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shani34/book-management-system/internal/middleware"
+	"github.com/shani34/book-management-system/internal/models"
+	"github.com/shani34/book-management-system/internal/repositories"
+	"github.com/shani34/book-management-system/internal/services"
+	"github.com/shani34/book-management-system/pkg/cache"
+	"github.com/shani34/book-management-system/pkg/patch"
+	"go.uber.org/zap"
+)
+
+// newETagRouter serves one book, Dune, at /books/1 and version 1.
+func newETagRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	service := services.NewBookService(repositories.NewMemoryBookRepository(repositories.NewMemoryDB()), cache.Noop{})
+	if err := service.ForTenant("t").CreateBook(&models.Book{Title: "Dune", Author: "Frank Herbert", Year: 1965}); err != nil {
+		t.Fatal(err)
+	}
+	handler := NewBookHandler(service, zap.NewNop())
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Set(middleware.TenantKey, "t") })
+	router.GET("/books/:id", handler.GetBook)
+	router.PUT("/books/:id", handler.UpdateBook)
+	router.PATCH("/books/:id", handler.PatchBook)
+	router.DELETE("/books/:id", handler.DeleteBook)
+	return router
+}
+
+// conditional sends a request with body (a merge patch for PATCH) and,
+// unless it is empty, the header.
+func conditional(router *gin.Engine, method, header, value, body string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(method, "/books/1", strings.NewReader(body))
+	if method == http.MethodPatch {
+		request.Header.Set("Content-Type", patch.MergePatchContentType)
+	} else if body != "" {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	if value != "" {
+		request.Header.Set(header, value)
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestGetBookETag(t *testing.T) {
+	router := newETagRouter(t)
+
+	recorder := conditional(router, http.MethodGet, "", "", "")
+	if recorder.Code != http.StatusOK || recorder.Header().Get("ETag") != `"1"` {
+		t.Fatalf("got %d with ETag %q, want 200 with \"1\"", recorder.Code, recorder.Header().Get("ETag"))
+	}
+
+	recorder = conditional(router, http.MethodGet, "If-None-Match", `"1"`, "")
+	if recorder.Code != http.StatusNotModified || recorder.Body.Len() != 0 {
+		t.Errorf("current If-None-Match: got %d %s, want an empty 304", recorder.Code, recorder.Body)
+	}
+	recorder = conditional(router, http.MethodGet, "If-None-Match", `"7"`, "")
+	if recorder.Code != http.StatusOK {
+		t.Errorf("stale If-None-Match: got %d, want 200", recorder.Code)
+	}
+}
+
+func TestIfMatchRequired(t *testing.T) {
+	router := newETagRouter(t)
+	body := map[string]string{
+		http.MethodPut:    `{"title":"Dune","author":"Frank Herbert","year":1966}`,
+		http.MethodPatch:  `{"year":1966}`,
+		http.MethodDelete: "",
+	}
+	tests := []struct {
+		ifMatch string
+		want    int
+	}{
+		{"", http.StatusPreconditionRequired},
+		{`"2"`, http.StatusPreconditionFailed},
+		{`W/"1"`, http.StatusPreconditionFailed},
+		{`1`, http.StatusPreconditionFailed},
+		{`"0"`, http.StatusPreconditionFailed},
+		{`"one"`, http.StatusPreconditionFailed},
+	}
+	for _, method := range []string{http.MethodPut, http.MethodPatch, http.MethodDelete} {
+		for _, tt := range tests {
+			recorder := conditional(router, method, "If-Match", tt.ifMatch, body[method])
+			if recorder.Code != tt.want {
+				t.Errorf("%s with If-Match %q: got %d %s, want %d", method, tt.ifMatch, recorder.Code, recorder.Body, tt.want)
+			}
+		}
+	}
+
+	// None of the refused writes changed the book.
+	if recorder := conditional(router, http.MethodGet, "", "", ""); recorder.Header().Get("ETag") != `"1"` {
+		t.Errorf("after refused writes: got ETag %q, want \"1\"", recorder.Header().Get("ETag"))
+	}
+}
+
+func TestIfMatchVersions(t *testing.T) {
+	router := newETagRouter(t)
+
+	recorder := conditional(router, http.MethodPut, "If-Match", `"1"`, `{"title":"Dune","author":"Frank Herbert","year":1966}`)
+	if recorder.Code != http.StatusOK || recorder.Header().Get("ETag") != `"2"` {
+		t.Fatalf("PUT at the current version: got %d %s with ETag %q", recorder.Code, recorder.Body, recorder.Header().Get("ETag"))
+	}
+
+	// The ETag the first writer held is now stale.
+	recorder = conditional(router, http.MethodPatch, "If-Match", `"1"`, `{"year":1967}`)
+	if recorder.Code != http.StatusPreconditionFailed {
+		t.Errorf("PATCH at a stale version: got %d, want 412", recorder.Code)
+	}
+	recorder = conditional(router, http.MethodPatch, "If-Match", `"2"`, `{"year":1967}`)
+	if recorder.Code != http.StatusOK || recorder.Header().Get("ETag") != `"3"` {
+		t.Errorf("PATCH at the current version: got %d with ETag %q", recorder.Code, recorder.Header().Get("ETag"))
+	}
+
+	// * matches whatever version is stored.
+	recorder = conditional(router, http.MethodPut, "If-Match", "*", `{"title":"Dune","author":"Frank Herbert","year":1968}`)
+	if recorder.Code != http.StatusOK || recorder.Header().Get("ETag") != `"4"` {
+		t.Errorf("PUT with *: got %d with ETag %q", recorder.Code, recorder.Header().Get("ETag"))
+	}
+
+	if recorder = conditional(router, http.MethodDelete, "If-Match", `"3"`, ""); recorder.Code != http.StatusPreconditionFailed {
+		t.Errorf("DELETE at a stale version: got %d, want 412", recorder.Code)
+	}
+	if recorder = conditional(router, http.MethodDelete, "If-Match", `"4"`, ""); recorder.Code != http.StatusNoContent {
+		t.Errorf("DELETE at the current version: got %d, want 204", recorder.Code)
+	}
+}
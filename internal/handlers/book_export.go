@@ -0,0 +1,230 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shani34/book-management-system/internal/models"
+	"go.uber.org/zap"
+)
+
+// exportFlushEvery is how many books are written between flushes, so the
+// client starts receiving data before the export finishes.
+const exportFlushEvery = 500
+
+// exportWriteTimeout is how long the client gets to take each batch of
+// books, so a stalled client can't hold the database cursor open forever.
+const exportWriteTimeout = 30 * time.Second
+
+// The export trailers tell a client whether it got the whole export:
+// X-Export-Status is "complete" or "truncated" and X-Export-Count is the
+// number of books written.
+const (
+	exportStatusTrailer = "X-Export-Status"
+	exportCountTrailer  = "X-Export-Count"
+)
+
+var exportColumns = []string{"id", "title", "author", "year", "isbn10", "isbn13", "version", "created_at", "updated_at"}
+
+// bookExporter writes books in one export format.
+type bookExporter interface {
+	contentType() string
+	begin() error
+	write(book *models.Book) error
+	end() error
+	// fail ends an export cut short after written books, marking the
+	// body as truncated where the format allows.
+	fail(written int) error
+}
+
+// ExportBooks godoc
+// @Summary Export books
+// @Description Stream the whole catalogue as CSV, NDJSON or a JSON array. Accepts the same filters and sort order as the list endpoint.
+// @Description The status is sent before the first book, so an export that fails part way is still a 200. The X-Export-Status trailer is "complete" or "truncated" and X-Export-Count counts the books written; a truncated NDJSON export also ends with an {"error": ...} line, and a truncated JSON array is left unclosed. Each batch of 500 books must be read by the client within 30 seconds.
+// @Tags books
+// @Produce text/csv,application/x-ndjson,json
+// @Param format query string false "Output format" Enums(csv, ndjson, json)
+// @Param author query string false "Exact author match"
+// @Param author_contains query string false "Case-insensitive author substring"
+// @Param title_prefix query string false "Case-insensitive title prefix"
+// @Param year_from query int false "Earliest publication year (inclusive)"
+// @Param year_to query int false "Latest publication year (inclusive)"
+// @Param tag query []string false "Tag name; repeat for several" collectionFormat(multi)
+// @Param tag_match query string false "Match books with any or all of the tags" Enums(any, all)
+// @Param sort query string false "Sort field" Enums(title, author, year, created_at)
+// @Param order query string false "Sort direction" Enums(asc, desc)
+// @Success 200 {file} file
+// @Failure 400 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Router /books/export [get]
+func (h *BookHandler) ExportBooks(c *gin.Context) {
+	filter, err := parseBookFilter(c)
+	if err != nil {
+		h.logger.Warn("Invalid book filter",
+			zap.String("query", c.Request.URL.RawQuery),
+			zap.Error(err),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	format := c.DefaultQuery("format", "ndjson")
+	var exporter bookExporter
+	switch format {
+	case "csv":
+		exporter = &csvExporter{writer: csv.NewWriter(c.Writer)}
+	case "ndjson":
+		exporter = &ndjsonExporter{encoder: json.NewEncoder(c.Writer)}
+	case "json":
+		exporter = &jsonExporter{w: c.Writer}
+	default:
+		h.logger.Warn("Unsupported export format", zap.String("format", format))
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unsupported format %q", format)})
+		return
+	}
+
+	h.logger.Info("Starting book export",
+		zap.String("format", format),
+		zap.String("filter", filter.CacheKey()),
+	)
+
+	c.Header("Content-Type", exporter.contentType())
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="books-%s.%s"`, time.Now().UTC().Format("20060102"), format))
+	c.Header("Trailer", exportStatusTrailer+", "+exportCountTrailer)
+	c.Status(http.StatusOK)
+
+	// Deadlines aren't supported by every writer (or under tests); the
+	// export then just runs without one.
+	controller := http.NewResponseController(c.Writer)
+	_ = controller.SetWriteDeadline(time.Now().Add(exportWriteTimeout))
+	defer controller.SetWriteDeadline(time.Time{})
+
+	count := 0
+	err = exporter.begin()
+	if err == nil {
+		err = h.books(c).ExportBooks(filter, func(book *models.Book) error {
+			if err := exporter.write(book); err != nil {
+				return err
+			}
+			count++
+			if count%exportFlushEvery == 0 {
+				c.Writer.Flush()
+				_ = controller.SetWriteDeadline(time.Now().Add(exportWriteTimeout))
+			}
+			return nil
+		})
+	}
+	if err == nil {
+		err = exporter.end()
+	}
+	c.Writer.Header().Set(exportCountTrailer, strconv.Itoa(count))
+	if err != nil {
+		// The status line is already sent, so the failure can only be
+		// reported in the trailer and, for NDJSON, a last error record.
+		h.logger.Error("Book export failed",
+			zap.String("format", format),
+			zap.Int("written", count),
+			zap.Error(err),
+		)
+		if err := exporter.fail(count); err != nil {
+			h.logger.Warn("Could not mark book export as truncated", zap.Error(err))
+		}
+		c.Writer.Header().Set(exportStatusTrailer, "truncated")
+		c.Abort()
+		return
+	}
+	c.Writer.Header().Set(exportStatusTrailer, "complete")
+
+	h.logger.Info("Book export finished",
+		zap.String("format", format),
+		zap.Int("count", count),
+	)
+}
+
+type csvExporter struct {
+	writer *csv.Writer
+}
+
+func (e *csvExporter) contentType() string { return "text/csv; charset=utf-8" }
+
+func (e *csvExporter) begin() error { return e.writer.Write(exportColumns) }
+
+func (e *csvExporter) write(book *models.Book) error {
+	return e.writer.Write([]string{
+		strconv.FormatUint(uint64(book.ID), 10),
+		book.Title,
+		book.Author,
+		strconv.Itoa(book.Year),
+		book.ISBN10,
+		book.ISBN13,
+		strconv.FormatUint(uint64(book.Version), 10),
+		book.CreatedAt.UTC().Format(time.RFC3339),
+		book.UpdatedAt.UTC().Format(time.RFC3339),
+	})
+}
+
+func (e *csvExporter) end() error {
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+// fail sends the rows still buffered; CSV has no room for an error record.
+func (e *csvExporter) fail(written int) error { return e.end() }
+
+type ndjsonExporter struct {
+	encoder *json.Encoder
+}
+
+func (e *ndjsonExporter) contentType() string { return "application/x-ndjson" }
+
+func (e *ndjsonExporter) begin() error { return nil }
+
+func (e *ndjsonExporter) write(book *models.Book) error { return e.encoder.Encode(book) }
+
+func (e *ndjsonExporter) end() error { return nil }
+
+// fail writes a last record that can't be mistaken for a book.
+func (e *ndjsonExporter) fail(written int) error {
+	return e.encoder.Encode(gin.H{"error": "export truncated", "written": written})
+}
+
+// jsonExporter writes a single JSON array one element at a time.
+type jsonExporter struct {
+	w       gin.ResponseWriter
+	written bool
+}
+
+func (e *jsonExporter) contentType() string { return "application/json; charset=utf-8" }
+
+func (e *jsonExporter) begin() error {
+	_, err := e.w.WriteString("[")
+	return err
+}
+
+func (e *jsonExporter) write(book *models.Book) error {
+	data, err := json.Marshal(book)
+	if err != nil {
+		return err
+	}
+	if e.written {
+		if _, err := e.w.WriteString(","); err != nil {
+			return err
+		}
+	}
+	e.written = true
+	_, err = e.w.Write(data)
+	return err
+}
+
+func (e *jsonExporter) end() error {
+	_, err := e.w.WriteString("]")
+	return err
+}
+
+// fail leaves the array unclosed so the body doesn't parse as JSON.
+func (e *jsonExporter) fail(written int) error { return nil }
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shani34/book-management-system/internal/middleware"
+	"github.com/shani34/book-management-system/internal/models"
+	"github.com/shani34/book-management-system/internal/repositories"
+	"github.com/shani34/book-management-system/internal/services"
+	"github.com/shani34/book-management-system/pkg/cache"
+	"go.uber.org/zap"
+)
+
+// brokenCursorStore fails an export after streaming the first after books,
+// as a dropped database connection would.
+type brokenCursorStore struct {
+	repositories.BookStore
+	after int
+}
+
+func (s brokenCursorStore) ForTenant(tenantID string) repositories.BookStore {
+	return brokenCursorStore{BookStore: s.BookStore.ForTenant(tenantID), after: s.after}
+}
+
+func (s brokenCursorStore) Each(filter models.BookFilter, fn func(book *models.Book) error) error {
+	seen := 0
+	return s.BookStore.Each(filter, func(book *models.Book) error {
+		if seen == s.after {
+			return errors.New("connection reset")
+		}
+		seen++
+		return fn(book)
+	})
+}
+
+// newExportRouter serves the export of three books from a store whose
+// cursor breaks after failAfter of them, or never when failAfter is -1.
+func newExportRouter(t *testing.T, failAfter int) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	var store repositories.BookStore = repositories.NewMemoryBookRepository(repositories.NewMemoryDB())
+	if failAfter >= 0 {
+		store = brokenCursorStore{BookStore: store, after: failAfter}
+	}
+	service := services.NewBookService(store, cache.Noop{})
+	for _, title := range []string{"Dune", "Emma", "Ulysses"} {
+		if err := service.ForTenant("t").CreateBook(&models.Book{Title: title, Author: "A", Year: 1900}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Set(middleware.TenantKey, "t") })
+	router.GET("/books/export", NewBookHandler(service, zap.NewNop()).ExportBooks)
+	return router
+}
+
+func TestExportComplete(t *testing.T) {
+	router := newExportRouter(t, -1)
+	for _, format := range []string{"csv", "ndjson", "json"} {
+		recorder := serve(router, http.MethodGet, "/books/export?format="+format, "")
+		trailer := recorder.Result().Trailer
+		if recorder.Code != http.StatusOK || trailer.Get("X-Export-Status") != "complete" || trailer.Get("X-Export-Count") != "3" {
+			t.Errorf("%s: got %d with trailer %v, want 200 complete with 3", format, recorder.Code, trailer)
+		}
+	}
+
+	recorder := serve(router, http.MethodGet, "/books/export?format=json", "")
+	var books []models.Book
+	if err := json.Unmarshal(recorder.Body.Bytes(), &books); err != nil || len(books) != 3 {
+		t.Errorf("json: got %d books, %v", len(books), err)
+	}
+}
+
+func TestExportTruncated(t *testing.T) {
+	router := newExportRouter(t, 2)
+
+	recorder := serve(router, http.MethodGet, "/books/export?format=ndjson", "")
+	trailer := recorder.Result().Trailer
+	if recorder.Code != http.StatusOK || trailer.Get("X-Export-Status") != "truncated" || trailer.Get("X-Export-Count") != "2" {
+		t.Errorf("ndjson: got %d with trailer %v, want 200 truncated with 2", recorder.Code, trailer)
+	}
+	lines := strings.Split(strings.TrimSpace(recorder.Body.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("ndjson: got %d lines %q, want 2 books and an error", len(lines), lines)
+	}
+	var last map[string]any
+	if err := json.Unmarshal([]byte(lines[2]), &last); err != nil || last["error"] != "export truncated" || last["written"] != float64(2) {
+		t.Errorf("ndjson: got last line %s, want the error record", lines[2])
+	}
+
+	recorder = serve(router, http.MethodGet, "/books/export?format=csv", "")
+	if got := recorder.Result().Trailer.Get("X-Export-Status"); got != "truncated" {
+		t.Errorf("csv: got status %q, want truncated", got)
+	}
+	if rows := strings.Count(recorder.Body.String(), "\n"); rows != 3 {
+		t.Errorf("csv: got %d rows %q, want the header and 2 books", rows, recorder.Body)
+	}
+
+	recorder = serve(router, http.MethodGet, "/books/export?format=json", "")
+	if got := recorder.Result().Trailer.Get("X-Export-Status"); got != "truncated" {
+		t.Errorf("json: got status %q, want truncated", got)
+	}
+	if json.Valid(recorder.Body.Bytes()) {
+		t.Errorf("json: truncated body %s parses", recorder.Body)
+	}
+}
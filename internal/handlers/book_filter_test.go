@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shani34/book-management-system/internal/models"
+)
+
+func parseQuery(query string) (models.BookFilter, error) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/books?"+query, nil)
+	return parseBookFilter(c)
+}
+
+func TestParseBookFilter(t *testing.T) {
+	filter, err := parseQuery("author=Jane+Austen&author_contains=aus&title_prefix=Em&year_from=0&year_to=1900&sort=year&order=desc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filter.Author != "Jane Austen" || filter.AuthorContains != "aus" || filter.TitlePrefix != "Em" ||
+		filter.YearFrom == nil || *filter.YearFrom != 0 || filter.YearTo == nil || *filter.YearTo != 1900 ||
+		filter.Sort != "year" || !filter.Desc {
+		t.Errorf("got %+v", filter)
+	}
+}
+
+func TestParseBookFilterErrors(t *testing.T) {
+	tests := map[string]bool{
+		"":                            true,
+		"order=asc":                   true,
+		"order=up":                    false,
+		"sort=isbn":                   false,
+		"year_from=last":              false,
+		"year_to=1.5":                 false,
+		"year_from=2000&year_to=1999": false,
+		"year_from=-50":               true,
+	}
+	for query, ok := range tests {
+		if _, err := parseQuery(query); (err == nil) != ok {
+			t.Errorf("%q: got %v", query, err)
+		}
+	}
+}
@@ -2,13 +2,18 @@ package handlers
 
 import (
 	"errors"
+	"fmt"
 	"github.com/gin-gonic/gin"
+	"github.com/shani34/book-management-system/internal/middleware"
 	"github.com/shani34/book-management-system/internal/models"
 	"github.com/shani34/book-management-system/internal/services"
+	"github.com/shani34/book-management-system/pkg/patch"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 	"net/http"
+	"path"
 	"strconv"
+	"strings"
 )
 
 type BookHandler struct {
@@ -23,27 +28,60 @@ func NewBookHandler(service *services.BookService, logger *zap.Logger) *BookHand
 	}
 }
 
+// books returns the book service for the request's tenant.
+func (h *BookHandler) books(c *gin.Context) *services.BookService {
+	return h.service.ForTenant(middleware.Tenant(c))
+}
+
 // GetBooks godoc
 // @Summary List books
-// @Description Get paginated list of books
+// @Description Get paginated list of books, optionally filtered and sorted
 // @Tags books
 // @Accept json
 // @Produce json
 // @Param limit query int false "Limit"
 // @Param offset query int false "Offset"
+// @Param author query string false "Exact author match"
+// @Param author_contains query string false "Case-insensitive author substring"
+// @Param title_prefix query string false "Case-insensitive title prefix"
+// @Param year_from query int false "Earliest publication year (inclusive)"
+// @Param year_to query int false "Latest publication year (inclusive)"
+// @Param tag query []string false "Tag name; repeat for several" collectionFormat(multi)
+// @Param tag_match query string false "Match books with any or all of the tags" Enums(any, all)
+// @Param sort query string false "Sort field" Enums(title, author, year, created_at)
+// @Param order query string false "Sort direction" Enums(asc, desc)
+// @Param cursor query string false "Cursor from a previous page; pass it empty to start cursor pagination, which returns a models.BookPage envelope instead of an array"
 // @Success 200 {array} models.Book
+// @Failure 400 {object} map[string]string
+// @Failure 429 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /books [get]
 func (h *BookHandler) GetBooks(c *gin.Context) {
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
 	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
 
+	filter, err := parseBookFilter(c)
+	if err != nil {
+		h.logger.Warn("Invalid book filter",
+			zap.String("query", c.Request.URL.RawQuery),
+			zap.Error(err),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if cursor, ok := c.GetQuery("cursor"); ok {
+		h.getBooksPage(c, filter, cursor, limit)
+		return
+	}
+
 	h.logger.Info("Starting GetBooks request",
 		zap.Int("limit", limit),
 		zap.Int("offset", offset),
+		zap.String("filter", filter.CacheKey()),
 	)
 
-	books, err := h.service.GetAllBooks(limit, offset)
+	books, err := h.books(c).GetAllBooks(filter, limit, offset)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			h.logger.Warn("No books found", 
@@ -68,6 +106,107 @@ func (h *BookHandler) GetBooks(c *gin.Context) {
 	c.JSON(http.StatusOK, books)
 }
 
+// getBooksPage serves GetBooks in cursor mode. Offset mode stays the
+// default so existing clients keep receiving a bare array.
+func (h *BookHandler) getBooksPage(c *gin.Context, filter models.BookFilter, cursor string, limit int) {
+	if limit <= 0 {
+		limit = 10
+	}
+
+	h.logger.Info("Starting GetBooks cursor request",
+		zap.Int("limit", limit),
+		zap.String("filter", filter.CacheKey()),
+	)
+
+	page, err := h.books(c).GetBooksPage(filter, cursor, limit)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidCursor) {
+			h.logger.Warn("Invalid cursor", zap.String("cursor", cursor))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid cursor"})
+		} else {
+			h.logger.Error("Failed to retrieve books page",
+				zap.Error(err),
+				zap.Int("limit", limit),
+			)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	var links []string
+	if page.NextCursor != "" {
+		links = append(links, fmt.Sprintf(`<%s>; rel="next"`, pageURL(c, page.NextCursor)))
+	}
+	if page.PrevCursor != "" {
+		links = append(links, fmt.Sprintf(`<%s>; rel="prev"`, pageURL(c, page.PrevCursor)))
+	}
+	if len(links) > 0 {
+		c.Header("Link", strings.Join(links, ", "))
+	}
+
+	h.logger.Info("Successfully retrieved books page",
+		zap.Int("count", len(page.Items)),
+		zap.Int64("total", page.Total),
+	)
+	c.JSON(http.StatusOK, page)
+}
+
+// pageURL is the current request URL with its cursor swapped for cursor,
+// for use as an RFC 8288 link target.
+func pageURL(c *gin.Context, cursor string) string {
+	query := c.Request.URL.Query()
+	query.Set("cursor", cursor)
+	query.Del("offset")
+	return c.Request.URL.Path + "?" + query.Encode()
+}
+
+// SearchBooks godoc
+// @Summary Search books
+// @Description Full-text search over title and author, ranked by relevance with highlighted matches
+// @Tags books
+// @Accept json
+// @Produce json
+// @Param q query string true "Search query"
+// @Param limit query int false "Limit"
+// @Param offset query int false "Offset"
+// @Success 200 {array} models.BookSearchResult
+// @Failure 400 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /books/search [get]
+func (h *BookHandler) SearchBooks(c *gin.Context) {
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		h.logger.Warn("Missing search query")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "query parameter q is required"})
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	h.logger.Info("Searching books",
+		zap.String("query", query),
+		zap.Int("limit", limit),
+		zap.Int("offset", offset),
+	)
+
+	results, err := h.books(c).SearchBooks(query, limit, offset)
+	if err != nil {
+		h.logger.Error("Failed to search books",
+			zap.String("query", query),
+			zap.Error(err),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	h.logger.Info("Search completed",
+		zap.String("query", query),
+		zap.Int("count", len(results)),
+	)
+	c.JSON(http.StatusOK, results)
+}
+
 // GetBook godoc
 // @Summary Get a book
 // @Description Get book by ID
@@ -75,8 +214,12 @@ func (h *BookHandler) GetBooks(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path int true "Book ID"
+// @Param If-None-Match header string false "ETag from a previous response"
 // @Success 200 {object} models.Book
+// @Success 304
+// @Header 200 {string} ETag "Current version of the book"
 // @Failure 404 {object} map[string]string
+// @Failure 429 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /books/{id} [get]
 func (h *BookHandler) GetBook(c *gin.Context) {
@@ -92,7 +235,7 @@ func (h *BookHandler) GetBook(c *gin.Context) {
 
 	h.logger.Info("Fetching book", zap.Int("book_id", id))
 
-	book, err := h.service.GetBookByID(uint(id))
+	book, err := h.books(c).GetBookByID(uint(id))
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			h.logger.Warn("Book not found", zap.Int("book_id", id))
@@ -107,10 +250,67 @@ func (h *BookHandler) GetBook(c *gin.Context) {
 		return
 	}
 
+	etag := bookETag(book)
+	c.Header("ETag", etag)
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
 	h.logger.Info("Successfully retrieved book", zap.Int("book_id", id))
 	c.JSON(http.StatusOK, book)
 }
 
+// GetBookByISBN godoc
+// @Summary Get a book by ISBN
+// @Description Get book by ISBN-10 or ISBN-13; hyphens are ignored
+// @Tags books
+// @Accept json
+// @Produce json
+// @Param isbn path string true "ISBN-10 or ISBN-13"
+// @Param If-None-Match header string false "ETag from a previous response"
+// @Success 200 {object} models.Book
+// @Success 304
+// @Header 200 {string} ETag "Current version of the book"
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /books/isbn/{isbn} [get]
+func (h *BookHandler) GetBookByISBN(c *gin.Context) {
+	value := c.Param("isbn")
+
+	h.logger.Info("Fetching book by ISBN", zap.String("isbn", value))
+
+	book, err := h.books(c).GetBookByISBN(value)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidISBN) {
+			h.logger.Warn("Invalid ISBN", zap.String("isbn", value), zap.Error(err))
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else if errors.Is(err, gorm.ErrRecordNotFound) {
+			h.logger.Warn("Book not found", zap.String("isbn", value))
+			c.JSON(http.StatusNotFound, gin.H{"error": "book not found"})
+		} else {
+			h.logger.Error("Failed to fetch book",
+				zap.String("isbn", value),
+				zap.Error(err),
+			)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	etag := bookETag(book)
+	c.Header("ETag", etag)
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	h.logger.Info("Successfully retrieved book", zap.Uint("book_id", book.ID))
+	c.JSON(http.StatusOK, book)
+}
+
 // CreateBook godoc
 // @Summary Create book
 // @Description Create new book
@@ -120,7 +320,12 @@ func (h *BookHandler) GetBook(c *gin.Context) {
 // @Param book body models.BookRequest true "Book data"
 // @Success 201 {object} models.Book
 // @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 429 {object} map[string]string
 // @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /books [post]
 func (h *BookHandler) CreateBook(c *gin.Context) {
 	var book models.Book
@@ -141,7 +346,17 @@ func (h *BookHandler) CreateBook(c *gin.Context) {
 		zap.Int("year", book.Year),
 	)
 
-	if err := h.service.CreateBook(&book); err != nil {
+	if err := h.books(c).CreateBook(&book); err != nil {
+		if errors.Is(err, services.ErrInvalidBook) {
+			h.logger.Warn("Book failed validation", zap.Error(err))
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, models.ErrDuplicateISBN) {
+			h.logger.Warn("Duplicate ISBN", zap.String("isbn13", book.ISBN13))
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		h.logger.Error("Failed to create book",
 			zap.Error(err),
 			zap.Any("book_data", book),
@@ -153,6 +368,7 @@ func (h *BookHandler) CreateBook(c *gin.Context) {
 	h.logger.Info("Book created successfully", 
 		zap.Uint("book_id", book.ID),
 	)
+	c.Header("ETag", bookETag(&book))
 	c.JSON(http.StatusCreated, book)
 }
 
@@ -163,11 +379,19 @@ func (h *BookHandler) CreateBook(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path int true "Book ID"
+// @Param If-Match header string true "ETag of the version being replaced, or *"
 // @Param book body models.BookRequest true "Book data"
 // @Success 200 {object} models.Book
 // @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
 // @Failure 404 {object} map[string]string
+// @Failure 412 {object} map[string]string
+// @Failure 428 {object} map[string]string
+// @Failure 429 {object} map[string]string
 // @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /books/{id} [put]
 func (h *BookHandler) UpdateBook(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
@@ -180,6 +404,11 @@ func (h *BookHandler) UpdateBook(c *gin.Context) {
 		return
 	}
 
+	version, ok := h.requireIfMatch(c)
+	if !ok {
+		return
+	}
+
 	var book models.Book
 	if err := c.ShouldBindJSON(&book); err != nil {
 		h.logger.Warn("Invalid request body for update",
@@ -194,12 +423,27 @@ func (h *BookHandler) UpdateBook(c *gin.Context) {
 		zap.Any("update_data", book),
 	)
 
-	if err := h.service.UpdateBook(uint(id), &book); err != nil {
+	if err := h.books(c).UpdateBook(uint(id), version, &book); err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			h.logger.Warn("Book not found for update",
 				zap.Int("book_id", id),
 			)
 			c.JSON(http.StatusNotFound, gin.H{"error": "book not found, cannot update"})
+		} else if errors.Is(err, models.ErrVersionConflict) {
+			h.logger.Warn("Stale version for update", zap.Int("book_id", id))
+			c.JSON(http.StatusPreconditionFailed, gin.H{"error": "book has been modified"})
+		} else if errors.Is(err, services.ErrInvalidBook) {
+			h.logger.Warn("Book failed validation",
+				zap.Int("book_id", id),
+				zap.Error(err),
+			)
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else if errors.Is(err, models.ErrDuplicateISBN) {
+			h.logger.Warn("Duplicate ISBN",
+				zap.Int("book_id", id),
+				zap.String("isbn13", book.ISBN13),
+			)
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
 		} else {
 			h.logger.Error("Failed to update book",
 				zap.Int("book_id", id),
@@ -211,19 +455,130 @@ func (h *BookHandler) UpdateBook(c *gin.Context) {
 	}
 
 	h.logger.Info("Book updated successfully", zap.Int("book_id", id))
+	c.Header("ETag", bookETag(&book))
+	c.JSON(http.StatusOK, book)
+}
+
+// PatchBook godoc
+// @Summary Patch book
+// @Description Partially update a book with a JSON Merge Patch (RFC 7396) or JSON Patch (RFC 6902) document. The patched book is validated before it is saved.
+// @Tags books
+// @Accept application/merge-patch+json,application/json-patch+json
+// @Produce json
+// @Param id path int true "Book ID"
+// @Param If-Match header string true "ETag of the version being patched, or *"
+// @Param patch body object true "Merge patch object or JSON Patch operation array"
+// @Success 200 {object} models.Book
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 412 {object} map[string]string
+// @Failure 415 {object} map[string]string
+// @Failure 428 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /books/{id} [patch]
+func (h *BookHandler) PatchBook(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.logger.Warn("Invalid book ID format",
+			zap.String("received_id", c.Param("id")),
+			zap.Error(err),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid book ID format"})
+		return
+	}
+
+	var apply func(doc, patchDoc []byte) ([]byte, error)
+	switch contentType := c.ContentType(); contentType {
+	case patch.MergePatchContentType:
+		apply = patch.MergePatch
+	case patch.JSONPatchContentType:
+		apply = patch.JSONPatch
+	default:
+		h.logger.Warn("Unsupported patch content type",
+			zap.Int("book_id", id),
+			zap.String("content_type", contentType),
+		)
+		c.Header("Accept-Patch", patch.MergePatchContentType+", "+patch.JSONPatchContentType)
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "unsupported patch content type"})
+		return
+	}
+
+	version, ok := h.requireIfMatch(c)
+	if !ok {
+		return
+	}
+
+	body, err := c.GetRawData()
+	if err != nil {
+		h.logger.Warn("Failed to read patch body", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	h.logger.Info("Patching book",
+		zap.Int("book_id", id),
+		zap.String("content_type", c.ContentType()),
+	)
+
+	book, err := h.books(c).PatchBook(uint(id), version, func(doc []byte) ([]byte, error) {
+		return apply(doc, body)
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			h.logger.Warn("Book not found for patch", zap.Int("book_id", id))
+			c.JSON(http.StatusNotFound, gin.H{"error": "book not found"})
+		case errors.Is(err, models.ErrVersionConflict):
+			h.logger.Warn("Stale version for patch", zap.Int("book_id", id))
+			c.JSON(http.StatusPreconditionFailed, gin.H{"error": "book has been modified"})
+		case errors.Is(err, patch.ErrTestFailed):
+			h.logger.Warn("Patch test failed", zap.Int("book_id", id), zap.Error(err))
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, models.ErrDuplicateISBN):
+			h.logger.Warn("Duplicate ISBN", zap.Int("book_id", id))
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, patch.ErrInvalidPatch), errors.Is(err, services.ErrInvalidBook):
+			h.logger.Warn("Patch rejected", zap.Int("book_id", id), zap.Error(err))
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			h.logger.Error("Failed to patch book",
+				zap.Int("book_id", id),
+				zap.Error(err),
+			)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to patch book"})
+		}
+		return
+	}
+
+	h.logger.Info("Book patched successfully", zap.Int("book_id", id))
+	c.Header("ETag", bookETag(book))
 	c.JSON(http.StatusOK, book)
 }
 
 // DeleteBook godoc
 // @Summary Delete book
-// @Description Delete book by ID
+// @Description Move a book to the trash. It can be restored or purged from there.
 // @Tags books
 // @Accept json
 // @Produce json
 // @Param id path int true "Book ID"
+// @Param If-Match header string true "ETag of the version being deleted, or *"
 // @Success 204
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
 // @Failure 404 {object} map[string]string
+// @Failure 412 {object} map[string]string
+// @Failure 428 {object} map[string]string
+// @Failure 429 {object} map[string]string
 // @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /books/{id} [delete]
 func (h *BookHandler) DeleteBook(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
@@ -236,14 +591,22 @@ func (h *BookHandler) DeleteBook(c *gin.Context) {
 		return
 	}
 
+	version, ok := h.requireIfMatch(c)
+	if !ok {
+		return
+	}
+
 	h.logger.Info("Deleting book", zap.Int("book_id", id))
 
-	if err := h.service.DeleteBook(uint(id)); err != nil {
+	if err := h.books(c).DeleteBook(uint(id), version); err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			h.logger.Warn("Book not found for deletion",
 				zap.Int("book_id", id),
 			)
 			c.JSON(http.StatusNotFound, gin.H{"error": "book not found"})
+		} else if errors.Is(err, models.ErrVersionConflict) {
+			h.logger.Warn("Stale version for deletion", zap.Int("book_id", id))
+			c.JSON(http.StatusPreconditionFailed, gin.H{"error": "book has been modified"})
 		} else {
 			h.logger.Error("Failed to delete book",
 				zap.Int("book_id", id),
@@ -256,4 +619,428 @@ func (h *BookHandler) DeleteBook(c *gin.Context) {
 
 	h.logger.Info("Book deleted successfully", zap.Int("book_id", id))
 	c.Status(http.StatusNoContent)
-}
\ No newline at end of file
+}
+
+// maxImportBytes caps the size of an import upload.
+const maxImportBytes = 32 << 20
+
+// ImportBooks godoc
+// @Summary Import books
+// @Description Bulk-create books from a CSV (header row with title, author, year) or NDJSON upload of up to 32 MiB. Valid rows are inserted in batches; the report lists the outcome of every row, including rows whose ISBN is already in the catalogue or on an earlier row.
+// @Tags books
+// @Accept multipart/form-data,text/csv,application/x-ndjson
+// @Produce json
+// @Param file formData file false "CSV or NDJSON file"
+// @Param format query string false "Input format, detected from the content type or file name when omitted" Enums(csv, ndjson)
+// @Success 200 {object} models.ImportReport
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 413 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /books/import [post]
+func (h *BookHandler) ImportBooks(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBytes)
+	body := c.Request.Body
+	format := c.Query("format")
+	contentType := c.ContentType()
+
+	if contentType == "multipart/form-data" {
+		fileHeader, err := c.FormFile("file")
+		if h.importTooLarge(c, err) {
+			return
+		}
+		if err != nil {
+			h.logger.Warn("Missing import file", zap.Error(err))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "multipart upload must include a file field"})
+			return
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			h.logger.Error("Failed to open import file", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to read upload"})
+			return
+		}
+		defer file.Close()
+		body = file
+		if format == "" {
+			format = importFormat(fileHeader.Header.Get("Content-Type"), fileHeader.Filename)
+		}
+	} else if format == "" {
+		format = importFormat(contentType, "")
+	}
+
+	h.logger.Info("Starting book import", zap.String("format", format))
+
+	report, err := h.books(c).ImportBooks(body, format)
+	if h.importTooLarge(c, err) {
+		return
+	}
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidImport) {
+			h.logger.Warn("Rejected import", zap.Error(err))
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			h.logger.Error("Failed to import books", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to import books"})
+		}
+		return
+	}
+
+	h.logger.Info("Book import finished",
+		zap.Int("total", report.Total),
+		zap.Int("created", report.Created),
+		zap.Int("failed", report.Failed),
+	)
+	c.JSON(http.StatusOK, report)
+}
+
+// importTooLarge answers 413 if err is from reading past maxImportBytes.
+func (h *BookHandler) importTooLarge(c *gin.Context, err error) bool {
+	var tooLarge *http.MaxBytesError
+	if !errors.As(err, &tooLarge) {
+		return false
+	}
+	h.logger.Warn("Import upload too large", zap.Int64("limit", tooLarge.Limit))
+	c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("upload is larger than %d bytes", tooLarge.Limit)})
+	return true
+}
+
+// importFormat guesses the import format from a content type, falling back
+// to the file extension.
+func importFormat(contentType, filename string) string {
+	switch {
+	case strings.HasPrefix(contentType, "text/csv"):
+		return "csv"
+	case strings.HasPrefix(contentType, "application/x-ndjson"),
+		strings.HasPrefix(contentType, "application/ndjson"),
+		strings.HasPrefix(contentType, "application/jsonl"):
+		return "ndjson"
+	}
+	switch strings.ToLower(path.Ext(filename)) {
+	case ".csv":
+		return "csv"
+	case ".ndjson", ".jsonl":
+		return "ndjson"
+	}
+	return ""
+}
+
+// ListTrash godoc
+// @Summary List deleted books
+// @Description List soft-deleted books, most recently deleted first
+// @Tags books
+// @Accept json
+// @Produce json
+// @Param limit query int false "Limit"
+// @Param offset query int false "Offset"
+// @Success 200 {array} models.Book
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /books/trash [get]
+func (h *BookHandler) ListTrash(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	h.logger.Info("Listing trash",
+		zap.Int("limit", limit),
+		zap.Int("offset", offset),
+	)
+
+	books, err := h.books(c).ListTrash(limit, offset)
+	if err != nil {
+		h.logger.Error("Failed to list trash", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	h.logger.Info("Successfully listed trash", zap.Int("count", len(books)))
+	c.JSON(http.StatusOK, books)
+}
+
+// RestoreBook godoc
+// @Summary Restore book
+// @Description Move a soft-deleted book out of the trash
+// @Tags books
+// @Accept json
+// @Produce json
+// @Param id path int true "Book ID"
+// @Success 200 {object} models.Book
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /books/{id}/restore [post]
+func (h *BookHandler) RestoreBook(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.logger.Warn("Invalid book ID format",
+			zap.String("received_id", c.Param("id")),
+			zap.Error(err),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid book ID format"})
+		return
+	}
+
+	h.logger.Info("Restoring book", zap.Int("book_id", id))
+
+	book, err := h.books(c).RestoreBook(uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			h.logger.Warn("Book not in trash", zap.Int("book_id", id))
+			c.JSON(http.StatusNotFound, gin.H{"error": "book not found in trash"})
+		} else {
+			h.logger.Error("Failed to restore book",
+				zap.Int("book_id", id),
+				zap.Error(err),
+			)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to restore book"})
+		}
+		return
+	}
+
+	h.logger.Info("Book restored successfully", zap.Int("book_id", id))
+	c.Header("ETag", bookETag(book))
+	c.JSON(http.StatusOK, book)
+}
+
+// PurgeBook godoc
+// @Summary Purge book
+// @Description Permanently delete a book that is in the trash
+// @Tags books
+// @Accept json
+// @Produce json
+// @Param id path int true "Book ID"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /books/trash/{id} [delete]
+func (h *BookHandler) PurgeBook(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.logger.Warn("Invalid book ID format",
+			zap.String("received_id", c.Param("id")),
+			zap.Error(err),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid book ID format"})
+		return
+	}
+
+	h.logger.Info("Purging book", zap.Int("book_id", id))
+
+	if err := h.books(c).PurgeBook(uint(id)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			h.logger.Warn("Book not in trash", zap.Int("book_id", id))
+			c.JSON(http.StatusNotFound, gin.H{"error": "book not found in trash"})
+		} else if errors.Is(err, models.ErrBookHasCopies) {
+			h.logger.Warn("Book has copies", zap.Int("book_id", id))
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		} else {
+			h.logger.Error("Failed to purge book",
+				zap.Int("book_id", id),
+				zap.Error(err),
+			)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to purge book"})
+		}
+		return
+	}
+
+	h.logger.Info("Book purged successfully", zap.Int("book_id", id))
+	c.Status(http.StatusNoContent)
+}
+
+// AttachTag godoc
+// @Summary Attach tag
+// @Description Add a tag from the vocabulary to a book
+// @Tags books
+// @Accept json
+// @Produce json
+// @Param id path int true "Book ID"
+// @Param tag_id path int true "Tag ID"
+// @Success 200 {object} models.Book
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /books/{id}/tags/{tag_id} [put]
+func (h *BookHandler) AttachTag(c *gin.Context) {
+	h.changeTag(c, true)
+}
+
+// DetachTag godoc
+// @Summary Detach tag
+// @Description Remove a tag from a book
+// @Tags books
+// @Accept json
+// @Produce json
+// @Param id path int true "Book ID"
+// @Param tag_id path int true "Tag ID"
+// @Success 200 {object} models.Book
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /books/{id}/tags/{tag_id} [delete]
+func (h *BookHandler) DetachTag(c *gin.Context) {
+	h.changeTag(c, false)
+}
+
+func (h *BookHandler) changeTag(c *gin.Context, attach bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.logger.Warn("Invalid book ID format",
+			zap.String("received_id", c.Param("id")),
+			zap.Error(err),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid book ID format"})
+		return
+	}
+	tagID, err := strconv.Atoi(c.Param("tag_id"))
+	if err != nil {
+		h.logger.Warn("Invalid tag ID format",
+			zap.String("received_id", c.Param("tag_id")),
+			zap.Error(err),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid tag ID format"})
+		return
+	}
+
+	h.logger.Info("Changing book tags",
+		zap.Int("book_id", id),
+		zap.Int("tag_id", tagID),
+		zap.Bool("attach", attach),
+	)
+
+	var book *models.Book
+	if attach {
+		book, err = h.books(c).AttachTag(uint(id), uint(tagID))
+	} else {
+		book, err = h.books(c).DetachTag(uint(id), uint(tagID))
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrTagNotFound):
+			h.logger.Warn("Tag not found", zap.Int("tag_id", tagID))
+			c.JSON(http.StatusNotFound, gin.H{"error": "tag not found"})
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			h.logger.Warn("Book not found", zap.Int("book_id", id))
+			c.JSON(http.StatusNotFound, gin.H{"error": "book not found"})
+		case errors.Is(err, models.ErrVersionConflict):
+			h.logger.Warn("Concurrent change to book tags", zap.Int("book_id", id))
+			c.JSON(http.StatusConflict, gin.H{"error": "book was modified concurrently, retry"})
+		default:
+			h.logger.Error("Failed to change book tags",
+				zap.Int("book_id", id),
+				zap.Int("tag_id", tagID),
+				zap.Error(err),
+			)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to change book tags"})
+		}
+		return
+	}
+
+	h.logger.Info("Book tags changed successfully", zap.Int("book_id", id))
+	c.Header("ETag", bookETag(book))
+	c.JSON(http.StatusOK, book)
+}
+
+// bookETag is the strong entity tag for the stored version of book.
+func bookETag(book *models.Book) string {
+	return fmt.Sprintf(`"%d"`, book.Version)
+}
+
+// requireIfMatch reads the version a write is conditional on. If-Match: *
+// yields 0, meaning any version. A missing header is answered with 428 and
+// one that can never match (e.g. a weak tag) with 412; ok is false then.
+func (h *BookHandler) requireIfMatch(c *gin.Context) (uint, bool) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" {
+		h.logger.Warn("Missing If-Match header", zap.String("path", c.Request.URL.Path))
+		c.JSON(http.StatusPreconditionRequired, gin.H{"error": "If-Match header is required"})
+		return 0, false
+	}
+	if header == "*" {
+		return 0, true
+	}
+
+	version, err := strconv.ParseUint(strings.Trim(header, `"`), 10, 64)
+	if err != nil || version == 0 || !strings.HasPrefix(header, `"`) || !strings.HasSuffix(header, `"`) {
+		h.logger.Warn("Unusable If-Match header", zap.String("if_match", header))
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": "book has been modified"})
+		return 0, false
+	}
+	return uint(version), true
+}
+
+// parseBookFilter reads the list filters and sort order from the query
+// string.
+func parseBookFilter(c *gin.Context) (models.BookFilter, error) {
+	filter := models.BookFilter{
+		Author:         c.Query("author"),
+		AuthorContains: c.Query("author_contains"),
+		TitlePrefix:    c.Query("title_prefix"),
+		Sort:           c.Query("sort"),
+	}
+
+	for param, target := range map[string]**int{
+		"year_from": &filter.YearFrom,
+		"year_to":   &filter.YearTo,
+	} {
+		raw := c.Query(param)
+		if raw == "" {
+			continue
+		}
+		year, err := strconv.Atoi(raw)
+		if err != nil {
+			return filter, fmt.Errorf("invalid %s", param)
+		}
+		*target = &year
+	}
+
+	for _, tag := range c.QueryArray("tag") {
+		if tag = models.NormalizeTagName(tag); tag != "" {
+			filter.Tags = append(filter.Tags, tag)
+		}
+	}
+	switch match := c.DefaultQuery("tag_match", "any"); match {
+	case "any":
+	case "all":
+		filter.AllTags = true
+	default:
+		return filter, fmt.Errorf("invalid tag_match %q", match)
+	}
+
+	switch order := c.DefaultQuery("order", "asc"); order {
+	case "asc":
+	case "desc":
+		filter.Desc = true
+	default:
+		return filter, fmt.Errorf("invalid order %q", order)
+	}
+
+	return filter, filter.Validate()
+}
@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shani34/book-management-system/internal/middleware"
+	"github.com/shani34/book-management-system/internal/models"
+	"github.com/shani34/book-management-system/internal/repositories"
+	"github.com/shani34/book-management-system/internal/services"
+	"github.com/shani34/book-management-system/pkg/cache"
+	"go.uber.org/zap"
+)
+
+// newBookRouter serves the book routes on a memory store without a cache,
+// as tenant "t".
+func newBookRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	service := services.NewBookService(repositories.NewMemoryBookRepository(repositories.NewMemoryDB()), cache.Noop{})
+	handler := NewBookHandler(service, zap.NewNop())
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Set(middleware.TenantKey, "t") })
+	router.GET("/books", handler.GetBooks)
+	router.POST("/books", handler.CreateBook)
+	router.GET("/books/isbn/:isbn", handler.GetBookByISBN)
+	router.GET("/books/:id", handler.GetBook)
+	router.PUT("/books/:id", handler.UpdateBook)
+	router.PATCH("/books/:id", handler.PatchBook)
+	router.DELETE("/books/:id", handler.DeleteBook)
+	return router
+}
+
+const mergePatch = "application/merge-patch+json"
+
+// serve sends a request with body, as JSON unless the name/value pairs in
+// headers set another Content-Type.
+func serve(router *gin.Engine, method, path, body string, headers ...string) *httptest.ResponseRecorder {
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	request := httptest.NewRequest(method, path, reader)
+	if body != "" {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		request.Header.Set(headers[i], headers[i+1])
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func decodeBook(t *testing.T, recorder *httptest.ResponseRecorder) models.Book {
+	t.Helper()
+	var book models.Book
+	if err := json.Unmarshal(recorder.Body.Bytes(), &book); err != nil {
+		t.Fatalf("decoding %s: %v", recorder.Body, err)
+	}
+	return book
+}
+
+func TestBookCRUD(t *testing.T) {
+	router := newBookRouter()
+
+	recorder := serve(router, http.MethodPost, "/books", `{"title":"Dune","author":"Frank Herbert","year":1965,"isbn13":"978-0-441-01359-3"}`)
+	if recorder.Code != http.StatusCreated {
+		t.Fatalf("create: got %d %s, want 201", recorder.Code, recorder.Body)
+	}
+	created := decodeBook(t, recorder)
+	if created.ID == 0 || created.Version != 1 || created.ISBN13 != "9780441013593" || created.ISBN10 != "0441013597" {
+		t.Errorf("created: got %+v", created)
+	}
+	if got := recorder.Header().Get("ETag"); got != `"1"` {
+		t.Errorf("create ETag: got %q, want \"1\"", got)
+	}
+	path := "/books/" + itoa(created.ID)
+
+	recorder = serve(router, http.MethodGet, path, "")
+	if recorder.Code != http.StatusOK || decodeBook(t, recorder).Title != "Dune" {
+		t.Fatalf("get: got %d %s", recorder.Code, recorder.Body)
+	}
+	if recorder := serve(router, http.MethodGet, path, "", "If-None-Match", `"1"`); recorder.Code != http.StatusNotModified {
+		t.Errorf("get with matching ETag: got %d, want 304", recorder.Code)
+	}
+	if recorder := serve(router, http.MethodGet, "/books/isbn/0-441-01359-7", ""); recorder.Code != http.StatusOK {
+		t.Errorf("get by ISBN-10: got %d %s, want 200", recorder.Code, recorder.Body)
+	}
+
+	recorder = serve(router, http.MethodGet, "/books?author=Frank%20Herbert", "")
+	var listed []models.Book
+	if err := json.Unmarshal(recorder.Body.Bytes(), &listed); err != nil || recorder.Code != http.StatusOK || len(listed) != 1 {
+		t.Errorf("list: got %d %s", recorder.Code, recorder.Body)
+	}
+
+	recorder = serve(router, http.MethodPut, path, `{"title":"Dune","author":"Frank Herbert","year":1966}`, "If-Match", `"1"`)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("update: got %d %s, want 200", recorder.Code, recorder.Body)
+	}
+	if updated := decodeBook(t, recorder); updated.Year != 1966 || updated.Version != 2 {
+		t.Errorf("updated: got %+v", updated)
+	}
+	if recorder := serve(router, http.MethodPut, path, `{"title":"Dune","author":"Frank Herbert"}`, "If-Match", `"1"`); recorder.Code != http.StatusPreconditionFailed {
+		t.Errorf("update at a stale version: got %d, want 412", recorder.Code)
+	}
+
+	recorder = serve(router, http.MethodPatch, path, `{"year":1965}`, "Content-Type", mergePatch, "If-Match", `"2"`)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("patch: got %d %s, want 200", recorder.Code, recorder.Body)
+	}
+	if patched := decodeBook(t, recorder); patched.Year != 1965 || patched.Title != "Dune" || patched.Version != 3 {
+		t.Errorf("patched: got %+v", patched)
+	}
+
+	if recorder := serve(router, http.MethodDelete, path, "", "If-Match", `"3"`); recorder.Code != http.StatusNoContent {
+		t.Fatalf("delete: got %d %s, want 204", recorder.Code, recorder.Body)
+	}
+	if recorder := serve(router, http.MethodGet, path, ""); recorder.Code != http.StatusNotFound {
+		t.Errorf("get after delete: got %d, want 404", recorder.Code)
+	}
+}
+
+func TestBookValidationErrors(t *testing.T) {
+	router := newBookRouter()
+	recorder := serve(router, http.MethodPost, "/books", `{"title":"Dune","author":"Frank Herbert","isbn13":"9780441013593"}`)
+	if recorder.Code != http.StatusCreated {
+		t.Fatalf("create: got %d %s, want 201", recorder.Code, recorder.Body)
+	}
+	path := "/books/" + itoa(decodeBook(t, recorder).ID)
+
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		body    string
+		headers []string
+		want    int
+	}{
+		{"malformed body", http.MethodPost, "/books", `{"title":`, nil, http.StatusBadRequest},
+		{"missing title", http.MethodPost, "/books", `{"author":"Frank Herbert"}`, nil, http.StatusBadRequest},
+		{"blank author", http.MethodPost, "/books", `{"title":"Dune","author":"  "}`, nil, http.StatusBadRequest},
+		{"future year", http.MethodPost, "/books", `{"title":"Dune","author":"Frank Herbert","year":3000}`, nil, http.StatusBadRequest},
+		{"bad check digit", http.MethodPost, "/books", `{"title":"Dune","author":"Frank Herbert","isbn13":"9780441013594"}`, nil, http.StatusBadRequest},
+		{"duplicate isbn", http.MethodPost, "/books", `{"title":"Dune","author":"Frank Herbert","isbn10":"0441013597"}`, nil, http.StatusConflict},
+		{"bad id", http.MethodGet, "/books/abc", "", nil, http.StatusBadRequest},
+		{"bad isbn", http.MethodGet, "/books/isbn/123", "", nil, http.StatusBadRequest},
+		{"bad year filter", http.MethodGet, "/books?year_from=soon", "", nil, http.StatusBadRequest},
+		{"update without If-Match", http.MethodPut, path, `{"title":"Dune","author":"Frank Herbert"}`, nil, http.StatusPreconditionRequired},
+		{"update with weak ETag", http.MethodPut, path, `{"title":"Dune","author":"Frank Herbert"}`, []string{"If-Match", `W/"1"`}, http.StatusPreconditionFailed},
+		{"update missing title", http.MethodPut, path, `{"author":"Frank Herbert"}`, []string{"If-Match", "*"}, http.StatusBadRequest},
+		{"patch as plain JSON", http.MethodPatch, path, `{"year":1965}`, []string{"If-Match", "*"}, http.StatusUnsupportedMediaType},
+		{"delete without If-Match", http.MethodDelete, path, "", nil, http.StatusPreconditionRequired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if recorder := serve(router, tt.method, tt.path, tt.body, tt.headers...); recorder.Code != tt.want {
+				t.Errorf("got %d %s, want %d", recorder.Code, recorder.Body, tt.want)
+			}
+		})
+	}
+}
+
+func TestBookNotFound(t *testing.T) {
+	router := newBookRouter()
+	body := `{"title":"Dune","author":"Frank Herbert"}`
+	for _, tt := range []struct {
+		method  string
+		path    string
+		body    string
+		headers []string
+	}{
+		{http.MethodGet, "/books/42", "", nil},
+		{http.MethodGet, "/books/isbn/9780441013593", "", nil},
+		{http.MethodPut, "/books/42", body, []string{"If-Match", "*"}},
+		{http.MethodPatch, "/books/42", `{"year":1965}`, []string{"Content-Type", mergePatch, "If-Match", "*"}},
+		{http.MethodDelete, "/books/42", "", []string{"If-Match", "*"}},
+	} {
+		if recorder := serve(router, tt.method, tt.path, tt.body, tt.headers...); recorder.Code != http.StatusNotFound {
+			t.Errorf("%s %s: got %d %s, want 404", tt.method, tt.path, recorder.Code, recorder.Body)
+		}
+	}
+}
+
+func itoa(id uint) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shani34/book-management-system/internal/middleware"
+	"github.com/shani34/book-management-system/internal/models"
+	"github.com/shani34/book-management-system/internal/repositories"
+	"github.com/shani34/book-management-system/internal/services"
+	"github.com/shani34/book-management-system/pkg/cache"
+	"go.uber.org/zap"
+)
+
+func newPageRouter(t *testing.T, count int) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	service := services.NewBookService(repositories.NewMemoryBookRepository(repositories.NewMemoryDB()), cache.Noop{})
+	for i := 0; i < count; i++ {
+		if err := service.ForTenant("t").CreateBook(&models.Book{Title: "T", Author: "A", Year: 2000}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Set(middleware.TenantKey, "t") })
+	router.GET("/books", NewBookHandler(service, zap.NewNop()).GetBooks)
+	return router
+}
+
+func getPage(t *testing.T, router *gin.Engine, target string) (*httptest.ResponseRecorder, models.BookPage) {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, target, nil))
+	var page models.BookPage
+	if recorder.Code == http.StatusOK {
+		if err := json.Unmarshal(recorder.Body.Bytes(), &page); err != nil {
+			t.Fatalf("%s: %v", target, err)
+		}
+	}
+	return recorder, page
+}
+
+// linkTargets maps each rel in a Link header to its URL.
+func linkTargets(t *testing.T, header string) map[string]*url.URL {
+	t.Helper()
+	links := make(map[string]*url.URL)
+	for _, link := range strings.Split(header, ", ") {
+		if link == "" {
+			continue
+		}
+		target, rel, ok := strings.Cut(link, "; rel=")
+		if !ok {
+			t.Fatalf("malformed link %q", link)
+		}
+		parsed, err := url.Parse(strings.Trim(target, "<>"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		links[strings.Trim(rel, `"`)] = parsed
+	}
+	return links
+}
+
+func TestGetBooksCursorLinks(t *testing.T) {
+	router := newPageRouter(t, 5)
+
+	recorder, page := getPage(t, router, "/books?cursor=&limit=2&offset=3&sort=year")
+	if recorder.Code != http.StatusOK || len(page.Items) != 2 || page.Total != 5 {
+		t.Fatalf("first page: got %d %s", recorder.Code, recorder.Body)
+	}
+	links := linkTargets(t, recorder.Header().Get("Link"))
+	next, ok := links["next"]
+	if !ok || links["prev"] != nil {
+		t.Fatalf("first page: got links %v, want next only", links)
+	}
+	query := next.Query()
+	if next.Path != "/books" || query.Get("cursor") != page.NextCursor || query.Get("sort") != "year" || query.Get("limit") != "2" || query.Has("offset") {
+		t.Errorf("next link: got %s", next)
+	}
+
+	recorder, page = getPage(t, router, next.String())
+	links = linkTargets(t, recorder.Header().Get("Link"))
+	if recorder.Code != http.StatusOK || page.Items[0].ID != 3 || links["next"] == nil || links["prev"] == nil {
+		t.Fatalf("second page: got %d %s, links %v", recorder.Code, recorder.Body, links)
+	}
+
+	recorder, page = getPage(t, router, links["next"].String())
+	links = linkTargets(t, recorder.Header().Get("Link"))
+	if len(page.Items) != 1 || links["next"] != nil || links["prev"] == nil {
+		t.Errorf("last page: got %s, links %v", recorder.Body, links)
+	}
+}
+
+func TestGetBooksOffsetModeStaysAnArray(t *testing.T) {
+	router := newPageRouter(t, 3)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/books?limit=2", nil))
+	var books []models.Book
+	if err := json.Unmarshal(recorder.Body.Bytes(), &books); err != nil || len(books) != 2 || recorder.Header().Get("Link") != "" {
+		t.Errorf("got %s with Link %q, want a bare array of 2", recorder.Body, recorder.Header().Get("Link"))
+	}
+}
+
+func TestGetBooksInvalidCursor(t *testing.T) {
+	router := newPageRouter(t, 3)
+	recorder, _ := getPage(t, router, "/books?cursor=garbage")
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("got %d %s, want 400", recorder.Code, recorder.Body)
+	}
+}
@@ -0,0 +1,626 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shani34/book-management-system/internal/middleware"
+	"github.com/shani34/book-management-system/internal/models"
+	"github.com/shani34/book-management-system/internal/services"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type CirculationHandler struct {
+	service *services.CirculationService
+	logger  *zap.Logger
+}
+
+func NewCirculationHandler(service *services.CirculationService, logger *zap.Logger) *CirculationHandler {
+	return &CirculationHandler{
+		service: service,
+		logger:  logger.Named("handlers.CirculationHandler"),
+	}
+}
+
+// circulation returns the circulation service for the request's tenant.
+func (h *CirculationHandler) circulation(c *gin.Context) *services.CirculationService {
+	return h.service.ForTenant(middleware.Tenant(c))
+}
+
+// GetCopies godoc
+// @Summary List copies
+// @Description Get the physical copies of a book
+// @Tags circulation
+// @Accept json
+// @Produce json
+// @Param id path int true "Book ID"
+// @Success 200 {array} models.Copy
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /books/{id}/copies [get]
+func (h *CirculationHandler) GetCopies(c *gin.Context) {
+	bookID, ok := h.pathID(c, "id", "book")
+	if !ok {
+		return
+	}
+
+	copies, err := h.circulation(c).GetCopies(bookID)
+	if err != nil {
+		h.writeError(c, "book", err)
+		return
+	}
+
+	h.logger.Info("Successfully retrieved copies",
+		zap.Uint("book_id", bookID),
+		zap.Int("count", len(copies)),
+	)
+	c.JSON(http.StatusOK, copies)
+}
+
+// CreateCopy godoc
+// @Summary Create copy
+// @Description Add a physical copy of a book
+// @Tags circulation
+// @Accept json
+// @Produce json
+// @Param id path int true "Book ID"
+// @Param copy body models.CopyRequest true "Copy data"
+// @Success 201 {object} models.Copy
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /books/{id}/copies [post]
+func (h *CirculationHandler) CreateCopy(c *gin.Context) {
+	bookID, ok := h.pathID(c, "id", "book")
+	if !ok {
+		return
+	}
+
+	var bookCopy models.Copy
+	if err := c.ShouldBindJSON(&bookCopy); err != nil {
+		h.logger.Warn("Invalid request body", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	if err := h.circulation(c).CreateCopy(bookID, &bookCopy); err != nil {
+		h.writeError(c, "book", err)
+		return
+	}
+
+	h.logger.Info("Copy created successfully",
+		zap.Uint("book_id", bookID),
+		zap.Uint("copy_id", bookCopy.ID),
+	)
+	c.JSON(http.StatusCreated, bookCopy)
+}
+
+// GetCopy godoc
+// @Summary Get a copy
+// @Description Get a physical copy by ID
+// @Tags circulation
+// @Accept json
+// @Produce json
+// @Param id path int true "Copy ID"
+// @Success 200 {object} models.Copy
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /copies/{id} [get]
+func (h *CirculationHandler) GetCopy(c *gin.Context) {
+	id, ok := h.pathID(c, "id", "copy")
+	if !ok {
+		return
+	}
+
+	bookCopy, err := h.circulation(c).GetCopy(id)
+	if err != nil {
+		h.writeError(c, "copy", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, bookCopy)
+}
+
+// UpdateCopy godoc
+// @Summary Update copy
+// @Description Change a copy's barcode, location or shelf status. A copy on loan keeps its status until it is returned.
+// @Tags circulation
+// @Accept json
+// @Produce json
+// @Param id path int true "Copy ID"
+// @Param copy body models.CopyRequest true "Copy data"
+// @Success 200 {object} models.Copy
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /copies/{id} [put]
+func (h *CirculationHandler) UpdateCopy(c *gin.Context) {
+	id, ok := h.pathID(c, "id", "copy")
+	if !ok {
+		return
+	}
+
+	var bookCopy models.Copy
+	if err := c.ShouldBindJSON(&bookCopy); err != nil {
+		h.logger.Warn("Invalid request body",
+			zap.Uint("copy_id", id),
+			zap.Error(err),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	if err := h.circulation(c).UpdateCopy(id, &bookCopy); err != nil {
+		h.writeError(c, "copy", err)
+		return
+	}
+
+	h.logger.Info("Copy updated successfully", zap.Uint("copy_id", id))
+	c.JSON(http.StatusOK, bookCopy)
+}
+
+// DeleteCopy godoc
+// @Summary Delete copy
+// @Description Delete a copy that has never been lent out
+// @Tags circulation
+// @Accept json
+// @Produce json
+// @Param id path int true "Copy ID"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /copies/{id} [delete]
+func (h *CirculationHandler) DeleteCopy(c *gin.Context) {
+	id, ok := h.pathID(c, "id", "copy")
+	if !ok {
+		return
+	}
+
+	if err := h.circulation(c).DeleteCopy(id); err != nil {
+		h.writeError(c, "copy", err)
+		return
+	}
+
+	h.logger.Info("Copy deleted successfully", zap.Uint("copy_id", id))
+	c.Status(http.StatusNoContent)
+}
+
+// GetLoans godoc
+// @Summary List loans
+// @Description Get loans, most recent checkout first
+// @Tags circulation
+// @Accept json
+// @Produce json
+// @Param book_id query int false "Only loans of this book"
+// @Param copy_id query int false "Only loans of this copy"
+// @Param member_id query int false "Only loans to this member"
+// @Param active query bool false "Only loans not yet returned"
+// @Param limit query int false "Limit"
+// @Param offset query int false "Offset"
+// @Success 200 {array} models.Loan
+// @Failure 400 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /loans [get]
+func (h *CirculationHandler) GetLoans(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	var filter models.LoanFilter
+	for param, target := range map[string]*uint{
+		"book_id":   &filter.BookID,
+		"copy_id":   &filter.CopyID,
+		"member_id": &filter.MemberID,
+	} {
+		raw := c.Query(param)
+		if raw == "" {
+			continue
+		}
+		id, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + param})
+			return
+		}
+		*target = uint(id)
+	}
+	if raw := c.Query("active"); raw != "" {
+		active, err := strconv.ParseBool(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid active"})
+			return
+		}
+		filter.ActiveOnly = active
+	}
+
+	loans, err := h.circulation(c).GetLoans(filter, limit, offset)
+	if err != nil {
+		h.writeError(c, "loan", err)
+		return
+	}
+
+	h.logger.Info("Successfully retrieved loans", zap.Int("count", len(loans)))
+	c.JSON(http.StatusOK, loans)
+}
+
+// GetLoan godoc
+// @Summary Get a loan
+// @Description Get a loan by ID
+// @Tags circulation
+// @Accept json
+// @Produce json
+// @Param id path int true "Loan ID"
+// @Success 200 {object} models.Loan
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /loans/{id} [get]
+func (h *CirculationHandler) GetLoan(c *gin.Context) {
+	id, ok := h.pathID(c, "id", "loan")
+	if !ok {
+		return
+	}
+
+	loan, err := h.circulation(c).GetLoan(id)
+	if err != nil {
+		h.writeError(c, "loan", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, loan)
+}
+
+// Checkout godoc
+// @Summary Check out a copy
+// @Description Lend a copy, identified by copy_id or barcode, to a member. A copy can only be on one loan at a time, and blocked or expired members, members at their tier's loan limit and members owing more in fines than their tier allows are refused. due_at defaults to the end of the tier's loan period and can only bring it forward.
+// @Tags circulation
+// @Accept json
+// @Produce json
+// @Param checkout body models.CheckoutRequest true "Checkout data"
+// @Success 201 {object} models.Loan
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /loans [post]
+func (h *CirculationHandler) Checkout(c *gin.Context) {
+	var request models.CheckoutRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		h.logger.Warn("Invalid request body", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	loan, err := h.circulation(c).Checkout(request)
+	if err != nil {
+		h.writeError(c, "copy", err)
+		return
+	}
+
+	h.logger.Info("Copy checked out",
+		zap.Uint("loan_id", loan.ID),
+		zap.Uint("copy_id", loan.CopyID),
+		zap.Uint("member_id", loan.MemberID),
+		zap.Time("due_at", loan.DueAt),
+	)
+	c.JSON(http.StatusCreated, loan)
+}
+
+// ReturnLoan godoc
+// @Summary Return a loan
+// @Description Close an active loan, assess a fine if it is late, and put the copy back on the shelf
+// @Tags circulation
+// @Accept json
+// @Produce json
+// @Param id path int true "Loan ID"
+// @Success 200 {object} models.Loan
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /loans/{id}/return [post]
+func (h *CirculationHandler) ReturnLoan(c *gin.Context) {
+	id, ok := h.pathID(c, "id", "loan")
+	if !ok {
+		return
+	}
+
+	loan, err := h.circulation(c).Return(id)
+	if err != nil {
+		h.writeError(c, "loan", err)
+		return
+	}
+
+	h.logger.Info("Loan returned",
+		zap.Uint("loan_id", loan.ID),
+		zap.Uint("copy_id", loan.CopyID),
+	)
+	c.JSON(http.StatusOK, loan)
+}
+
+// GetBookHolds godoc
+// @Summary List a book's holds
+// @Description Get the holds on a book in queue order. Waiting holds carry their position in the queue.
+// @Tags circulation
+// @Accept json
+// @Produce json
+// @Param id path int true "Book ID"
+// @Param status query string false "Only holds with this status" Enums(waiting, ready, fulfilled, cancelled, expired)
+// @Param limit query int false "Limit"
+// @Param offset query int false "Offset"
+// @Success 200 {array} models.Hold
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /books/{id}/holds [get]
+func (h *CirculationHandler) GetBookHolds(c *gin.Context) {
+	bookID, ok := h.pathID(c, "id", "book")
+	if !ok {
+		return
+	}
+	filter, ok := h.holdFilter(c)
+	if !ok {
+		return
+	}
+	filter.BookID = bookID
+	h.listHolds(c, filter, "book")
+}
+
+// PlaceHold godoc
+// @Summary Place a hold
+// @Description Join the queue for a book none of whose copies is available and that the member doesn't already have on loan. When a copy comes back it is set aside for the first member in the queue, who has a limited time to collect it.
+// @Tags circulation
+// @Accept json
+// @Produce json
+// @Param id path int true "Book ID"
+// @Param hold body models.HoldRequest true "Hold data"
+// @Success 201 {object} models.Hold
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /books/{id}/holds [post]
+func (h *CirculationHandler) PlaceHold(c *gin.Context) {
+	bookID, ok := h.pathID(c, "id", "book")
+	if !ok {
+		return
+	}
+
+	var request models.HoldRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		h.logger.Warn("Invalid request body", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	hold, err := h.circulation(c).PlaceHold(bookID, request.MemberID)
+	if err != nil {
+		h.writeError(c, "book", err)
+		return
+	}
+
+	h.logger.Info("Hold placed",
+		zap.Uint("hold_id", hold.ID),
+		zap.Uint("book_id", bookID),
+		zap.Uint("member_id", hold.MemberID),
+		zap.Int("position", hold.Position),
+	)
+	c.JSON(http.StatusCreated, hold)
+}
+
+// GetHolds godoc
+// @Summary List holds
+// @Description Get holds in queue order. Waiting holds carry their position in their book's queue.
+// @Tags circulation
+// @Accept json
+// @Produce json
+// @Param book_id query int false "Only holds on this book"
+// @Param member_id query int false "Only holds of this member"
+// @Param status query string false "Only holds with this status" Enums(waiting, ready, fulfilled, cancelled, expired)
+// @Param limit query int false "Limit"
+// @Param offset query int false "Offset"
+// @Success 200 {array} models.Hold
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /holds [get]
+func (h *CirculationHandler) GetHolds(c *gin.Context) {
+	filter, ok := h.holdFilter(c)
+	if !ok {
+		return
+	}
+	for param, target := range map[string]*uint{
+		"book_id":   &filter.BookID,
+		"member_id": &filter.MemberID,
+	} {
+		raw := c.Query(param)
+		if raw == "" {
+			continue
+		}
+		id, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + param})
+			return
+		}
+		*target = uint(id)
+	}
+	h.listHolds(c, filter, "book")
+}
+
+// GetHold godoc
+// @Summary Get a hold
+// @Description Get a hold by ID, with its position in the queue while it is waiting
+// @Tags circulation
+// @Accept json
+// @Produce json
+// @Param id path int true "Hold ID"
+// @Success 200 {object} models.Hold
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /holds/{id} [get]
+func (h *CirculationHandler) GetHold(c *gin.Context) {
+	id, ok := h.pathID(c, "id", "hold")
+	if !ok {
+		return
+	}
+
+	hold, err := h.circulation(c).GetHold(id)
+	if err != nil {
+		h.writeError(c, "hold", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, hold)
+}
+
+// CancelHold godoc
+// @Summary Cancel a hold
+// @Description Leave the queue. A copy already set aside for the hold passes to the next member.
+// @Tags circulation
+// @Accept json
+// @Produce json
+// @Param id path int true "Hold ID"
+// @Success 200 {object} models.Hold
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /holds/{id}/cancel [post]
+func (h *CirculationHandler) CancelHold(c *gin.Context) {
+	id, ok := h.pathID(c, "id", "hold")
+	if !ok {
+		return
+	}
+
+	hold, err := h.circulation(c).CancelHold(id)
+	if err != nil {
+		h.writeError(c, "hold", err)
+		return
+	}
+
+	h.logger.Info("Hold cancelled", zap.Uint("hold_id", id))
+	c.JSON(http.StatusOK, hold)
+}
+
+// holdFilter reads the status filter shared by the hold listings.
+func (h *CirculationHandler) holdFilter(c *gin.Context) (models.HoldFilter, bool) {
+	filter := models.HoldFilter{Status: c.Query("status")}
+	switch filter.Status {
+	case "", models.HoldWaiting, models.HoldReady, models.HoldFulfilled, models.HoldCancelled, models.HoldExpired:
+		return filter, true
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid status"})
+		return filter, false
+	}
+}
+
+func (h *CirculationHandler) listHolds(c *gin.Context, filter models.HoldFilter, resource string) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	holds, err := h.circulation(c).GetHolds(filter, limit, offset)
+	if err != nil {
+		h.writeError(c, resource, err)
+		return
+	}
+
+	h.logger.Info("Successfully retrieved holds", zap.Int("count", len(holds)))
+	c.JSON(http.StatusOK, holds)
+}
+
+func (h *CirculationHandler) pathID(c *gin.Context, param, resource string) (uint, bool) {
+	id, err := strconv.Atoi(c.Param(param))
+	if err != nil || id < 0 {
+		h.logger.Warn("Invalid "+resource+" ID format",
+			zap.String("received_id", c.Param(param)),
+			zap.Error(err),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + resource + " ID format"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// writeError maps service errors onto responses. resource names what a
+// gorm.ErrRecordNotFound refers to.
+func (h *CirculationHandler) writeError(c *gin.Context, resource string, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		h.logger.Warn("Not found", zap.String("resource", resource), zap.String("path", c.Request.URL.Path))
+		c.JSON(http.StatusNotFound, gin.H{"error": resource + " not found"})
+	case errors.Is(err, services.ErrInvalidCopy),
+		errors.Is(err, services.ErrInvalidLoan),
+		errors.Is(err, services.ErrInvalidHold):
+		h.logger.Warn("Request failed validation", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, models.ErrDuplicateBarcode),
+		errors.Is(err, models.ErrCopyUnavailable),
+		errors.Is(err, models.ErrCopyOnLoan),
+		errors.Is(err, models.ErrCopyOnHold),
+		errors.Is(err, models.ErrCopyHasLoans),
+		errors.Is(err, models.ErrLoanReturned),
+		errors.Is(err, models.ErrMemberBlocked),
+		errors.Is(err, models.ErrMembershipExpired),
+		errors.Is(err, models.ErrLoanLimitReached),
+		errors.Is(err, models.ErrFinesOutstanding),
+		errors.Is(err, models.ErrDuplicateHold),
+		errors.Is(err, models.ErrAlreadyBorrowing),
+		errors.Is(err, models.ErrCopiesAvailable),
+		errors.Is(err, models.ErrHoldClosed):
+		h.logger.Warn("Circulation conflict", zap.String("path", c.Request.URL.Path), zap.Error(err))
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		h.logger.Error("Circulation request failed", zap.String("path", c.Request.URL.Path), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
@@ -0,0 +1,275 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shani34/book-management-system/internal/middleware"
+	"github.com/shani34/book-management-system/internal/models"
+	"github.com/shani34/book-management-system/internal/services"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type EditionHandler struct {
+	service *services.EditionService
+	logger  *zap.Logger
+}
+
+func NewEditionHandler(service *services.EditionService, logger *zap.Logger) *EditionHandler {
+	return &EditionHandler{
+		service: service,
+		logger:  logger.Named("handlers.EditionHandler"),
+	}
+}
+
+// editions returns the edition service for the request's tenant.
+func (h *EditionHandler) editions(c *gin.Context) *services.EditionService {
+	return h.service.ForTenant(middleware.Tenant(c))
+}
+
+// GetEditions godoc
+// @Summary List editions
+// @Description Get every edition of a book
+// @Tags editions
+// @Accept json
+// @Produce json
+// @Param id path int true "Book ID"
+// @Success 200 {array} models.Edition
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /books/{id}/editions [get]
+func (h *EditionHandler) GetEditions(c *gin.Context) {
+	bookID, ok := h.pathID(c, "id", "book")
+	if !ok {
+		return
+	}
+
+	editions, err := h.editions(c).GetEditions(bookID)
+	if err != nil {
+		h.writeError(c, "Failed to fetch editions", bookID, 0, err)
+		return
+	}
+
+	h.logger.Info("Successfully retrieved editions",
+		zap.Uint("book_id", bookID),
+		zap.Int("count", len(editions)),
+	)
+	c.JSON(http.StatusOK, editions)
+}
+
+// GetEdition godoc
+// @Summary Get an edition
+// @Description Get one edition of a book
+// @Tags editions
+// @Accept json
+// @Produce json
+// @Param id path int true "Book ID"
+// @Param edition_id path int true "Edition ID"
+// @Success 200 {object} models.Edition
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /books/{id}/editions/{edition_id} [get]
+func (h *EditionHandler) GetEdition(c *gin.Context) {
+	bookID, ok := h.pathID(c, "id", "book")
+	if !ok {
+		return
+	}
+	id, ok := h.pathID(c, "edition_id", "edition")
+	if !ok {
+		return
+	}
+
+	edition, err := h.editions(c).GetEdition(bookID, id)
+	if err != nil {
+		h.writeError(c, "Failed to fetch edition", bookID, id, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, edition)
+}
+
+// CreateEdition godoc
+// @Summary Create edition
+// @Description Add an edition to a book. Bumps the book's version and emits book_updated.
+// @Tags editions
+// @Accept json
+// @Produce json
+// @Param id path int true "Book ID"
+// @Param edition body models.EditionRequest true "Edition data"
+// @Success 201 {object} models.Edition
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /books/{id}/editions [post]
+func (h *EditionHandler) CreateEdition(c *gin.Context) {
+	bookID, ok := h.pathID(c, "id", "book")
+	if !ok {
+		return
+	}
+
+	var edition models.Edition
+	if err := c.ShouldBindJSON(&edition); err != nil {
+		h.logger.Warn("Invalid request body",
+			zap.Uint("book_id", bookID),
+			zap.Error(err),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	if err := h.editions(c).CreateEdition(bookID, &edition); err != nil {
+		h.writeError(c, "Failed to create edition", bookID, 0, err)
+		return
+	}
+
+	h.logger.Info("Edition created successfully",
+		zap.Uint("book_id", bookID),
+		zap.Uint("edition_id", edition.ID),
+	)
+	c.JSON(http.StatusCreated, edition)
+}
+
+// UpdateEdition godoc
+// @Summary Update edition
+// @Description Replace an edition of a book. Bumps the book's version and emits book_updated.
+// @Tags editions
+// @Accept json
+// @Produce json
+// @Param id path int true "Book ID"
+// @Param edition_id path int true "Edition ID"
+// @Param edition body models.EditionRequest true "Edition data"
+// @Success 200 {object} models.Edition
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /books/{id}/editions/{edition_id} [put]
+func (h *EditionHandler) UpdateEdition(c *gin.Context) {
+	bookID, ok := h.pathID(c, "id", "book")
+	if !ok {
+		return
+	}
+	id, ok := h.pathID(c, "edition_id", "edition")
+	if !ok {
+		return
+	}
+
+	var edition models.Edition
+	if err := c.ShouldBindJSON(&edition); err != nil {
+		h.logger.Warn("Invalid request body",
+			zap.Uint("book_id", bookID),
+			zap.Uint("edition_id", id),
+			zap.Error(err),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	if err := h.editions(c).UpdateEdition(bookID, id, &edition); err != nil {
+		h.writeError(c, "Failed to update edition", bookID, id, err)
+		return
+	}
+
+	h.logger.Info("Edition updated successfully",
+		zap.Uint("book_id", bookID),
+		zap.Uint("edition_id", id),
+	)
+	c.JSON(http.StatusOK, edition)
+}
+
+// DeleteEdition godoc
+// @Summary Delete edition
+// @Description Delete an edition of a book. Bumps the book's version and emits book_updated.
+// @Tags editions
+// @Accept json
+// @Produce json
+// @Param id path int true "Book ID"
+// @Param edition_id path int true "Edition ID"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /books/{id}/editions/{edition_id} [delete]
+func (h *EditionHandler) DeleteEdition(c *gin.Context) {
+	bookID, ok := h.pathID(c, "id", "book")
+	if !ok {
+		return
+	}
+	id, ok := h.pathID(c, "edition_id", "edition")
+	if !ok {
+		return
+	}
+
+	if err := h.editions(c).DeleteEdition(bookID, id); err != nil {
+		h.writeError(c, "Failed to delete edition", bookID, id, err)
+		return
+	}
+
+	h.logger.Info("Edition deleted successfully",
+		zap.Uint("book_id", bookID),
+		zap.Uint("edition_id", id),
+	)
+	c.Status(http.StatusNoContent)
+}
+
+func (h *EditionHandler) pathID(c *gin.Context, param, resource string) (uint, bool) {
+	id, err := strconv.Atoi(c.Param(param))
+	if err != nil || id < 0 {
+		h.logger.Warn("Invalid "+resource+" ID format",
+			zap.String("received_id", c.Param(param)),
+			zap.Error(err),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + resource + " ID format"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// writeError maps service errors onto responses. A missing book and a
+// missing edition are both reported as 404.
+func (h *EditionHandler) writeError(c *gin.Context, msg string, bookID, id uint, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		h.logger.Warn("Book or edition not found",
+			zap.Uint("book_id", bookID),
+			zap.Uint("edition_id", id),
+		)
+		c.JSON(http.StatusNotFound, gin.H{"error": "book or edition not found"})
+	case errors.Is(err, services.ErrInvalidEdition):
+		h.logger.Warn("Edition failed validation", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, models.ErrDuplicateISBN):
+		h.logger.Warn("Duplicate edition ISBN", zap.Uint("book_id", bookID))
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		h.logger.Error(msg,
+			zap.Uint("book_id", bookID),
+			zap.Uint("edition_id", id),
+			zap.Error(err),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
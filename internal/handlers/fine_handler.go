@@ -0,0 +1,290 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shani34/book-management-system/internal/middleware"
+	"github.com/shani34/book-management-system/internal/models"
+	"github.com/shani34/book-management-system/internal/services"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type FineHandler struct {
+	service *services.FineService
+	logger  *zap.Logger
+}
+
+func NewFineHandler(service *services.FineService, logger *zap.Logger) *FineHandler {
+	return &FineHandler{
+		service: service,
+		logger:  logger.Named("handlers.FineHandler"),
+	}
+}
+
+// fines returns the fine service for the request's tenant.
+func (h *FineHandler) fines(c *gin.Context) *services.FineService {
+	return h.service.ForTenant(middleware.Tenant(c))
+}
+
+// GetMemberFines godoc
+// @Summary List a member's fines
+// @Description Get the fines assessed on a member's late returns, most recent first. Amounts are in minor currency units.
+// @Tags fines
+// @Accept json
+// @Produce json
+// @Param id path int true "Member ID"
+// @Param open query bool false "Only fines with a balance left"
+// @Param limit query int false "Limit"
+// @Param offset query int false "Offset"
+// @Success 200 {array} models.Fine
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /members/{id}/fines [get]
+func (h *FineHandler) GetMemberFines(c *gin.Context) {
+	memberID, ok := h.pathID(c, "member")
+	if !ok {
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	openOnly := false
+	if raw := c.Query("open"); raw != "" {
+		var err error
+		if openOnly, err = strconv.ParseBool(raw); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid open"})
+			return
+		}
+	}
+
+	fines, err := h.fines(c).GetMemberFines(memberID, openOnly, limit, offset)
+	if err != nil {
+		h.writeError(c, "member", err)
+		return
+	}
+
+	h.logger.Info("Successfully retrieved fines",
+		zap.Uint("member_id", memberID),
+		zap.Int("count", len(fines)),
+	)
+	c.JSON(http.StatusOK, fines)
+}
+
+// GetFineSummary godoc
+// @Summary Get a member's fine balance
+// @Description Get what a member owes: assessed fines still outstanding, plus fines accruing on overdue loans. A total above the tier's threshold blocks checkouts.
+// @Tags fines
+// @Accept json
+// @Produce json
+// @Param id path int true "Member ID"
+// @Success 200 {object} models.FineSummary
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /members/{id}/fines/summary [get]
+func (h *FineHandler) GetFineSummary(c *gin.Context) {
+	memberID, ok := h.pathID(c, "member")
+	if !ok {
+		return
+	}
+
+	summary, err := h.fines(c).GetFineSummary(memberID)
+	if err != nil {
+		h.writeError(c, "member", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
+// GetLedger godoc
+// @Summary Get a member's fine ledger
+// @Description Get every assessment, payment and waiver on a member's fines, oldest first
+// @Tags fines
+// @Accept json
+// @Produce json
+// @Param id path int true "Member ID"
+// @Param limit query int false "Limit"
+// @Param offset query int false "Offset"
+// @Success 200 {array} models.LedgerEntry
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /members/{id}/ledger [get]
+func (h *FineHandler) GetLedger(c *gin.Context) {
+	memberID, ok := h.pathID(c, "member")
+	if !ok {
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	entries, err := h.fines(c).GetLedger(memberID, limit, offset)
+	if err != nil {
+		h.writeError(c, "member", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+// GetFine godoc
+// @Summary Get a fine
+// @Description Get a fine by ID
+// @Tags fines
+// @Accept json
+// @Produce json
+// @Param id path int true "Fine ID"
+// @Success 200 {object} models.Fine
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /fines/{id} [get]
+func (h *FineHandler) GetFine(c *gin.Context) {
+	id, ok := h.pathID(c, "fine")
+	if !ok {
+		return
+	}
+
+	fine, err := h.fines(c).GetFine(id)
+	if err != nil {
+		h.writeError(c, "fine", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, fine)
+}
+
+// PayFine godoc
+// @Summary Pay a fine
+// @Description Record a payment towards a fine, in minor currency units. Partial payments are allowed, up to the balance.
+// @Tags fines
+// @Accept json
+// @Produce json
+// @Param id path int true "Fine ID"
+// @Param payment body models.FinePaymentRequest true "Payment"
+// @Success 200 {object} models.Fine
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /fines/{id}/pay [post]
+func (h *FineHandler) PayFine(c *gin.Context) {
+	id, ok := h.pathID(c, "fine")
+	if !ok {
+		return
+	}
+
+	var request models.FinePaymentRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		h.logger.Warn("Invalid request body", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	fine, err := h.fines(c).PayFine(id, request.Amount, request.Note)
+	if err != nil {
+		h.writeError(c, "fine", err)
+		return
+	}
+
+	h.logger.Info("Fine payment recorded",
+		zap.Uint("fine_id", id),
+		zap.Int64("amount", request.Amount),
+		zap.Int64("balance", fine.Balance),
+	)
+	c.JSON(http.StatusOK, fine)
+}
+
+// WaiveFine godoc
+// @Summary Waive a fine
+// @Description Write off part of a fine, in minor currency units, or all of what is left when amount is omitted
+// @Tags fines
+// @Accept json
+// @Produce json
+// @Param id path int true "Fine ID"
+// @Param waiver body models.FineWaiverRequest false "Waiver"
+// @Success 200 {object} models.Fine
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /fines/{id}/waive [post]
+func (h *FineHandler) WaiveFine(c *gin.Context) {
+	id, ok := h.pathID(c, "fine")
+	if !ok {
+		return
+	}
+
+	var request models.FineWaiverRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			h.logger.Warn("Invalid request body", zap.Error(err))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+			return
+		}
+	}
+
+	fine, err := h.fines(c).WaiveFine(id, request.Amount, request.Note)
+	if err != nil {
+		h.writeError(c, "fine", err)
+		return
+	}
+
+	h.logger.Info("Fine waived",
+		zap.Uint("fine_id", id),
+		zap.Int64("waived", fine.Waived),
+		zap.Int64("balance", fine.Balance),
+	)
+	c.JSON(http.StatusOK, fine)
+}
+
+func (h *FineHandler) pathID(c *gin.Context, resource string) (uint, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id < 0 {
+		h.logger.Warn("Invalid "+resource+" ID format",
+			zap.String("received_id", c.Param("id")),
+			zap.Error(err),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + resource + " ID format"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// writeError maps service errors onto responses. resource names what a
+// gorm.ErrRecordNotFound refers to.
+func (h *FineHandler) writeError(c *gin.Context, resource string, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		h.logger.Warn("Not found", zap.String("resource", resource), zap.String("path", c.Request.URL.Path))
+		c.JSON(http.StatusNotFound, gin.H{"error": resource + " not found"})
+	case errors.Is(err, services.ErrInvalidAmount):
+		h.logger.Warn("Request failed validation", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, models.ErrFineSettled):
+		h.logger.Warn("Fine conflict", zap.String("path", c.Request.URL.Path), zap.Error(err))
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		h.logger.Error("Fine request failed", zap.String("path", c.Request.URL.Path), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
@@ -0,0 +1,357 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shani34/book-management-system/internal/middleware"
+	"github.com/shani34/book-management-system/internal/models"
+	"github.com/shani34/book-management-system/internal/services"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type MemberHandler struct {
+	service *services.MemberService
+	logger  *zap.Logger
+}
+
+func NewMemberHandler(service *services.MemberService, logger *zap.Logger) *MemberHandler {
+	return &MemberHandler{
+		service: service,
+		logger:  logger.Named("handlers.MemberHandler"),
+	}
+}
+
+// members returns the member service for the request's tenant.
+func (h *MemberHandler) members(c *gin.Context) *services.MemberService {
+	return h.service.ForTenant(middleware.Tenant(c))
+}
+
+// GetMembers godoc
+// @Summary List members
+// @Description Get paginated list of members ordered by name
+// @Tags members
+// @Accept json
+// @Produce json
+// @Param limit query int false "Limit"
+// @Param offset query int false "Offset"
+// @Success 200 {array} models.Member
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /members [get]
+func (h *MemberHandler) GetMembers(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	members, err := h.members(c).GetAllMembers(limit, offset)
+	if err != nil {
+		h.writeError(c, "member", err)
+		return
+	}
+
+	h.logger.Info("Successfully retrieved members", zap.Int("count", len(members)))
+	c.JSON(http.StatusOK, members)
+}
+
+// GetMember godoc
+// @Summary Get a member
+// @Description Get member by ID, with their tier
+// @Tags members
+// @Accept json
+// @Produce json
+// @Param id path int true "Member ID"
+// @Success 200 {object} models.Member
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /members/{id} [get]
+func (h *MemberHandler) GetMember(c *gin.Context) {
+	id, ok := h.pathID(c, "member")
+	if !ok {
+		return
+	}
+
+	member, err := h.members(c).GetMemberByID(id)
+	if err != nil {
+		h.writeError(c, "member", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, member)
+}
+
+// CreateMember godoc
+// @Summary Create member
+// @Description Register a new member in a tier
+// @Tags members
+// @Accept json
+// @Produce json
+// @Param member body models.MemberRequest true "Member data"
+// @Success 201 {object} models.Member
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /members [post]
+func (h *MemberHandler) CreateMember(c *gin.Context) {
+	var member models.Member
+	if err := c.ShouldBindJSON(&member); err != nil {
+		h.logger.Warn("Invalid request body", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	if err := h.members(c).CreateMember(&member); err != nil {
+		h.writeError(c, "member", err)
+		return
+	}
+
+	h.logger.Info("Member created successfully", zap.Uint("member_id", member.ID))
+	c.JSON(http.StatusCreated, member)
+}
+
+// UpdateMember godoc
+// @Summary Update member
+// @Description Replace a member's details. Set blocked to stop them borrowing, or expires_on to end the membership.
+// @Tags members
+// @Accept json
+// @Produce json
+// @Param id path int true "Member ID"
+// @Param member body models.MemberRequest true "Member data"
+// @Success 200 {object} models.Member
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /members/{id} [put]
+func (h *MemberHandler) UpdateMember(c *gin.Context) {
+	id, ok := h.pathID(c, "member")
+	if !ok {
+		return
+	}
+
+	var member models.Member
+	if err := c.ShouldBindJSON(&member); err != nil {
+		h.logger.Warn("Invalid request body",
+			zap.Uint("member_id", id),
+			zap.Error(err),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	if err := h.members(c).UpdateMember(id, &member); err != nil {
+		h.writeError(c, "member", err)
+		return
+	}
+
+	h.logger.Info("Member updated successfully", zap.Uint("member_id", id))
+	c.JSON(http.StatusOK, member)
+}
+
+// DeleteMember godoc
+// @Summary Delete member
+// @Description Delete a member who has never borrowed anything and has no active holds
+// @Tags members
+// @Accept json
+// @Produce json
+// @Param id path int true "Member ID"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /members/{id} [delete]
+func (h *MemberHandler) DeleteMember(c *gin.Context) {
+	id, ok := h.pathID(c, "member")
+	if !ok {
+		return
+	}
+
+	if err := h.members(c).DeleteMember(id); err != nil {
+		h.writeError(c, "member", err)
+		return
+	}
+
+	h.logger.Info("Member deleted successfully", zap.Uint("member_id", id))
+	c.Status(http.StatusNoContent)
+}
+
+// GetTiers godoc
+// @Summary List tiers
+// @Description Get the membership tiers
+// @Tags members
+// @Accept json
+// @Produce json
+// @Success 200 {array} models.Tier
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /tiers [get]
+func (h *MemberHandler) GetTiers(c *gin.Context) {
+	tiers, err := h.members(c).GetTiers()
+	if err != nil {
+		h.writeError(c, "tier", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tiers)
+}
+
+// GetTier godoc
+// @Summary Get a tier
+// @Description Get membership tier by ID
+// @Tags members
+// @Accept json
+// @Produce json
+// @Param id path int true "Tier ID"
+// @Success 200 {object} models.Tier
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /tiers/{id} [get]
+func (h *MemberHandler) GetTier(c *gin.Context) {
+	id, ok := h.pathID(c, "tier")
+	if !ok {
+		return
+	}
+
+	tier, err := h.members(c).GetTier(id)
+	if err != nil {
+		h.writeError(c, "tier", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tier)
+}
+
+// CreateTier godoc
+// @Summary Create tier
+// @Description Create a membership tier
+// @Tags members
+// @Accept json
+// @Produce json
+// @Param tier body models.TierRequest true "Tier data"
+// @Success 201 {object} models.Tier
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /tiers [post]
+func (h *MemberHandler) CreateTier(c *gin.Context) {
+	var tier models.Tier
+	if err := c.ShouldBindJSON(&tier); err != nil {
+		h.logger.Warn("Invalid request body", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	if err := h.members(c).CreateTier(&tier); err != nil {
+		h.writeError(c, "tier", err)
+		return
+	}
+
+	h.logger.Info("Tier created successfully", zap.Uint("tier_id", tier.ID))
+	c.JSON(http.StatusCreated, tier)
+}
+
+// UpdateTier godoc
+// @Summary Update tier
+// @Description Change a tier's name or limits. New limits apply from the next checkout.
+// @Tags members
+// @Accept json
+// @Produce json
+// @Param id path int true "Tier ID"
+// @Param tier body models.TierRequest true "Tier data"
+// @Success 200 {object} models.Tier
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /tiers/{id} [put]
+func (h *MemberHandler) UpdateTier(c *gin.Context) {
+	id, ok := h.pathID(c, "tier")
+	if !ok {
+		return
+	}
+
+	var tier models.Tier
+	if err := c.ShouldBindJSON(&tier); err != nil {
+		h.logger.Warn("Invalid request body",
+			zap.Uint("tier_id", id),
+			zap.Error(err),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	if err := h.members(c).UpdateTier(id, &tier); err != nil {
+		h.writeError(c, "tier", err)
+		return
+	}
+
+	h.logger.Info("Tier updated successfully", zap.Uint("tier_id", id))
+	c.JSON(http.StatusOK, tier)
+}
+
+func (h *MemberHandler) pathID(c *gin.Context, resource string) (uint, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id < 0 {
+		h.logger.Warn("Invalid "+resource+" ID format",
+			zap.String("received_id", c.Param("id")),
+			zap.Error(err),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + resource + " ID format"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// writeError maps service errors onto responses. resource names what a
+// gorm.ErrRecordNotFound refers to.
+func (h *MemberHandler) writeError(c *gin.Context, resource string, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		h.logger.Warn("Not found", zap.String("resource", resource), zap.String("path", c.Request.URL.Path))
+		c.JSON(http.StatusNotFound, gin.H{"error": resource + " not found"})
+	case errors.Is(err, services.ErrInvalidMember), errors.Is(err, services.ErrInvalidTier):
+		h.logger.Warn("Request failed validation", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, models.ErrDuplicateEmail),
+		errors.Is(err, models.ErrDuplicateTier),
+		errors.Is(err, models.ErrMemberHasLoans),
+		errors.Is(err, models.ErrMemberHasHolds):
+		h.logger.Warn("Member conflict", zap.String("path", c.Request.URL.Path), zap.Error(err))
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		h.logger.Error("Member request failed", zap.String("path", c.Request.URL.Path), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
@@ -0,0 +1,240 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shani34/book-management-system/internal/middleware"
+	"github.com/shani34/book-management-system/internal/models"
+	"github.com/shani34/book-management-system/internal/services"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type TagHandler struct {
+	service *services.TagService
+	logger  *zap.Logger
+}
+
+func NewTagHandler(service *services.TagService, logger *zap.Logger) *TagHandler {
+	return &TagHandler{
+		service: service,
+		logger:  logger.Named("handlers.TagHandler"),
+	}
+}
+
+// tags returns the tag service for the request's tenant.
+func (h *TagHandler) tags(c *gin.Context) *services.TagService {
+	return h.service.ForTenant(middleware.Tenant(c))
+}
+
+// GetTags godoc
+// @Summary List tags
+// @Description Get paginated list of tags ordered by name
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param limit query int false "Limit"
+// @Param offset query int false "Offset"
+// @Success 200 {array} models.Tag
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /tags [get]
+func (h *TagHandler) GetTags(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	h.logger.Info("Starting GetTags request",
+		zap.Int("limit", limit),
+		zap.Int("offset", offset),
+	)
+
+	tags, err := h.tags(c).GetAllTags(limit, offset)
+	if err != nil {
+		h.logger.Error("Failed to fetch tags", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	h.logger.Info("Successfully retrieved tags", zap.Int("count", len(tags)))
+	c.JSON(http.StatusOK, tags)
+}
+
+// GetTag godoc
+// @Summary Get a tag
+// @Description Get tag by ID
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param id path int true "Tag ID"
+// @Success 200 {object} models.Tag
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /tags/{id} [get]
+func (h *TagHandler) GetTag(c *gin.Context) {
+	id, ok := h.tagID(c)
+	if !ok {
+		return
+	}
+
+	tag, err := h.tags(c).GetTagByID(id)
+	if err != nil {
+		h.writeError(c, "Failed to fetch tag", id, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tag)
+}
+
+// CreateTag godoc
+// @Summary Create tag
+// @Description Create new tag
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param tag body models.TagRequest true "Tag data"
+// @Success 201 {object} models.Tag
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /tags [post]
+func (h *TagHandler) CreateTag(c *gin.Context) {
+	var tag models.Tag
+	if err := c.ShouldBindJSON(&tag); err != nil {
+		h.logger.Warn("Invalid request body", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	if err := h.tags(c).CreateTag(&tag); err != nil {
+		h.writeError(c, "Failed to create tag", 0, err)
+		return
+	}
+
+	h.logger.Info("Tag created successfully", zap.Uint("tag_id", tag.ID))
+	c.JSON(http.StatusCreated, tag)
+}
+
+// UpdateTag godoc
+// @Summary Update tag
+// @Description Rename an existing tag. Every book carrying it gets a new version and a book_updated event.
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param id path int true "Tag ID"
+// @Param tag body models.TagRequest true "Tag data"
+// @Success 200 {object} models.Tag
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /tags/{id} [put]
+func (h *TagHandler) UpdateTag(c *gin.Context) {
+	id, ok := h.tagID(c)
+	if !ok {
+		return
+	}
+
+	var tag models.Tag
+	if err := c.ShouldBindJSON(&tag); err != nil {
+		h.logger.Warn("Invalid request body",
+			zap.Uint("tag_id", id),
+			zap.Error(err),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	if err := h.tags(c).UpdateTag(id, &tag); err != nil {
+		h.writeError(c, "Failed to update tag", id, err)
+		return
+	}
+
+	h.logger.Info("Tag updated successfully", zap.Uint("tag_id", id))
+	c.JSON(http.StatusOK, tag)
+}
+
+// DeleteTag godoc
+// @Summary Delete tag
+// @Description Delete a tag that no book, live or in the trash, carries
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param id path int true "Tag ID"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /tags/{id} [delete]
+func (h *TagHandler) DeleteTag(c *gin.Context) {
+	id, ok := h.tagID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.tags(c).DeleteTag(id); err != nil {
+		h.writeError(c, "Failed to delete tag", id, err)
+		return
+	}
+
+	h.logger.Info("Tag deleted successfully", zap.Uint("tag_id", id))
+	c.Status(http.StatusNoContent)
+}
+
+func (h *TagHandler) tagID(c *gin.Context) (uint, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id < 0 {
+		h.logger.Warn("Invalid tag ID format",
+			zap.String("received_id", c.Param("id")),
+			zap.Error(err),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid tag ID format"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// writeError maps service errors onto responses; anything unexpected is
+// logged with msg and reported as a 500.
+func (h *TagHandler) writeError(c *gin.Context, msg string, id uint, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		h.logger.Warn("Tag not found", zap.Uint("tag_id", id))
+		c.JSON(http.StatusNotFound, gin.H{"error": "tag not found"})
+	case errors.Is(err, services.ErrInvalidTag):
+		h.logger.Warn("Tag failed validation", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, models.ErrDuplicateTag), errors.Is(err, models.ErrTagInUse):
+		h.logger.Warn("Tag conflict",
+			zap.Uint("tag_id", id),
+			zap.Error(err),
+		)
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		h.logger.Error(msg,
+			zap.Uint("tag_id", id),
+			zap.Error(err),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
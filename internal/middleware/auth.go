@@ -0,0 +1,179 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shani34/book-management-system/internal/models"
+	"github.com/shani34/book-management-system/internal/policy"
+	"github.com/shani34/book-management-system/internal/services"
+	"github.com/shani34/book-management-system/pkg/jwt"
+	"go.uber.org/zap"
+)
+
+// Context keys set by Authenticate.
+const (
+	PrincipalKey = "auth.principal"
+	SubjectKey   = "auth.subject"
+	ClaimsKey    = "auth.claims"
+)
+
+// APIKeyHeader carries an API key, as an alternative to a bearer token.
+const APIKeyHeader = "X-API-Key"
+
+// defaultTokenScopes are granted to a bearer token without a scope claim
+// when there is no policy to derive them from its roles.
+var defaultTokenScopes = models.Scopes{models.ScopeBooksRead}
+
+// Principal is the authenticated caller. Claims is set for bearer tokens
+// and APIKey for API keys. Roles come from the token's "roles" or "role"
+// claim, or for API keys from their scopes.
+type Principal struct {
+	Subject string
+	Scopes  models.Scopes
+	Roles   []string
+	Claims  jwt.Claims
+	APIKey  *models.APIKey
+}
+
+// KeyAuthenticator looks up the API key sent in the X-API-Key header.
+type KeyAuthenticator interface {
+	Authenticate(raw string) (*models.APIKey, error)
+}
+
+// Authenticate identifies the caller from an X-API-Key header or an
+// Authorization bearer token and puts a Principal on the context. Requests
+// carrying neither continue anonymously; routes that need a caller say so
+// with RequireScope. A credential that fails to verify is refused with 401.
+// A token without a scope claim gets the scopes roles grants its roles.
+func Authenticate(verifier *jwt.Verifier, keys KeyAuthenticator, roles *policy.Policy, logger *zap.Logger) gin.HandlerFunc {
+	logger = logger.Named("middleware.Authenticate")
+	return func(c *gin.Context) {
+		if raw := c.GetHeader(APIKeyHeader); raw != "" {
+			key, err := keys.Authenticate(raw)
+			switch {
+			case err == nil:
+				setPrincipal(c, &Principal{
+					Subject: "apikey:" + key.Prefix,
+					Scopes:  key.Scopes,
+					Roles:   policy.RolesForScopes(key.Scopes),
+					APIKey:  key,
+				})
+			case errors.Is(err, services.ErrUnknownAPIKey),
+				errors.Is(err, models.ErrAPIKeyRevoked),
+				errors.Is(err, models.ErrAPIKeyExpired):
+				logger.Warn("Rejected API key",
+					zap.String("method", c.Request.Method),
+					zap.String("path", c.Request.URL.Path),
+					zap.Error(err),
+				)
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			default:
+				logger.Error("Failed to authenticate API key", zap.Error(err))
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			}
+			return
+		}
+
+		header := c.GetHeader("Authorization")
+		if header == "" {
+			return
+		}
+		scheme, token, _ := strings.Cut(header, " ")
+		if !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+			unauthorized(c, "authorization header must be a bearer token")
+			return
+		}
+		claims, err := verifier.Verify(strings.TrimSpace(token))
+		if err != nil {
+			logger.Warn("Rejected bearer token",
+				zap.String("method", c.Request.Method),
+				zap.String("path", c.Request.URL.Path),
+				zap.Error(err),
+			)
+			c.Header("WWW-Authenticate", `Bearer realm="api", error="invalid_token"`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid token: " + err.Error()})
+			return
+		}
+
+		tokenRoles := claims.Strings("roles")
+		if len(tokenRoles) == 0 {
+			tokenRoles = claims.Strings("role")
+		}
+		scopes := defaultTokenScopes
+		if scope, ok := claims["scope"].(string); ok {
+			scopes = strings.Fields(scope)
+		} else if roles != nil {
+			scopes = roles.Scopes(tokenRoles)
+		}
+		setPrincipal(c, &Principal{Subject: claims.Subject(), Scopes: scopes, Roles: tokenRoles, Claims: claims})
+	}
+}
+
+// RequireScope refuses anonymous requests with 401 and callers without
+// scope with 403.
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal := GetPrincipal(c)
+		if principal == nil {
+			unauthorized(c, "authentication required")
+			return
+		}
+		if !principal.Scopes.Has(scope) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "missing scope " + scope})
+		}
+	}
+}
+
+// RequireMethodScope applies RequireScope with read for GET, HEAD and
+// OPTIONS requests and with write for the rest. With publicReads set,
+// reads are let through without any check.
+func RequireMethodScope(read, write string, publicReads bool) gin.HandlerFunc {
+	requireRead, requireWrite := RequireScope(read), RequireScope(write)
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			if !publicReads {
+				requireRead(c)
+			}
+		default:
+			requireWrite(c)
+		}
+	}
+}
+
+// GetPrincipal returns the authenticated caller, or nil for anonymous
+// requests.
+func GetPrincipal(c *gin.Context) *Principal {
+	principal, _ := c.Get(PrincipalKey)
+	typed, _ := principal.(*Principal)
+	return typed
+}
+
+// Subject returns the authenticated subject, or "" for anonymous requests.
+func Subject(c *gin.Context) string {
+	return c.GetString(SubjectKey)
+}
+
+// Claims returns the verified token claims, or nil unless the caller used
+// a bearer token.
+func Claims(c *gin.Context) jwt.Claims {
+	claims, _ := c.Get(ClaimsKey)
+	typed, _ := claims.(jwt.Claims)
+	return typed
+}
+
+func setPrincipal(c *gin.Context, principal *Principal) {
+	c.Set(PrincipalKey, principal)
+	c.Set(SubjectKey, principal.Subject)
+	if principal.Claims != nil {
+		c.Set(ClaimsKey, principal.Claims)
+	}
+}
+
+func unauthorized(c *gin.Context, message string) {
+	c.Header("WWW-Authenticate", `Bearer realm="api"`)
+	c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": message})
+}
@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shani34/book-management-system/config"
+	"github.com/shani34/book-management-system/internal/models"
+	"github.com/shani34/book-management-system/internal/repositories"
+	"github.com/shani34/book-management-system/internal/services"
+	"go.uber.org/zap"
+)
+
+// keyRouter serves GET and POST / behind API key authentication, the
+// books scopes and tenant resolution, recording the resolved tenant.
+func keyRouter(keys KeyAuthenticator, tenant *string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Authenticate(nil, keys, nil, zap.NewNop()))
+	router.Use(RequireMethodScope(models.ScopeBooksRead, models.ScopeBooksWrite, false))
+	router.Use(ResolveTenant(config.TenancyConfig{Default: "default"}, zap.NewNop()))
+	handler := func(c *gin.Context) {
+		*tenant = Tenant(c)
+		c.Status(http.StatusOK)
+	}
+	router.GET("/", handler)
+	router.POST("/", handler)
+	return router
+}
+
+// issue issues a key with scopes bound to tenant, failing the test on
+// error.
+func issue(t *testing.T, keys *services.APIKeyService, tenant string, scopes ...string) string {
+	t.Helper()
+	issued, err := keys.IssueAPIKey(&models.APIKeyRequest{Name: "test", Scopes: scopes, TenantID: tenant})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return issued.Key
+}
+
+func TestAPIKeyAuthentication(t *testing.T) {
+	keys := services.NewAPIKeyService(repositories.NewMemoryAPIKeyRepository(repositories.NewMemoryDB()))
+	reader := issue(t, keys, "", models.ScopeBooksRead)
+	writer := issue(t, keys, "", models.ScopeBooksRead, models.ScopeBooksWrite)
+	revoked := issue(t, keys, "", models.ScopeBooksRead)
+	revokedKey, err := keys.Authenticate(revoked)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := keys.RevokeAPIKey(revokedKey.ID); err != nil {
+		t.Fatal(err)
+	}
+	north := issue(t, keys, "north", models.ScopeBooksRead)
+
+	tests := []struct {
+		name       string
+		method     string
+		key        string
+		header     string
+		wantStatus int
+		wantTenant string
+	}{
+		{name: "no credentials", method: http.MethodGet, wantStatus: http.StatusUnauthorized},
+		{name: "unknown key", method: http.MethodGet, key: "bms_ffffffffffff_secret", wantStatus: http.StatusUnauthorized},
+		{name: "revoked key", method: http.MethodGet, key: revoked, wantStatus: http.StatusUnauthorized},
+		{name: "read with read scope", method: http.MethodGet, key: reader, wantStatus: http.StatusOK, wantTenant: "default"},
+		{name: "write with read scope", method: http.MethodPost, key: reader, wantStatus: http.StatusForbidden},
+		{name: "write with write scope", method: http.MethodPost, key: writer, wantStatus: http.StatusOK, wantTenant: "default"},
+		{name: "bound key", method: http.MethodGet, key: north, wantStatus: http.StatusOK, wantTenant: "north"},
+		{name: "bound key names its tenant", method: http.MethodGet, key: north, header: "north", wantStatus: http.StatusOK, wantTenant: "north"},
+		{name: "bound key names another", method: http.MethodGet, key: north, header: "south", wantStatus: http.StatusForbidden},
+		{name: "bound key names the default", method: http.MethodGet, key: north, header: "default", wantStatus: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tenant string
+			request := httptest.NewRequest(tt.method, "/", nil)
+			if tt.key != "" {
+				request.Header.Set(APIKeyHeader, tt.key)
+			}
+			if tt.header != "" {
+				request.Header.Set(TenantHeader, tt.header)
+			}
+			recorder := httptest.NewRecorder()
+			keyRouter(keys, &tenant).ServeHTTP(recorder, request)
+
+			if recorder.Code != tt.wantStatus {
+				t.Fatalf("status: got %d, want %d (%s)", recorder.Code, tt.wantStatus, recorder.Body)
+			}
+			if tenant != tt.wantTenant {
+				t.Errorf("tenant: got %q, want %q", tenant, tt.wantTenant)
+			}
+		})
+	}
+}
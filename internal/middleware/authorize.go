@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shani34/book-management-system/internal/policy"
+	"go.uber.org/zap"
+)
+
+// Authorize refuses the request with 403 unless the caller's roles grant
+// perm under p. Anonymous callers have the anonymous role, and
+// authenticated ones without a role the policy's default role. A nil p
+// allows everything, for when authentication is disabled.
+//
+// The response carries a machine-readable reason (see the policy.Reason
+// constants) alongside the message:
+//
+//	{"error": "...", "reason": "missing_permission", "permission": "book:delete"}
+func Authorize(p *policy.Policy, perm policy.Permission, logger *zap.Logger) gin.HandlerFunc {
+	logger = logger.Named("middleware.Authorize")
+	return func(c *gin.Context) {
+		if p == nil {
+			return
+		}
+
+		var roles []string
+		subject := ""
+		switch principal := GetPrincipal(c); {
+		case principal == nil:
+			roles = []string{policy.RoleAnonymous}
+		case len(principal.Roles) == 0 && p.DefaultRole() != "":
+			roles = []string{p.DefaultRole()}
+			subject = principal.Subject
+		default:
+			roles = principal.Roles
+			subject = principal.Subject
+		}
+
+		denial := p.Check(roles, perm)
+		if denial == nil {
+			return
+		}
+		logger.Warn("Access denied",
+			zap.String("subject", subject),
+			zap.Strings("roles", roles),
+			zap.String("permission", string(perm)),
+			zap.String("reason", denial.Reason),
+			zap.String("method", c.Request.Method),
+			zap.String("path", c.Request.URL.Path),
+		)
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+			"error":      "forbidden: " + denial.Error(),
+			"reason":     denial.Reason,
+			"permission": string(perm),
+		})
+	}
+}
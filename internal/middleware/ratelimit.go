@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shani34/book-management-system/pkg/ratelimit"
+	"go.uber.org/zap"
+)
+
+// RateLimit refuses requests over limit with 429. Callers are counted per
+// route group: by API key, else by token subject, else by client IP, so it
+// runs after Authenticate. Every response carries X-RateLimit-Limit,
+// X-RateLimit-Remaining and X-RateLimit-Reset (seconds until the full
+// allowance is back), and refusals Retry-After.
+//
+// If the limiter fails the request is let through; an outage of the
+// limit store should not take the API down with it.
+func RateLimit(limiter ratelimit.Limiter, group string, limit ratelimit.Limit, logger *zap.Logger) gin.HandlerFunc {
+	logger = logger.Named("middleware.RateLimit")
+	return func(c *gin.Context) {
+		caller := rateLimitCaller(c)
+		result, err := limiter.Allow(ratelimit.KeyPrefix+group+":"+caller, limit)
+		if err != nil {
+			logger.Error("Failed to check rate limit", zap.String("group", group), zap.Error(err))
+			return
+		}
+
+		c.Header("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("X-RateLimit-Reset", strconv.Itoa(seconds(result.Reset)))
+		if result.Allowed {
+			return
+		}
+
+		logger.Warn("Rate limit exceeded",
+			zap.String("group", group),
+			zap.String("caller", caller),
+			zap.String("limit", limit.String()),
+			zap.String("method", c.Request.Method),
+			zap.String("path", c.Request.URL.Path),
+		)
+		c.Header("Retry-After", strconv.Itoa(seconds(result.RetryAfter)))
+		c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "rate limit exceeded, retry later"})
+	}
+}
+
+// LimitAuthFailures refuses requests with 429 from a client IP that has
+// failed authentication more than limit allows, before their credentials
+// are checked, so keys and tokens can't be guessed at full speed. Only
+// requests that carried credentials and got 401 are counted. It runs
+// before Authenticate.
+func LimitAuthFailures(limiter ratelimit.Limiter, limit ratelimit.Limit, logger *zap.Logger) gin.HandlerFunc {
+	logger = logger.Named("middleware.LimitAuthFailures")
+	return func(c *gin.Context) {
+		key := ratelimit.KeyPrefix + ratelimit.AuthFailuresGroup + ":ip:" + c.ClientIP()
+		result, err := limiter.Peek(key, limit)
+		if err != nil {
+			logger.Error("Failed to check auth failure limit", zap.Error(err))
+		} else if !result.Allowed {
+			logger.Warn("Too many failed authentication attempts",
+				zap.String("client_ip", c.ClientIP()),
+				zap.String("limit", limit.String()),
+				zap.String("method", c.Request.Method),
+				zap.String("path", c.Request.URL.Path),
+			)
+			c.Header("Retry-After", strconv.Itoa(seconds(result.RetryAfter)))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "too many failed authentication attempts, retry later"})
+			return
+		}
+
+		c.Next()
+
+		credentials := c.GetHeader(APIKeyHeader) != "" || c.GetHeader("Authorization") != ""
+		if credentials && c.Writer.Status() == http.StatusUnauthorized {
+			if _, err := limiter.Allow(key, limit); err != nil {
+				logger.Error("Failed to count auth failure", zap.Error(err))
+			}
+		}
+	}
+}
+
+func rateLimitCaller(c *gin.Context) string {
+	principal := GetPrincipal(c)
+	switch {
+	case principal != nil && principal.APIKey != nil:
+		return "key:" + principal.APIKey.Prefix
+	case principal != nil && principal.Subject != "":
+		return "user:" + principal.Subject
+	default:
+		return "ip:" + c.ClientIP()
+	}
+}
+
+// seconds rounds d up, so a client waiting that long is let through.
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
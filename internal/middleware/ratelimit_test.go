@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shani34/book-management-system/pkg/ratelimit"
+	"go.uber.org/zap"
+)
+
+// fixedLimiter answers every call with result and err.
+type fixedLimiter struct {
+	result ratelimit.Result
+	err    error
+	keys   []string
+}
+
+func (f *fixedLimiter) Allow(key string, limit ratelimit.Limit) (ratelimit.Result, error) {
+	f.keys = append(f.keys, key)
+	return f.result, f.err
+}
+
+func (f *fixedLimiter) Peek(key string, limit ratelimit.Limit) (ratelimit.Result, error) {
+	return f.result, f.err
+}
+
+func serveRateLimited(limiter ratelimit.Limiter, principal *Principal) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if principal != nil {
+			setPrincipal(c, principal)
+		}
+	})
+	router.Use(RateLimit(limiter, "books", ratelimit.Limit{Requests: 5, Window: time.Second}, zap.NewNop()))
+	router.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	return recorder
+}
+
+func TestRateLimitHeaders(t *testing.T) {
+	limiter := &fixedLimiter{result: ratelimit.Result{Allowed: true, Limit: 5, Remaining: 3, Reset: 400 * time.Millisecond}}
+	recorder := serveRateLimited(limiter, &Principal{Subject: "jane"})
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status %d, want 200", recorder.Code)
+	}
+	for header, want := range map[string]string{
+		"X-RateLimit-Limit":     "5",
+		"X-RateLimit-Remaining": "3",
+		"X-RateLimit-Reset":     "1",
+		"Retry-After":           "",
+	} {
+		if got := recorder.Header().Get(header); got != want {
+			t.Errorf("%s: got %q, want %q", header, got, want)
+		}
+	}
+	if len(limiter.keys) != 1 || limiter.keys[0] != "ratelimit:books:user:jane" {
+		t.Errorf("keys: got %v, want the caller's subject", limiter.keys)
+	}
+}
+
+func TestRateLimitRefusal(t *testing.T) {
+	limiter := &fixedLimiter{result: ratelimit.Result{Limit: 5, Reset: time.Second, RetryAfter: 1200 * time.Millisecond}}
+	recorder := serveRateLimited(limiter, nil)
+
+	if recorder.Code != http.StatusTooManyRequests {
+		t.Fatalf("status %d, want 429", recorder.Code)
+	}
+	// Retry-After rounds up, so waiting that long is always enough.
+	if got := recorder.Header().Get("Retry-After"); got != "2" {
+		t.Errorf("Retry-After: got %q, want 2", got)
+	}
+	if got := recorder.Header().Get("X-RateLimit-Remaining"); got != "0" {
+		t.Errorf("X-RateLimit-Remaining: got %q, want 0", got)
+	}
+	if len(limiter.keys) != 1 || limiter.keys[0] != "ratelimit:books:ip:192.0.2.1" {
+		t.Errorf("keys: got %v, want the client IP", limiter.keys)
+	}
+}
+
+func TestRateLimitFailsOpen(t *testing.T) {
+	recorder := serveRateLimited(&fixedLimiter{err: errors.New("redis down")}, nil)
+	if recorder.Code != http.StatusOK {
+		t.Errorf("status %d, want the request let through", recorder.Code)
+	}
+	if got := recorder.Header().Get("X-RateLimit-Limit"); got != "" {
+		t.Errorf("X-RateLimit-Limit: got %q, want none", got)
+	}
+}
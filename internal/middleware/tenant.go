@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shani34/book-management-system/config"
+	"github.com/shani34/book-management-system/internal/models"
+	"go.uber.org/zap"
+)
+
+// TenantKey is the context key set by ResolveTenant.
+const TenantKey = "tenant.id"
+
+// TenantHeader names the tenant of a request explicitly.
+const TenantHeader = "X-Tenant-ID"
+
+// tenantClaim is the token claim that binds a caller to one tenant.
+const tenantClaim = "tenant"
+
+// ResolveTenant works out which tenant's catalogue the request is for and
+// puts it on the context for Tenant. It runs after Authenticate.
+//
+// The tenant is named by the X-Tenant-ID header or, when cfg.BaseDomain is
+// set, by the subdomain of the Host, and the two must agree. A caller bound
+// to a tenant, by the "tenant" claim of its token or the tenant of its API
+// key, gets that tenant when the request names none and is refused with
+// 403 when it names another. Anonymous callers and credentials bound to no
+// tenant get the tenant named, so a school's subdomain serves its public
+// catalogue. Requests naming none get cfg.Default, or 400 when
+// cfg.Required is set.
+func ResolveTenant(cfg config.TenancyConfig, logger *zap.Logger) gin.HandlerFunc {
+	logger = logger.Named("middleware.ResolveTenant")
+	return func(c *gin.Context) {
+		requested := strings.ToLower(strings.TrimSpace(c.GetHeader(TenantHeader)))
+		if sub := subdomain(c.Request.Host, cfg.BaseDomain); sub != "" {
+			if requested != "" && requested != sub {
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "tenant header does not match the host"})
+				return
+			}
+			requested = sub
+		}
+		if requested != "" && !models.ValidTenantID(requested) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid tenant id"})
+			return
+		}
+
+		bound := boundTenant(GetPrincipal(c))
+		tenant := requested
+		switch {
+		case bound != "" && requested != "" && bound != requested:
+			logger.Warn("Tenant mismatch",
+				zap.String("subject", Subject(c)),
+				zap.String("bound", bound),
+				zap.String("requested", requested),
+				zap.String("method", c.Request.Method),
+				zap.String("path", c.Request.URL.Path),
+			)
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error":  "forbidden: credentials are for another tenant",
+				"reason": "tenant_mismatch",
+			})
+			return
+		case bound != "":
+			tenant = bound
+		case tenant == "" && cfg.Required:
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "tenant required: set the " + TenantHeader + " header"})
+			return
+		case tenant == "":
+			tenant = cfg.Default
+		}
+		c.Set(TenantKey, tenant)
+	}
+}
+
+// Tenant returns the tenant resolved for the request.
+func Tenant(c *gin.Context) string {
+	return c.GetString(TenantKey)
+}
+
+// BoundTenant returns the tenant the caller's credentials are confined to,
+// or "" for anonymous callers and credentials bound to no tenant.
+func BoundTenant(c *gin.Context) string {
+	return boundTenant(GetPrincipal(c))
+}
+
+func boundTenant(principal *Principal) string {
+	switch {
+	case principal == nil:
+		return ""
+	case principal.APIKey != nil:
+		return principal.APIKey.TenantID
+	default:
+		tenant, _ := principal.Claims[tenantClaim].(string)
+		return tenant
+	}
+}
+
+// subdomain returns the label of host directly under baseDomain, as in
+// school1 for school1.books.example.com, or "" if host isn't under it.
+func subdomain(host, baseDomain string) string {
+	if baseDomain == "" {
+		return ""
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	label, found := strings.CutSuffix(strings.ToLower(host), "."+strings.ToLower(baseDomain))
+	if !found || strings.Contains(label, ".") {
+		return ""
+	}
+	return label
+}
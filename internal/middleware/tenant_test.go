@@ -0,0 +1,120 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shani34/book-management-system/config"
+	"github.com/shani34/book-management-system/internal/models"
+	"github.com/shani34/book-management-system/pkg/jwt"
+	"go.uber.org/zap"
+)
+
+func TestResolveTenant(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := config.TenancyConfig{Default: "default", BaseDomain: "books.example.com"}
+
+	tests := []struct {
+		name       string
+		principal  *Principal
+		host       string
+		header     string
+		wantStatus int
+		wantTenant string
+	}{
+		{name: "no header", wantStatus: http.StatusOK, wantTenant: "default"},
+		{name: "default named", header: "default", wantStatus: http.StatusOK, wantTenant: "default"},
+		{name: "anonymous names tenant", header: "a", wantStatus: http.StatusOK, wantTenant: "a"},
+		{name: "anonymous on subdomain", host: "a.books.example.com", wantStatus: http.StatusOK, wantTenant: "a"},
+		{name: "subdomain with port", host: "a.books.example.com:8080", wantStatus: http.StatusOK, wantTenant: "a"},
+		{name: "subdomain and header agree", host: "a.books.example.com", header: "a", wantStatus: http.StatusOK, wantTenant: "a"},
+		{name: "subdomain and header differ", host: "a.books.example.com", header: "b", wantStatus: http.StatusBadRequest},
+		{name: "base domain", host: "books.example.com", wantStatus: http.StatusOK, wantTenant: "default"},
+		{name: "nested subdomain", host: "x.a.books.example.com", wantStatus: http.StatusOK, wantTenant: "default"},
+		{
+			name:       "unbound user names tenant",
+			principal:  &Principal{Subject: "u", Roles: []string{"librarian"}},
+			header:     "a",
+			wantStatus: http.StatusOK,
+			wantTenant: "a",
+		},
+		{
+			name:       "unbound key on subdomain",
+			principal:  &Principal{Subject: "apikey:x", Scopes: models.Scopes{models.ScopeBooksRead}, APIKey: &models.APIKey{}},
+			host:       "a.books.example.com",
+			wantStatus: http.StatusOK,
+			wantTenant: "a",
+		},
+		{
+			name:       "bound token",
+			principal:  &Principal{Subject: "u", Claims: jwt.Claims{"tenant": "a"}},
+			wantStatus: http.StatusOK,
+			wantTenant: "a",
+		},
+		{
+			name:       "bound token names its tenant",
+			principal:  &Principal{Subject: "u", Claims: jwt.Claims{"tenant": "a"}},
+			header:     "a",
+			wantStatus: http.StatusOK,
+			wantTenant: "a",
+		},
+		{
+			name:       "bound token on its subdomain",
+			principal:  &Principal{Subject: "u", Claims: jwt.Claims{"tenant": "a"}},
+			host:       "a.books.example.com",
+			wantStatus: http.StatusOK,
+			wantTenant: "a",
+		},
+		{
+			name:       "bound token names another",
+			principal:  &Principal{Subject: "u", Claims: jwt.Claims{"tenant": "a"}},
+			header:     "b",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "bound token on another subdomain",
+			principal:  &Principal{Subject: "u", Claims: jwt.Claims{"tenant": "a"}},
+			host:       "b.books.example.com",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "bound key names another",
+			principal:  &Principal{Subject: "apikey:x", APIKey: &models.APIKey{TenantID: "a"}},
+			header:     "b",
+			wantStatus: http.StatusForbidden,
+		},
+		{name: "invalid id", header: "A_B", wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tenant string
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				if tt.principal != nil {
+					setPrincipal(c, tt.principal)
+				}
+			})
+			router.Use(ResolveTenant(cfg, zap.NewNop()))
+			router.GET("/", func(c *gin.Context) { tenant = Tenant(c) })
+
+			request := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.host != "" {
+				request.Host = tt.host
+			}
+			if tt.header != "" {
+				request.Header.Set(TenantHeader, tt.header)
+			}
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+
+			if recorder.Code != tt.wantStatus {
+				t.Fatalf("status: got %d, want %d (%s)", recorder.Code, tt.wantStatus, recorder.Body)
+			}
+			if tenant != tt.wantTenant {
+				t.Errorf("tenant: got %q, want %q", tenant, tt.wantTenant)
+			}
+		})
+	}
+}
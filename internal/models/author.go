@@ -0,0 +1,28 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrDuplicateAuthor = errors.New("author already exists")
+	ErrAuthorHasBooks  = errors.New("author still has books")
+)
+
+// Author is a person credited on books. Book.Author keeps the display
+// string as entered; Book.Authors links the book to Author rows through the
+// book_authors join table. Each tenant keeps its own authors, and books
+// only link to authors of their tenant.
+type Author struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	TenantID  string    `gorm:"size:63;not null;default:'default';uniqueIndex:idx_authors_tenant_name,priority:1" json:"tenant_id"`
+	Name      string    `gorm:"not null;uniqueIndex:idx_authors_tenant_name,priority:2" json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Swagger model documentation
+type AuthorRequest struct {
+	Name string `json:"name" example:"Alan A. A. Donovan"`
+}
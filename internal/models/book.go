@@ -1,21 +1,59 @@
 package models
 
 import (
+	"errors"
 	"time"
+
+	"gorm.io/gorm"
 )
 
+// ErrVersionConflict means a write was based on a stale version of the row.
+var ErrVersionConflict = errors.New("version conflict")
+
+// ErrDuplicateISBN means another book, possibly one in the trash, already
+// has the ISBN.
+var ErrDuplicateISBN = errors.New("isbn already in use")
+
 type Book struct {
 	ID        uint      `gorm:"primaryKey" json:"id"`
+	// TenantID is the catalogue the book belongs to. It is set from the
+	// request, never from the body, and every book query is scoped by it.
+	TenantID  string    `gorm:"size:63;not null;default:'default';index" json:"tenant_id"`
 	Title     string    `gorm:"not null" json:"title"`
 	Author    string    `gorm:"not null" json:"author"`
 	Year      int       `json:"year"`
+	ISBN10    string    `gorm:"size:10" json:"isbn10,omitempty"`
+	ISBN13    string    `gorm:"size:13" json:"isbn13,omitempty"`
+	Version   uint      `gorm:"not null;default:1" json:"version"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+	// DeletedAt is set while the book is in the trash; GORM leaves such
+	// rows out of every query unless it is run Unscoped.
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty" swaggertype:"string" format:"date-time"`
+	Authors   []Author       `gorm:"many2many:book_authors;" json:"authors,omitempty"`
+	Tags      []Tag          `gorm:"many2many:book_tags;" json:"tags,omitempty"`
+	// Editions is only filled in when a single book is read.
+	Editions []Edition `json:"editions,omitempty"`
+	// AuthorIDs is write-only: when sent, it replaces the book's author
+	// links. Otherwise the book is linked to the Author named by Author.
+	AuthorIDs []uint `gorm:"-" json:"author_ids,omitempty"`
+}
+
+// BookSearchResult is a full-text search hit. The highlight fields hold the
+// title and author with matching terms wrapped in <mark> tags.
+type BookSearchResult struct {
+	Book
+	Rank            float64 `json:"rank"`
+	TitleHighlight  string  `json:"title_highlight"`
+	AuthorHighlight string  `json:"author_highlight"`
 }
 
 // Swagger model documentation
 type BookRequest struct {
-	Title  string `json:"title" example:"The Go Programming Language"`
-	Author string `json:"author" example:"Alan A. A. Donovan"`
-	Year   int    `json:"year" example:"2015"`
+	Title     string `json:"title" example:"The Go Programming Language"`
+	Author    string `json:"author" example:"Alan A. A. Donovan"`
+	Year      int    `json:"year" example:"2015"`
+	ISBN10    string `json:"isbn10,omitempty" example:"0-13-419044-0"`
+	ISBN13    string `json:"isbn13,omitempty" example:"978-0-13-419044-0"`
+	AuthorIDs []uint `json:"author_ids,omitempty"`
 }
\ No newline at end of file
@@ -0,0 +1,92 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// BookCursor marks a position in a sorted book listing: the sort key and id
+// of the row the page starts after (or, with Before set, ends before). It
+// travels to clients as an opaque base64 token.
+type BookCursor struct {
+	Sort   string `json:"s,omitempty"`
+	Desc   bool   `json:"d,omitempty"`
+	Value  string `json:"v,omitempty"`
+	ID     uint   `json:"id"`
+	Before bool   `json:"b,omitempty"`
+}
+
+// BookPage is the response envelope for cursor pagination.
+type BookPage struct {
+	Items      []Book `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+	Total      int64  `json:"total"`
+}
+
+func NewBookCursor(book Book, filter BookFilter, before bool) BookCursor {
+	cursor := BookCursor{
+		Sort:   filter.Sort,
+		Desc:   filter.Desc,
+		ID:     book.ID,
+		Before: before,
+	}
+	switch filter.Sort {
+	case "title":
+		cursor.Value = book.Title
+	case "author":
+		cursor.Value = book.Author
+	case "year":
+		cursor.Value = strconv.Itoa(book.Year)
+	case "created_at":
+		cursor.Value = book.CreatedAt.UTC().Format(time.RFC3339Nano)
+	}
+	return cursor
+}
+
+func (c BookCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeBookCursor parses a token produced by Encode and checks that it was
+// issued for the same sort order as filter.
+func DecodeBookCursor(token string, filter BookFilter) (*BookCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cursor BookCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if cursor.Sort != filter.Sort || cursor.Desc != filter.Desc {
+		return nil, ErrInvalidCursor
+	}
+	if _, err := cursor.Position(); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+// Position rebuilds the sort key fields of the book the cursor points at.
+func (c BookCursor) Position() (Book, error) {
+	book := Book{ID: c.ID}
+	var err error
+	switch c.Sort {
+	case "title":
+		book.Title = c.Value
+	case "author":
+		book.Author = c.Value
+	case "year":
+		book.Year, err = strconv.Atoi(c.Value)
+	case "created_at":
+		book.CreatedAt, err = time.Parse(time.RFC3339Nano, c.Value)
+	}
+	return book, err
+}
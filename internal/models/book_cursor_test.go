@@ -0,0 +1,50 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBookCursorRoundTrip(t *testing.T) {
+	book := Book{ID: 7, Title: "Dune", Author: "Frank Herbert", Year: 1965, CreatedAt: time.Date(2024, 5, 1, 12, 0, 0, 123456789, time.UTC)}
+	for field := range BookSortFields {
+		for _, desc := range []bool{false, true} {
+			filter := BookFilter{Sort: field, Desc: desc}
+			token := NewBookCursor(book, filter, desc).Encode()
+
+			cursor, err := DecodeBookCursor(token, filter)
+			if err != nil {
+				t.Fatalf("%s desc=%v: %v", field, desc, err)
+			}
+			position, err := cursor.Position()
+			if err != nil || position.ID != 7 || cursor.Before != desc {
+				t.Fatalf("%s desc=%v: got %+v, %v", field, desc, cursor, err)
+			}
+			if compare := map[string]bool{
+				"title":      position.Title == book.Title,
+				"author":     position.Author == book.Author,
+				"year":       position.Year == book.Year,
+				"created_at": position.CreatedAt.Equal(book.CreatedAt),
+			}; !compare[field] {
+				t.Errorf("%s: position %+v lost the sort key", field, position)
+			}
+		}
+	}
+}
+
+func TestDecodeBookCursorRejects(t *testing.T) {
+	filter := BookFilter{Sort: "year"}
+	tests := map[string]string{
+		"other sort field": NewBookCursor(Book{ID: 1}, BookFilter{Sort: "title"}, false).Encode(),
+		"other direction":  NewBookCursor(Book{ID: 1}, BookFilter{Sort: "year", Desc: true}, false).Encode(),
+		"not base64":       "***",
+		"not json":         "bm90IGpzb24",
+		"bad year":         BookCursor{Sort: "year", Value: "soon", ID: 1}.Encode(),
+	}
+	for name, token := range tests {
+		if _, err := DecodeBookCursor(token, filter); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("%s: got %v, want ErrInvalidCursor", name, err)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+)
+
+// BookSortFields maps the sort values accepted by the list endpoint to the
+// columns they order by.
+var BookSortFields = map[string]string{
+	"title":      "title",
+	"author":     "author",
+	"year":       "year",
+	"created_at": "created_at",
+}
+
+// BookFilter narrows and orders a book listing. Zero values mean "no
+// filter"; YearFrom and YearTo are pointers because 0 is a valid year.
+// Tags matches books carrying any of the named tags, or all of them when
+// AllTags is set.
+type BookFilter struct {
+	Author         string
+	AuthorContains string
+	TitlePrefix    string
+	YearFrom       *int
+	YearTo         *int
+	Tags           []string
+	AllTags        bool
+	Sort           string
+	Desc           bool
+}
+
+// Validate rejects unknown sort fields and inverted year ranges.
+func (f BookFilter) Validate() error {
+	if f.Sort != "" {
+		if _, ok := BookSortFields[f.Sort]; !ok {
+			return fmt.Errorf("invalid sort field %q", f.Sort)
+		}
+	}
+	if f.YearFrom != nil && f.YearTo != nil && *f.YearFrom > *f.YearTo {
+		return fmt.Errorf("year_from must not be after year_to")
+	}
+	return nil
+}
+
+// CacheKey is a canonical encoding of the filter, so equal filters always
+// produce the same cache key.
+func (f BookFilter) CacheKey() string {
+	values := url.Values{}
+	if f.Author != "" {
+		values.Set("author", f.Author)
+	}
+	if f.AuthorContains != "" {
+		values.Set("author_contains", f.AuthorContains)
+	}
+	if f.TitlePrefix != "" {
+		values.Set("title_prefix", f.TitlePrefix)
+	}
+	if f.YearFrom != nil {
+		values.Set("year_from", strconv.Itoa(*f.YearFrom))
+	}
+	if f.YearTo != nil {
+		values.Set("year_to", strconv.Itoa(*f.YearTo))
+	}
+	if len(f.Tags) > 0 {
+		tags := slices.Clone(f.Tags)
+		slices.Sort(tags)
+		values["tag"] = slices.Compact(tags)
+		if f.AllTags {
+			values.Set("tag_match", "all")
+		}
+	}
+	if f.Sort != "" {
+		values.Set("sort", f.Sort)
+	}
+	if f.Desc {
+		values.Set("order", "desc")
+	}
+	return values.Encode()
+}
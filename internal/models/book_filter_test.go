@@ -0,0 +1,48 @@
+package models
+
+import "testing"
+
+func year(y int) *int { return &y }
+
+func TestBookFilterValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter BookFilter
+		ok     bool
+	}{
+		{"empty", BookFilter{}, true},
+		{"every sort field", BookFilter{Sort: "created_at", Desc: true}, true},
+		{"unknown sort field", BookFilter{Sort: "id"}, false},
+		{"single year", BookFilter{YearFrom: year(1965), YearTo: year(1965)}, true},
+		{"year zero", BookFilter{YearFrom: year(0)}, true},
+		{"inverted years", BookFilter{YearFrom: year(2000), YearTo: year(1999)}, false},
+	}
+	for _, tt := range tests {
+		if err := tt.filter.Validate(); (err == nil) != tt.ok {
+			t.Errorf("%s: got %v", tt.name, err)
+		}
+	}
+}
+
+func TestBookFilterCacheKey(t *testing.T) {
+	filter := BookFilter{
+		Author:         "Frank Herbert",
+		AuthorContains: "herb",
+		TitlePrefix:    "du&ne",
+		YearFrom:       year(0),
+		YearTo:         year(1970),
+		Sort:           "year",
+		Desc:           true,
+	}
+	want := "author=Frank+Herbert&author_contains=herb&order=desc&sort=year&title_prefix=du%26ne&year_from=0&year_to=1970"
+	if got := filter.CacheKey(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got := (BookFilter{}).CacheKey(); got != "" {
+		t.Errorf("empty filter: got %q", got)
+	}
+	// Equal filters give equal keys whichever pointers they hold.
+	if (BookFilter{YearFrom: year(1)}).CacheKey() != (BookFilter{YearFrom: year(1)}).CacheKey() {
+		t.Error("equal filters gave different keys")
+	}
+}
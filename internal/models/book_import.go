@@ -0,0 +1,18 @@
+package models
+
+// ImportReport summarises a bulk import. Rows lists every input row in
+// order, with either the ID it was created under or why it was rejected.
+type ImportReport struct {
+	Total   int         `json:"total"`
+	Created int         `json:"created"`
+	Failed  int         `json:"failed"`
+	Rows    []ImportRow `json:"rows"`
+}
+
+// ImportRow is the outcome of one input row. Row is the line number in the
+// uploaded file, so for CSV the first data row is 2.
+type ImportRow struct {
+	Row   int    `json:"row"`
+	ID    uint   `json:"id,omitempty"`
+	Error string `json:"error,omitempty"`
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// Copy statuses. CopyOnLoan and CopyOnHold are managed by circulation:
+// a checkout sets on_loan, and a copy that comes back while its book has
+// waiting holds is set aside as on_hold for the next member in the queue.
+// The others are set by staff.
+const (
+	CopyAvailable   = "available"
+	CopyOnLoan      = "on_loan"
+	CopyOnHold      = "on_hold"
+	CopyMaintenance = "maintenance"
+	CopyLost        = "lost"
+)
+
+var (
+	ErrDuplicateBarcode = errors.New("barcode already in use")
+	// ErrCopyUnavailable means the copy is on loan or otherwise out of
+	// circulation, so it cannot be checked out.
+	ErrCopyUnavailable = errors.New("copy is not available for loan")
+	// ErrCopyOnLoan means the change needs the copy back first.
+	ErrCopyOnLoan = errors.New("copy is on loan")
+	ErrCopyOnHold = errors.New("copy is held for a member")
+	// ErrCopyHasLoans means the copy has loan history and can only be
+	// marked lost, not deleted.
+	ErrCopyHasLoans  = errors.New("copy has loan history")
+	ErrLoanReturned  = errors.New("loan already returned")
+	ErrBookHasCopies = errors.New("book still has copies")
+)
+
+// Copy is one physical item of a book that can be lent out.
+type Copy struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	TenantID  string    `gorm:"size:63;not null;default:'default';uniqueIndex:idx_copies_tenant_barcode,priority:1" json:"tenant_id"`
+	BookID    uint      `gorm:"not null;index" json:"book_id"`
+	Barcode   string    `gorm:"not null;uniqueIndex:idx_copies_tenant_barcode,priority:2" json:"barcode"`
+	Status    string    `gorm:"not null;default:available" json:"status"`
+	Location  string    `json:"location,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Loan is a checkout of a copy. It is active until ReturnedAt is set; the
+// database allows at most one active loan per copy.
+type Loan struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	TenantID     string     `gorm:"size:63;not null;default:'default';index" json:"tenant_id"`
+	CopyID       uint       `gorm:"not null;index" json:"copy_id"`
+	BookID       uint       `gorm:"not null;index" json:"book_id"`
+	MemberID     uint       `gorm:"index" json:"member_id"`
+	CheckedOutAt time.Time  `gorm:"not null" json:"checked_out_at"`
+	DueAt        time.Time  `gorm:"not null" json:"due_at"`
+	ReturnedAt   *time.Time `json:"returned_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// LoanFilter narrows a loan listing; zero values mean "no filter".
+type LoanFilter struct {
+	BookID     uint
+	CopyID     uint
+	MemberID   uint
+	ActiveOnly bool
+}
+
+// Swagger model documentation
+type CopyRequest struct {
+	Barcode  string `json:"barcode" example:"LIB-000123"`
+	Status   string `json:"status,omitempty" example:"available" enums:"available,maintenance,lost"`
+	Location string `json:"location,omitempty" example:"Main branch, shelf 4B"`
+}
+
+// CheckoutRequest identifies the copy by ID or barcode. DueAt defaults to
+// the loan period of the member's tier from now, and may not be later.
+type CheckoutRequest struct {
+	CopyID   uint       `json:"copy_id,omitempty" example:"1"`
+	Barcode  string     `json:"barcode,omitempty" example:"LIB-000123"`
+	MemberID uint       `json:"member_id" example:"1"`
+	DueAt    *time.Time `json:"due_at,omitempty"`
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"time"
+)
+
+// DateLayout is the wire and storage format of a Date.
+const DateLayout = "2006-01-02"
+
+// Date is a calendar day without a time of day, such as a publication date.
+// It is written as "YYYY-MM-DD" in JSON and stored in a DATE column.
+type Date struct {
+	time.Time
+}
+
+func ParseDate(s string) (Date, error) {
+	t, err := time.Parse(DateLayout, s)
+	if err != nil {
+		return Date{}, fmt.Errorf("invalid date %q, want YYYY-MM-DD", s)
+	}
+	return Date{t}, nil
+}
+
+func (d Date) String() string {
+	return d.Format(DateLayout)
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + d.String() + `"`), nil
+}
+
+func (d *Date) UnmarshalJSON(data []byte) error {
+	if len(data) < 2 || data[0] != '"' || data[len(data)-1] != '"' {
+		return fmt.Errorf("invalid date %s, want \"YYYY-MM-DD\"", data)
+	}
+	parsed, err := ParseDate(string(data[1 : len(data)-1]))
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+func (d Date) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+func (d *Date) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case time.Time:
+		*d = Date{time.Date(v.Year(), v.Month(), v.Day(), 0, 0, 0, 0, time.UTC)}
+		return nil
+	case string:
+		parsed, err := ParseDate(v)
+		*d = parsed
+		return err
+	case []byte:
+		parsed, err := ParseDate(string(v))
+		*d = parsed
+		return err
+	default:
+		return fmt.Errorf("cannot scan %T into Date", value)
+	}
+}
+
+// GormDataType makes AutoMigrate create a DATE column.
+func (Date) GormDataType() string {
+	return "date"
+}
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// Edition is one printing, translation or format of the work a Book
+// describes. ISBN is stored as the normalized ISBN-13. TenantID is always
+// the book's; it is kept on the edition so ISBNs can be unique per tenant.
+type Edition struct {
+	ID              uint      `gorm:"primaryKey" json:"id"`
+	TenantID        string    `gorm:"size:63;not null;default:'default'" json:"tenant_id"`
+	BookID          uint      `gorm:"not null;index" json:"book_id"`
+	Publisher       string    `json:"publisher"`
+	Format          string    `gorm:"not null" json:"format"`
+	Language        string    `json:"language,omitempty"`
+	ISBN            string    `gorm:"size:13" json:"isbn,omitempty"`
+	PublicationDate *Date     `json:"publication_date,omitempty" swaggertype:"string" format:"date"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// EditionFormats are the accepted values of Edition.Format.
+var EditionFormats = map[string]bool{
+	"hardcover": true,
+	"paperback": true,
+	"ebook":     true,
+	"audiobook": true,
+}
+
+// Swagger model documentation
+type EditionRequest struct {
+	Publisher       string `json:"publisher" example:"Addison-Wesley"`
+	Format          string `json:"format" example:"paperback" enums:"hardcover,paperback,ebook,audiobook"`
+	Language        string `json:"language,omitempty" example:"en"`
+	ISBN            string `json:"isbn,omitempty" example:"978-0-13-419044-0"`
+	PublicationDate string `json:"publication_date,omitempty" example:"2015-10-26"`
+}
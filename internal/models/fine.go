@@ -0,0 +1,86 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// Ledger entry kinds. Assessments add to what a member owes; payments and
+// waivers are recorded as negative amounts.
+const (
+	LedgerAssessed = "assessed"
+	LedgerPayment  = "payment"
+	LedgerWaiver   = "waiver"
+)
+
+var (
+	ErrFineSettled = errors.New("fine is already settled")
+	// ErrFinesOutstanding refuses a checkout while the member owes more
+	// than their tier allows.
+	ErrFinesOutstanding = errors.New("member has outstanding fines above the limit of their tier")
+)
+
+// Fine is what a member owes for returning a loan late. All amounts are in
+// minor currency units (cents). Balance is Amount less Paid and Waived,
+// and is kept in step with them by the store.
+type Fine struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	TenantID  string    `gorm:"size:63;not null;default:'default';index" json:"tenant_id"`
+	LoanID    uint      `gorm:"not null;uniqueIndex" json:"loan_id"`
+	MemberID  uint      `gorm:"not null;index" json:"member_id"`
+	DaysLate  int       `gorm:"not null" json:"days_late"`
+	Amount    int64     `gorm:"not null" json:"amount"`
+	Paid      int64     `gorm:"not null;default:0" json:"paid"`
+	Waived    int64     `gorm:"not null;default:0" json:"waived"`
+	Balance   int64     `gorm:"not null" json:"balance"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// LedgerEntry is an append-only record of a change to a fine. The entries
+// of a fine sum to its balance; BalanceAfter is that running total.
+type LedgerEntry struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	TenantID     string    `gorm:"size:63;not null;default:'default';index" json:"tenant_id"`
+	FineID       uint      `gorm:"not null;index" json:"fine_id"`
+	MemberID     uint      `gorm:"not null;index" json:"member_id"`
+	Kind         string    `gorm:"not null" json:"kind"`
+	Amount       int64     `gorm:"not null" json:"amount"`
+	BalanceAfter int64     `gorm:"not null" json:"balance_after"`
+	Note         string    `json:"note,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func (LedgerEntry) TableName() string {
+	return "fine_ledger"
+}
+
+// FineFilter narrows a fine listing; zero values mean "no filter".
+type FineFilter struct {
+	MemberID uint
+	OpenOnly bool
+}
+
+// FineSummary is what a member owes. Accruing is the fine building up on
+// overdue loans not yet returned; it counts towards the checkout limit but
+// can't be paid until the loan is returned and the fine assessed.
+type FineSummary struct {
+	MemberID    uint  `json:"member_id"`
+	Outstanding int64 `json:"outstanding"`
+	Accruing    int64 `json:"accruing"`
+	Total       int64 `json:"total"`
+	Threshold   int64 `json:"threshold"`
+	Blocked     bool  `json:"blocked"`
+}
+
+// Swagger model documentation
+type FinePaymentRequest struct {
+	Amount int64  `json:"amount" example:"250"`
+	Note   string `json:"note,omitempty" example:"Paid at front desk"`
+}
+
+// Swagger model documentation
+type FineWaiverRequest struct {
+	Amount int64  `json:"amount,omitempty" example:"250"`
+	Note   string `json:"note,omitempty" example:"Book returned in a storm"`
+}
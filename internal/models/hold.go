@@ -0,0 +1,60 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// Hold statuses. A hold waits in its book's queue until a copy is
+// allocated to it; it is then ready for pickup until PickupBy.
+const (
+	HoldWaiting   = "waiting"
+	HoldReady     = "ready"
+	HoldFulfilled = "fulfilled"
+	HoldCancelled = "cancelled"
+	HoldExpired   = "expired"
+)
+
+var (
+	ErrDuplicateHold = errors.New("member already has a hold on this book")
+	// ErrAlreadyBorrowing refuses a hold from a member who has a copy of
+	// the book on loan.
+	ErrAlreadyBorrowing = errors.New("member already has this book on loan")
+	// ErrCopiesAvailable means a copy can be checked out straight away, so
+	// there is nothing to wait for.
+	ErrCopiesAvailable = errors.New("book has copies available")
+	ErrHoldClosed      = errors.New("hold is no longer active")
+)
+
+// Hold is a member's place in the queue for a book. Holds are served in ID
+// order. Position is only set while the hold is waiting; 1 is next in line.
+type Hold struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	TenantID  string     `gorm:"size:63;not null;default:'default';index" json:"tenant_id"`
+	BookID    uint       `gorm:"not null;index" json:"book_id"`
+	MemberID  uint       `gorm:"not null;index" json:"member_id"`
+	Status    string     `gorm:"not null;default:waiting" json:"status"`
+	CopyID    *uint      `json:"copy_id,omitempty"`
+	ReadyAt   *time.Time `json:"ready_at,omitempty"`
+	PickupBy  *time.Time `json:"pickup_by,omitempty"`
+	Position  int        `gorm:"->;-:migration" json:"position,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// Active reports whether the hold is still waiting or ready.
+func (h Hold) Active() bool {
+	return h.Status == HoldWaiting || h.Status == HoldReady
+}
+
+// HoldFilter narrows a hold listing; zero values mean "no filter".
+type HoldFilter struct {
+	BookID   uint
+	MemberID uint
+	Status   string
+}
+
+// Swagger model documentation
+type HoldRequest struct {
+	MemberID uint `json:"member_id" example:"1"`
+}
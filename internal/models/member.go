@@ -0,0 +1,118 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrDuplicateEmail = errors.New("email already in use")
+	ErrDuplicateTier  = errors.New("tier already exists")
+	// ErrMemberHasLoans means the member has loan history, so the record
+	// must be kept; block the member instead.
+	ErrMemberHasLoans = errors.New("member has loan history")
+	ErrMemberHasHolds = errors.New("member has active holds")
+
+	// Checkout refusals.
+	ErrMemberBlocked     = errors.New("member is blocked")
+	ErrMembershipExpired = errors.New("membership has expired")
+	ErrLoanLimitReached  = errors.New("member has reached the loan limit of their tier")
+)
+
+// Tier is a membership level. It sets how many copies a member may have
+// on loan at once, how long each loan lasts and the fine policy for late
+// returns. Money is in minor currency units: a late loan costs FinePerDay
+// for every day past its due date beyond the first FineGraceDays, up to
+// FineCap (0 means no cap). Members owing more than FineThreshold can't
+// check anything out. Each tenant sets its own tiers.
+type Tier struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	TenantID      string    `gorm:"size:63;not null;default:'default';uniqueIndex:idx_tiers_tenant_name,priority:1" json:"tenant_id"`
+	Name          string    `gorm:"not null;uniqueIndex:idx_tiers_tenant_name,priority:2" json:"name"`
+	MaxLoans      int       `gorm:"not null" json:"max_loans"`
+	LoanDays      int       `gorm:"not null" json:"loan_days"`
+	FinePerDay    int64     `gorm:"not null;default:0" json:"fine_per_day"`
+	FineGraceDays int       `gorm:"not null;default:0" json:"fine_grace_days"`
+	FineCap       int64     `gorm:"not null;default:0" json:"fine_cap"`
+	FineThreshold int64     `gorm:"not null;default:0" json:"fine_threshold"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// LoanPeriod is how long a checkout lasts when no due date is given.
+func (t Tier) LoanPeriod() time.Duration {
+	return time.Duration(t.LoanDays) * 24 * time.Hour
+}
+
+// Fine works out the fine for a loan due at dueAt and returned (or still
+// out) at at. Every started day past dueAt counts as a day late.
+func (t Tier) Fine(dueAt, at time.Time) (daysLate int, amount int64) {
+	late := at.Sub(dueAt)
+	if late <= 0 {
+		return 0, 0
+	}
+	daysLate = int((late + 24*time.Hour - 1) / (24 * time.Hour))
+	if daysLate <= t.FineGraceDays {
+		return daysLate, 0
+	}
+	amount = int64(daysLate-t.FineGraceDays) * t.FinePerDay
+	if t.FineCap > 0 && amount > t.FineCap {
+		amount = t.FineCap
+	}
+	return daysLate, amount
+}
+
+// DefaultTiers are created for a tenant that has no tiers yet, the first
+// time its tiers are needed.
+func DefaultTiers() []Tier {
+	return []Tier{
+		{Name: "standard", MaxLoans: 5, LoanDays: 14, FinePerDay: 25, FineGraceDays: 1, FineCap: 1000, FineThreshold: 500},
+		{Name: "premium", MaxLoans: 10, LoanDays: 28, FinePerDay: 10, FineGraceDays: 3, FineCap: 500, FineThreshold: 1000},
+	}
+}
+
+// Member is a library patron who can borrow copies. A member with
+// ExpiresOn in the past, or who is Blocked, can't check anything out but
+// can still return what they have.
+type Member struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	TenantID  string    `gorm:"size:63;not null;default:'default';uniqueIndex:idx_members_tenant_email,priority:1" json:"tenant_id"`
+	Name      string    `gorm:"not null" json:"name"`
+	Email     string    `gorm:"not null;uniqueIndex:idx_members_tenant_email,priority:2" json:"email"`
+	TierID    uint      `gorm:"not null;index" json:"tier_id"`
+	Tier      *Tier     `json:"tier,omitempty"`
+	Blocked   bool      `gorm:"not null;default:false" json:"blocked"`
+	ExpiresOn *Date     `json:"expires_on,omitempty" swaggertype:"string" format:"date"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Expired reports whether the membership ran out before the day of now.
+// It is still valid on its expiry date.
+func (m Member) Expired(now time.Time) bool {
+	if m.ExpiresOn == nil {
+		return false
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	return m.ExpiresOn.Before(today)
+}
+
+// Swagger model documentation
+type MemberRequest struct {
+	Name      string `json:"name" example:"Jane Doe"`
+	Email     string `json:"email" example:"jane.doe@example.com"`
+	TierID    uint   `json:"tier_id" example:"1"`
+	Blocked   bool   `json:"blocked,omitempty" example:"false"`
+	ExpiresOn string `json:"expires_on,omitempty" example:"2027-12-31"`
+}
+
+// Swagger model documentation
+type TierRequest struct {
+	Name          string `json:"name" example:"student"`
+	MaxLoans      int    `json:"max_loans" example:"3"`
+	LoanDays      int    `json:"loan_days" example:"21"`
+	FinePerDay    int64  `json:"fine_per_day" example:"15"`
+	FineGraceDays int    `json:"fine_grace_days" example:"2"`
+	FineCap       int64  `json:"fine_cap" example:"750"`
+	FineThreshold int64  `json:"fine_threshold" example:"500"`
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTierFine(t *testing.T) {
+	tier := Tier{FinePerDay: 25, FineGraceDays: 2, FineCap: 200}
+	due := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	tests := []struct {
+		name     string
+		tier     Tier
+		late     time.Duration
+		daysLate int
+		amount   int64
+	}{
+		{"early", tier, -time.Hour, 0, 0},
+		{"on time", tier, 0, 0, 0},
+		{"a minute late", tier, time.Minute, 1, 0},
+		{"last day of grace", tier, 2 * day, 2, 0},
+		{"first day past grace", tier, 2*day + time.Second, 3, 25},
+		{"a week late", tier, 7 * day, 7, 125},
+		{"just under the cap", tier, 9 * day, 9, 175},
+		{"at the cap", tier, 10 * day, 10, 200},
+		{"past the cap", tier, 30 * day, 30, 200},
+		{"no grace", Tier{FinePerDay: 25}, time.Hour, 1, 25},
+		{"no cap", Tier{FinePerDay: 25, FineGraceDays: 2}, 30 * day, 30, 700},
+		{"no fines", Tier{FineGraceDays: 2}, 30 * day, 30, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			daysLate, amount := tt.tier.Fine(due, due.Add(tt.late))
+			if daysLate != tt.daysLate || amount != tt.amount {
+				t.Errorf("Fine: got %d days, %d; want %d days, %d", daysLate, amount, tt.daysLate, tt.amount)
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+)
+
+// OutboxEvent is an event waiting to be delivered to the publisher. Rows are
+// written in the same transaction as the change they describe and relayed
+// afterwards, so an event is never lost once the change has committed.
+type OutboxEvent struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	Topic         string     `gorm:"not null" json:"topic"`
+	TenantID      string     `gorm:"size:63;not null;default:'default';index" json:"tenant_id"`
+	EventType     string     `gorm:"not null" json:"event_type"`
+	Message       string     `gorm:"type:text;not null" json:"message"`
+	Attempts      int        `gorm:"not null;default:0" json:"attempts"`
+	LastError     string     `json:"last_error,omitempty"`
+	NextAttemptAt time.Time  `gorm:"index" json:"next_attempt_at"`
+	SentAt        *time.Time `gorm:"index" json:"sent_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
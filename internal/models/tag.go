@@ -0,0 +1,33 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	ErrDuplicateTag = errors.New("tag already exists")
+	ErrTagInUse     = errors.New("tag is still attached to books")
+)
+
+// Tag is a genre or free-form label from a tenant's managed vocabulary.
+// Books carry any number of their tenant's tags through the book_tags join
+// table.
+type Tag struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	TenantID  string    `gorm:"size:63;not null;default:'default';uniqueIndex:idx_tags_tenant_name,priority:1" json:"tenant_id"`
+	Name      string    `gorm:"not null;uniqueIndex:idx_tags_tenant_name,priority:2" json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Swagger model documentation
+type TagRequest struct {
+	Name string `json:"name" example:"programming"`
+}
+
+// NormalizeTagName is the canonical form tags are stored and matched in.
+func NormalizeTagName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
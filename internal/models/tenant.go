@@ -0,0 +1,23 @@
+package models
+
+import (
+	"errors"
+	"regexp"
+)
+
+// DefaultTenant owns every book created before catalogues were split by
+// tenant, and is used for requests that don't name one.
+const DefaultTenant = "default"
+
+// ErrNoTenant is returned by book stores that are not bound to a tenant.
+var ErrNoTenant = errors.New("no tenant selected")
+
+// tenantPattern keeps tenant IDs usable as DNS labels, so the same ID works
+// in a header, a subdomain and a cache key.
+var tenantPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// ValidTenantID reports whether id is a well-formed tenant ID: lowercase
+// letters, digits and inner hyphens, at most 63 characters.
+func ValidTenantID(id string) bool {
+	return tenantPattern.MatchString(id)
+}
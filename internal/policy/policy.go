@@ -0,0 +1,206 @@
+// Package policy decides which roles may perform which operations. Roles
+// and their permissions are loaded from config, so deployments can adjust
+// them without a release.
+package policy
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/shani34/book-management-system/internal/models"
+)
+
+// Permission names an operation on a resource.
+type Permission string
+
+// Book permissions.
+const (
+	BookRead   Permission = "book:read"
+	BookCreate Permission = "book:create"
+	BookUpdate Permission = "book:update"
+	BookDelete Permission = "book:delete"
+	BookPurge  Permission = "book:purge"
+)
+
+// Catalogue permissions. Editions are part of their book and need
+// BookUpdate.
+const (
+	AuthorWrite Permission = "author:write"
+	TagWrite    Permission = "tag:write"
+)
+
+// Circulation permissions.
+const (
+	CopyWrite   Permission = "copy:write"
+	LoanWrite   Permission = "loan:write"
+	HoldWrite   Permission = "hold:write"
+	MemberWrite Permission = "member:write"
+	TierWrite   Permission = "tier:write"
+	FinePay     Permission = "fine:pay"
+	FineWaive   Permission = "fine:waive"
+)
+
+// Wildcard in a role's permission list grants every permission.
+const Wildcard Permission = "*"
+
+// Built-in role names. Anonymous is the role of callers who sent no
+// credentials; the others are only defaults and can be redefined.
+const (
+	RoleAnonymous = "anonymous"
+	RoleReader    = "reader"
+	RoleLibrarian = "librarian"
+	RoleAdmin     = "admin"
+)
+
+// DefaultRoles lets readers read; librarians also create and update books
+// and run the desk: authors, tags, copies, loans, holds, members and fine
+// payments. Only admins delete and purge books, change tiers and waive
+// fines.
+const DefaultRoles = "anonymous=book:read;reader=book:read;" +
+	"librarian=book:read,book:create,book:update,author:write,tag:write,copy:write,loan:write,hold:write,member:write,fine:pay;" +
+	"admin=*"
+
+// Reasons a request is denied, reported to clients in the "reason" field.
+const (
+	// ReasonNoRole: none of the caller's roles is defined by the policy.
+	ReasonNoRole = "no_role"
+	// ReasonMissingPermission: no role of the caller grants the permission.
+	ReasonMissingPermission = "missing_permission"
+)
+
+// Policy maps roles to the permissions they grant.
+type Policy struct {
+	roles map[string][]Permission
+	// defaultRole is assumed for authenticated callers who carry no role.
+	defaultRole string
+}
+
+// Parse reads a policy in the form "role=perm,perm;role=perm", as in
+// DefaultRoles. defaultRole, if set, must be one of the roles.
+func Parse(spec, defaultRole string) (*Policy, error) {
+	p := &Policy{roles: make(map[string][]Permission), defaultRole: defaultRole}
+	for _, entry := range strings.Split(spec, ";") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		role, perms, found := strings.Cut(entry, "=")
+		role = strings.TrimSpace(role)
+		if !found || role == "" {
+			return nil, fmt.Errorf("policy entry %q: want role=permission,...", entry)
+		}
+		if _, ok := p.roles[role]; ok {
+			return nil, fmt.Errorf("policy entry %q: role %s is defined twice", entry, role)
+		}
+		granted := []Permission{}
+		for _, perm := range strings.Split(perms, ",") {
+			if perm = strings.TrimSpace(perm); perm != "" {
+				granted = append(granted, Permission(perm))
+			}
+		}
+		p.roles[role] = granted
+	}
+	if defaultRole != "" {
+		if _, ok := p.roles[defaultRole]; !ok {
+			return nil, fmt.Errorf("default role %s is not defined by the policy", defaultRole)
+		}
+	}
+	return p, nil
+}
+
+// Roles lists the roles the policy defines, sorted.
+func (p *Policy) Roles() []string {
+	roles := make([]string, 0, len(p.roles))
+	for role := range p.roles {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+	return roles
+}
+
+// DefaultRole is the role assumed for authenticated callers without one.
+func (p *Policy) DefaultRole() string {
+	return p.defaultRole
+}
+
+// Denial explains why a check failed.
+type Denial struct {
+	Reason     string
+	Permission Permission
+	Roles      []string
+}
+
+func (d *Denial) Error() string {
+	if d.Reason == ReasonNoRole {
+		return fmt.Sprintf("no recognised role among [%s]", strings.Join(d.Roles, ", "))
+	}
+	return fmt.Sprintf("roles [%s] do not grant %s", strings.Join(d.Roles, ", "), d.Permission)
+}
+
+// Check returns nil if any of roles grants perm, and the reason it is
+// denied otherwise.
+func (p *Policy) Check(roles []string, perm Permission) *Denial {
+	known := false
+	for _, role := range roles {
+		granted, ok := p.roles[role]
+		if !ok {
+			continue
+		}
+		known = true
+		if slices.Contains(granted, perm) || slices.Contains(granted, Wildcard) {
+			return nil
+		}
+	}
+	reason := ReasonMissingPermission
+	if !known {
+		reason = ReasonNoRole
+	}
+	return &Denial{Reason: reason, Permission: perm, Roles: roles}
+}
+
+// Scopes gives a caller with roles but no scopes, a token without a scope
+// claim, the scopes its roles need: admin if a role grants every
+// permission, books:write if one grants anything beyond reading, and
+// books:read if one can read. A caller without roles has the default role.
+func (p *Policy) Scopes(roles []string) models.Scopes {
+	if len(roles) == 0 && p.defaultRole != "" {
+		roles = []string{p.defaultRole}
+	}
+	read, write := false, false
+	for _, role := range roles {
+		for _, perm := range p.roles[role] {
+			switch perm {
+			case Wildcard:
+				return models.Scopes{models.ScopeAdmin}
+			case BookRead:
+				read = true
+			default:
+				write = true
+			}
+		}
+	}
+	scopes := models.Scopes{}
+	if read {
+		scopes = append(scopes, models.ScopeBooksRead)
+	}
+	if write {
+		scopes = append(scopes, models.ScopeBooksWrite)
+	}
+	return scopes
+}
+
+// RolesForScopes gives an API key, which carries scopes rather than roles,
+// the role matching its broadest scope.
+func RolesForScopes(scopes models.Scopes) []string {
+	switch {
+	case slices.Contains(scopes, models.ScopeAdmin):
+		return []string{RoleAdmin}
+	case slices.Contains(scopes, models.ScopeBooksWrite):
+		return []string{RoleLibrarian}
+	case slices.Contains(scopes, models.ScopeBooksRead):
+		return []string{RoleReader}
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,132 @@
+package policy
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/shani34/book-management-system/internal/models"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name        string
+		spec        string
+		defaultRole string
+		roles       []string
+		ok          bool
+	}{
+		{"defaults", DefaultRoles, RoleReader, []string{"admin", "anonymous", "librarian", "reader"}, true},
+		{"spaces and empty entries", " a = book:read , ; ;b=", "", []string{"a", "b"}, true},
+		{"empty", "", "", []string{}, true},
+		{"no permissions list", "a", "", nil, false},
+		{"no role name", "=book:read", "", nil, false},
+		{"role twice", "a=book:read;a=book:create", "", nil, false},
+		{"undefined default role", "a=book:read", "b", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := Parse(tt.spec, tt.defaultRole)
+			if (err == nil) != tt.ok {
+				t.Fatalf("Parse: got %v, want ok %v", err, tt.ok)
+			}
+			if err == nil && !slices.Equal(p.Roles(), tt.roles) {
+				t.Errorf("Roles: got %v, want %v", p.Roles(), tt.roles)
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	p, err := Parse(DefaultRoles, RoleReader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		roles  []string
+		perm   Permission
+		reason string
+	}{
+		{"anonymous reads", []string{RoleAnonymous}, BookRead, ""},
+		{"anonymous writes", []string{RoleAnonymous}, BookCreate, ReasonMissingPermission},
+		{"reader writes", []string{RoleReader}, BookUpdate, ReasonMissingPermission},
+		{"librarian creates", []string{RoleLibrarian}, BookCreate, ""},
+		{"librarian lends", []string{RoleLibrarian}, LoanWrite, ""},
+		{"librarian deletes", []string{RoleLibrarian}, BookDelete, ReasonMissingPermission},
+		{"librarian waives", []string{RoleLibrarian}, FineWaive, ReasonMissingPermission},
+		{"admin purges", []string{RoleAdmin}, BookPurge, ""},
+		{"any role grants", []string{RoleReader, RoleAdmin}, TierWrite, ""},
+		{"unknown role alongside a known one", []string{"janitor", RoleReader}, BookCreate, ReasonMissingPermission},
+		{"unknown role", []string{"janitor"}, BookRead, ReasonNoRole},
+		{"no roles", nil, BookRead, ReasonNoRole},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			denial := p.Check(tt.roles, tt.perm)
+			switch {
+			case tt.reason == "" && denial != nil:
+				t.Errorf("Check: denied %v, want allowed", denial)
+			case tt.reason != "" && (denial == nil || denial.Reason != tt.reason || denial.Permission != tt.perm):
+				t.Errorf("Check: got %+v, want denied with %s", denial, tt.reason)
+			}
+		})
+	}
+}
+
+func TestScopes(t *testing.T) {
+	p, err := Parse(DefaultRoles+";auditor=book:read;clerk=loan:write", RoleReader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	read := models.Scopes{models.ScopeBooksRead}
+	write := models.Scopes{models.ScopeBooksRead, models.ScopeBooksWrite}
+
+	tests := []struct {
+		name  string
+		roles []string
+		want  models.Scopes
+	}{
+		{"reader", []string{RoleReader}, read},
+		{"librarian", []string{RoleLibrarian}, write},
+		{"admin", []string{RoleAdmin}, models.Scopes{models.ScopeAdmin}},
+		{"broadest role wins", []string{RoleReader, RoleLibrarian}, write},
+		{"wildcard wins", []string{RoleLibrarian, RoleAdmin}, models.Scopes{models.ScopeAdmin}},
+		{"write without read", []string{"clerk"}, models.Scopes{models.ScopeBooksWrite}},
+		{"write and read from two roles", []string{"clerk", "auditor"}, write},
+		{"no roles gets the default role", nil, read},
+		{"unknown role", []string{"janitor"}, models.Scopes{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := p.Scopes(tt.roles); !slices.Equal(got, tt.want) {
+				t.Errorf("Scopes(%v): got %v, want %v", tt.roles, got, tt.want)
+			}
+		})
+	}
+
+	noDefault, err := Parse(DefaultRoles, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := noDefault.Scopes(nil); len(got) != 0 {
+		t.Errorf("Scopes without roles or a default role: got %v, want none", got)
+	}
+}
+
+func TestRolesForScopes(t *testing.T) {
+	tests := []struct {
+		scopes models.Scopes
+		want   []string
+	}{
+		{models.Scopes{models.ScopeBooksRead}, []string{RoleReader}},
+		{models.Scopes{models.ScopeBooksRead, models.ScopeBooksWrite}, []string{RoleLibrarian}},
+		{models.Scopes{models.ScopeBooksWrite, models.ScopeAdmin}, []string{RoleAdmin}},
+		{models.Scopes{}, nil},
+	}
+	for _, tt := range tests {
+		if got := RolesForScopes(tt.scopes); !slices.Equal(got, tt.want) {
+			t.Errorf("RolesForScopes(%v): got %v, want %v", tt.scopes, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/shani34/book-management-system/internal/models"
+	"gorm.io/gorm"
+)
+
+type APIKeyRepository struct {
+	db     *gorm.DB
+	tenant string
+}
+
+// NewAPIKeyRepository returns a store that sees every key; see ForTenant.
+func NewAPIKeyRepository(db *gorm.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+func (r *APIKeyRepository) ForTenant(tenantID string) APIKeyStore {
+	return &APIKeyRepository{db: r.db, tenant: tenantID}
+}
+
+// keys starts every query on the api_keys table, limited to the tenant
+// when the store is bound to one.
+func (r *APIKeyRepository) keys() *gorm.DB {
+	query := r.db.Model(&models.APIKey{})
+	if r.tenant != "" {
+		query = query.Where("tenant_id = ?", r.tenant)
+	}
+	return query
+}
+
+func (r *APIKeyRepository) GetAll(limit, offset int) ([]models.APIKey, error) {
+	keys := []models.APIKey{}
+	result := r.keys().Order("id DESC").Limit(limit).Offset(offset).Find(&keys)
+	return keys, result.Error
+}
+
+func (r *APIKeyRepository) GetByID(id uint) (*models.APIKey, error) {
+	var key models.APIKey
+	if err := r.keys().First(&key, id).Error; err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *APIKeyRepository) GetByPrefix(prefix string) (*models.APIKey, error) {
+	var key models.APIKey
+	if err := r.keys().Where("prefix = ?", prefix).First(&key).Error; err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *APIKeyRepository) Create(key *models.APIKey) error {
+	return r.db.Create(key).Error
+}
+
+func (r *APIKeyRepository) Revoke(id uint, at time.Time) error {
+	result := r.keys().
+		Where("id = ? AND revoked_at IS NULL", id).
+		Updates(map[string]interface{}{"revoked_at": at, "updated_at": at})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		if _, err := r.GetByID(id); err != nil {
+			return err
+		}
+		return models.ErrAPIKeyRevoked
+	}
+	return nil
+}
+
+// Touch leaves updated_at alone: being used is not a change to the key.
+func (r *APIKeyRepository) Touch(id uint, at time.Time) error {
+	return r.keys().Where("id = ?", id).UpdateColumn("last_used_at", at).Error
+}
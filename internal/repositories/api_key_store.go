@@ -0,0 +1,33 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/shani34/book-management-system/internal/models"
+)
+
+// APIKeyStore persists API keys. Keys are never deleted, only revoked, so
+// the record of what a key did stays attributable. Missing rows are
+// reported as gorm.ErrRecordNotFound.
+//
+// Unlike the catalogue stores, a store bound to no tenant sees every key:
+// it serves the unbound admins who manage keys for all tenants.
+type APIKeyStore interface {
+	// ForTenant returns a store that only sees keys bound to tenantID.
+	ForTenant(tenantID string) APIKeyStore
+	// GetAll lists keys newest first, revoked ones included.
+	GetAll(limit, offset int) ([]models.APIKey, error)
+	GetByID(id uint) (*models.APIKey, error)
+	GetByPrefix(prefix string) (*models.APIKey, error)
+	Create(key *models.APIKey) error
+	// Revoke sets RevokedAt on a key that isn't revoked yet; revoking it
+	// again returns models.ErrAPIKeyRevoked.
+	Revoke(id uint, at time.Time) error
+	// Touch records that the key was used at at.
+	Touch(id uint, at time.Time) error
+}
+
+var (
+	_ APIKeyStore = (*APIKeyRepository)(nil)
+	_ APIKeyStore = (*MemoryAPIKeyRepository)(nil)
+)
@@ -0,0 +1,108 @@
+package repositories
+
+import (
+	"errors"
+	"github.com/shani34/book-management-system/internal/models"
+	"gorm.io/gorm"
+)
+
+type AuthorRepository struct {
+	db     *gorm.DB
+	tenant string
+}
+
+// NewAuthorRepository returns a store bound to no tenant; see ForTenant.
+func NewAuthorRepository(db *gorm.DB) *AuthorRepository {
+	return &AuthorRepository{db: db}
+}
+
+func (r *AuthorRepository) ForTenant(tenantID string) AuthorStore {
+	return &AuthorRepository{db: r.db, tenant: tenantID}
+}
+
+func (r *AuthorRepository) scoped() *gorm.DB {
+	return r.db.Where("authors.tenant_id = ?", r.tenant)
+}
+
+func (r *AuthorRepository) GetAll(limit, offset int) ([]models.Author, error) {
+	var authors []models.Author
+	result := r.scoped().Order("name, id").Limit(limit).Offset(offset).Find(&authors)
+	return authors, result.Error
+}
+
+func (r *AuthorRepository) GetByID(id uint) (*models.Author, error) {
+	var author models.Author
+	result := r.scoped().First(&author, id)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &author, nil
+}
+
+func (r *AuthorRepository) Create(author *models.Author) error {
+	if r.tenant == "" {
+		return models.ErrNoTenant
+	}
+	author.TenantID = r.tenant
+	return translateAuthorError(r.db.Create(author).Error)
+}
+
+func (r *AuthorRepository) Update(author *models.Author) error {
+	author.TenantID = r.tenant
+	result := r.db.Model(author).Where("tenant_id = ?", r.tenant).Select("name", "updated_at").Updates(author)
+	if result.Error != nil {
+		return translateAuthorError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *AuthorRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var author models.Author
+		if err := tx.Where("tenant_id = ?", r.tenant).Select("id").First(&author, id).Error; err != nil {
+			return err
+		}
+		var linked int64
+		if err := tx.Table("book_authors").Where("author_id = ?", id).Count(&linked).Error; err != nil {
+			return err
+		}
+		if linked > 0 {
+			return models.ErrAuthorHasBooks
+		}
+
+		result := tx.Delete(&models.Author{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+// GetBooks lists the tenant's live books linked to the author, oldest
+// first.
+func (r *AuthorRepository) GetBooks(authorID uint, limit, offset int) ([]models.Book, error) {
+	var books []models.Book
+	result := r.db.
+		Joins("JOIN book_authors ON book_authors.book_id = books.id").
+		Where("book_authors.author_id = ? AND books.tenant_id = ?", authorID, r.tenant).
+		Preload("Authors").
+		Preload("Tags").
+		Order("books.id").
+		Limit(limit).
+		Offset(offset).
+		Find(&books)
+	return books, result.Error
+}
+
+func translateAuthorError(err error) error {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return models.ErrDuplicateAuthor
+	}
+	return err
+}
@@ -0,0 +1,28 @@
+package repositories
+
+import "github.com/shani34/book-management-system/internal/models"
+
+// AuthorStore persists authors. Like BookStore, missing rows are reported
+// as gorm.ErrRecordNotFound, and the store only sees the authors of the
+// tenant it is bound to.
+type AuthorStore interface {
+	// ForTenant returns a store bound to tenantID.
+	ForTenant(tenantID string) AuthorStore
+
+	GetAll(limit, offset int) ([]models.Author, error)
+	GetByID(id uint) (*models.Author, error)
+	// Create and Update return models.ErrDuplicateAuthor when the name is
+	// already taken in the tenant.
+	Create(author *models.Author) error
+	Update(author *models.Author) error
+	// Delete refuses with models.ErrAuthorHasBooks while books still link
+	// to the author.
+	Delete(id uint) error
+	// GetBooks lists the live books linked to the author.
+	GetBooks(authorID uint, limit, offset int) ([]models.Book, error)
+}
+
+var (
+	_ AuthorStore = (*AuthorRepository)(nil)
+	_ AuthorStore = (*MemoryAuthorRepository)(nil)
+)
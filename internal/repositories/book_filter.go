@@ -0,0 +1,99 @@
+package repositories
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/shani34/book-management-system/internal/models"
+	"gorm.io/gorm"
+)
+
+// filterBooks adds the WHERE clauses for filter.
+func filterBooks(db *gorm.DB, filter models.BookFilter) *gorm.DB {
+	if filter.Author != "" {
+		db = db.Where("author = ?", filter.Author)
+	}
+	if filter.AuthorContains != "" {
+		db = db.Where("author ILIKE ?", "%"+escapeLike(filter.AuthorContains)+"%")
+	}
+	if filter.TitlePrefix != "" {
+		db = db.Where("title ILIKE ?", escapeLike(filter.TitlePrefix)+"%")
+	}
+	if filter.YearFrom != nil {
+		db = db.Where("year >= ?", *filter.YearFrom)
+	}
+	if filter.YearTo != nil {
+		db = db.Where("year <= ?", *filter.YearTo)
+	}
+	if len(filter.Tags) > 0 {
+		tags := uniqueTagNames(filter.Tags)
+		if filter.AllTags {
+			db = db.Where(`id IN (SELECT book_tags.book_id FROM book_tags
+				JOIN tags ON tags.id = book_tags.tag_id
+				WHERE tags.name IN ?
+				GROUP BY book_tags.book_id
+				HAVING COUNT(DISTINCT tags.id) = ?)`, tags, len(tags))
+		} else {
+			db = db.Where(`id IN (SELECT book_tags.book_id FROM book_tags
+				JOIN tags ON tags.id = book_tags.tag_id
+				WHERE tags.name IN ?)`, tags)
+		}
+	}
+	return db
+}
+
+// uniqueTagNames drops repeated names so "all" counts each tag once.
+func uniqueTagNames(names []string) []string {
+	unique := slices.Clone(names)
+	slices.Sort(unique)
+	return slices.Compact(unique)
+}
+
+// orderBooks adds the ORDER BY for filter, or its reverse. Ties on the sort
+// column are broken by id so paging is stable.
+func orderBooks(db *gorm.DB, filter models.BookFilter, reverse bool) *gorm.DB {
+	direction := " ASC"
+	if filter.Desc != reverse {
+		direction = " DESC"
+	}
+	if column, ok := models.BookSortFields[filter.Sort]; ok {
+		db = db.Order(column + direction)
+	}
+	return db.Order("id" + direction)
+}
+
+// seekBooks restricts the query to rows strictly after cursor in the
+// direction being read, comparing (sort column, id) as a row value so the
+// index on the sort column can be used.
+func seekBooks(db *gorm.DB, filter models.BookFilter, cursor *models.BookCursor) (*gorm.DB, error) {
+	if cursor == nil {
+		return db, nil
+	}
+	op := ">"
+	if filter.Desc != cursor.Before {
+		op = "<"
+	}
+
+	position, err := cursor.Position()
+	if err != nil {
+		return nil, models.ErrInvalidCursor
+	}
+	switch filter.Sort {
+	case "title":
+		return db.Where("(title, id) "+op+" (?, ?)", position.Title, position.ID), nil
+	case "author":
+		return db.Where("(author, id) "+op+" (?, ?)", position.Author, position.ID), nil
+	case "year":
+		return db.Where("(year, id) "+op+" (?, ?)", position.Year, position.ID), nil
+	case "created_at":
+		return db.Where("(created_at, id) "+op+" (?, ?)", position.CreatedAt, position.ID), nil
+	default:
+		return db.Where("id "+op+" ?", position.ID), nil
+	}
+}
+
+// escapeLike escapes the LIKE wildcards in user input so they match
+// literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -4,45 +4,361 @@ import (
 	"errors"
 	"github.com/shani34/book-management-system/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"slices"
+	"time"
 )
 
 type BookRepository struct {
-	db *gorm.DB
+	db     *gorm.DB
+	tenant string
 }
 
+// NewBookRepository returns a store bound to no tenant; see ForTenant.
 func NewBookRepository(db *gorm.DB) *BookRepository {
 	return &BookRepository{db: db}
 }
 
-func (r *BookRepository) GetAll(limit, offset int) ([]models.Book, error) {
+func (r *BookRepository) ForTenant(tenantID string) BookStore {
+	return &BookRepository{db: r.db, tenant: tenantID}
+}
+
+// books starts every query on the books table, limited to the tenant.
+func (r *BookRepository) books() *gorm.DB {
+	return r.db.Model(&models.Book{}).Where("books.tenant_id = ?", r.tenant)
+}
+
+func (r *BookRepository) GetAll(filter models.BookFilter, limit, offset int) ([]models.Book, error) {
 	var books []models.Book
-	result := r.db.Limit(limit).Offset(offset).Find(&books)
+	result := orderBooks(filterBooks(r.books(), filter), filter, false).Preload("Authors").Preload("Tags").Limit(limit).Offset(offset).Find(&books)
 	return books, result.Error
 }
 
+// GetPage reads up to limit books following cursor, or preceding it when
+// cursor.Before is set. Either way the books come back in listing order.
+func (r *BookRepository) GetPage(filter models.BookFilter, cursor *models.BookCursor, limit int) ([]models.Book, error) {
+	query, err := seekBooks(filterBooks(r.books(), filter), filter, cursor)
+	if err != nil {
+		return nil, err
+	}
+	backwards := cursor != nil && cursor.Before
+
+	var books []models.Book
+	if err := orderBooks(query, filter, backwards).Preload("Authors").Preload("Tags").Limit(limit).Find(&books).Error; err != nil {
+		return nil, err
+	}
+	if backwards {
+		slices.Reverse(books)
+	}
+	return books, nil
+}
+
+func (r *BookRepository) Count(filter models.BookFilter) (int64, error) {
+	var total int64
+	result := filterBooks(r.books(), filter).Count(&total)
+	return total, result.Error
+}
+
+// Each reads the result set row by row off the connection rather than
+// loading it into memory, so it can walk the whole table.
+func (r *BookRepository) Each(filter models.BookFilter, fn func(book *models.Book) error) error {
+	rows, err := orderBooks(filterBooks(r.books(), filter), filter, false).Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var book models.Book
+		if err := r.db.ScanRows(rows, &book); err != nil {
+			return err
+		}
+		if err := fn(&book); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
 func (r *BookRepository) GetByID(id uint) (*models.Book, error) {
 	var book models.Book
-	result := r.db.First(&book, id)
+	result := r.books().Preload("Authors").Preload("Tags").Preload("Editions", orderEditions).First(&book, id)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, result.Error
+	}
+	return &book, result.Error
+}
+
+// GetByISBN13 finds the live book with the given normalized ISBN-13.
+func (r *BookRepository) GetByISBN13(isbn13 string) (*models.Book, error) {
+	var book models.Book
+	result := r.books().Preload("Authors").Preload("Tags").Preload("Editions", orderEditions).Where("isbn13 = ?", isbn13).First(&book)
 	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 		return nil, result.Error
 	}
 	return &book, result.Error
 }
 
+func orderEditions(db *gorm.DB) *gorm.DB {
+	return db.Order("editions.id")
+}
+
 func (r *BookRepository) Create(book *models.Book) error {
-	result := r.db.Create(book)
-	return result.Error
+	if r.tenant == "" {
+		return models.ErrNoTenant
+	}
+	book.TenantID = r.tenant
+	result := r.db.Omit(clause.Associations).Create(book)
+	return translateBookError(result.Error)
 }
 
 func (r *BookRepository) Update(book *models.Book) error {
-	result := r.db.Save(book)
-	return result.Error
+	expected := book.Version
+	book.Version++
+	book.TenantID = r.tenant
+	result := r.db.Model(book).Where("tenant_id = ? AND version = ?", r.tenant, expected).Select("*").Omit(clause.Associations).Updates(book)
+	if result.Error != nil {
+		book.Version = expected
+		return translateBookError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		book.Version = expected
+		return models.ErrVersionConflict
+	}
+	return nil
+}
+
+// translateBookError maps unique violations onto models.ErrDuplicateISBN;
+// the per-tenant ISBN indexes are the only unique constraints on books
+// besides the primary key.
+func translateBookError(err error) error {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return models.ErrDuplicateISBN
+	}
+	return err
 }
 
-func (r *BookRepository) Delete(id uint) error {
-	result := r.db.Delete(&models.Book{}, id)
+func (r *BookRepository) Delete(id uint, version uint) error {
+	query := r.db.Where("tenant_id = ?", r.tenant)
+	if version != 0 {
+		query = query.Where("version = ?", version)
+	}
+	result := query.Delete(&models.Book{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		if _, err := r.GetByID(id); err != nil {
+			return err
+		}
+		return models.ErrVersionConflict
+	}
+	return nil
+}
+
+// ListDeleted returns the trash, most recently deleted first.
+func (r *BookRepository) ListDeleted(limit, offset int) ([]models.Book, error) {
+	var books []models.Book
+	result := r.db.Unscoped().
+		Where("tenant_id = ? AND deleted_at IS NOT NULL", r.tenant).
+		Order("deleted_at DESC, id").
+		Limit(limit).
+		Offset(offset).
+		Find(&books)
+	return books, result.Error
+}
+
+func (r *BookRepository) Restore(id uint) (*models.Book, error) {
+	result := r.db.Unscoped().
+		Model(&models.Book{}).
+		Where("id = ? AND tenant_id = ? AND deleted_at IS NOT NULL", id, r.tenant).
+		Updates(map[string]interface{}{
+			"deleted_at": nil,
+			"version":    gorm.Expr("version + 1"),
+			"updated_at": time.Now(),
+		})
+	if result.Error != nil {
+		return nil, result.Error
+	}
 	if result.RowsAffected == 0 {
-		return gorm.ErrRecordNotFound
+		return nil, gorm.ErrRecordNotFound
 	}
-	return result.Error
-}
\ No newline at end of file
+	return r.GetByID(id)
+}
+
+func (r *BookRepository) Purge(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var trashed int64
+		if err := tx.Unscoped().Model(&models.Book{}).Where("id = ? AND tenant_id = ? AND deleted_at IS NOT NULL", id, r.tenant).Count(&trashed).Error; err != nil {
+			return err
+		}
+		if trashed == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		var copies int64
+		if err := tx.Model(&models.Copy{}).Where("book_id = ?", id).Count(&copies).Error; err != nil {
+			return err
+		}
+		if copies > 0 {
+			return models.ErrBookHasCopies
+		}
+		if err := tx.Exec("DELETE FROM book_authors WHERE book_id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM book_tags WHERE book_id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM editions WHERE book_id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM holds WHERE book_id = ?", id).Error; err != nil {
+			return err
+		}
+		result := tx.Unscoped().Where("tenant_id = ? AND deleted_at IS NOT NULL", r.tenant).Delete(&models.Book{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+// EnsureAuthor inserts with ON CONFLICT DO NOTHING so concurrent callers
+// racing on the same name all end up with the one row.
+func (r *BookRepository) EnsureAuthor(name string) (*models.Author, error) {
+	if r.tenant == "" {
+		return nil, models.ErrNoTenant
+	}
+	author := models.Author{TenantID: r.tenant, Name: name}
+	if err := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&author).Error; err != nil {
+		return nil, err
+	}
+	if author.ID == 0 {
+		if err := r.db.Where("tenant_id = ? AND name = ?", r.tenant, name).First(&author).Error; err != nil {
+			return nil, err
+		}
+	}
+	return &author, nil
+}
+
+func (r *BookRepository) SetBookAuthors(bookID uint, authorIDs []uint) ([]models.Author, error) {
+	authors := []models.Author{}
+	if len(authorIDs) > 0 {
+		if err := r.db.Where("tenant_id = ? AND id IN ?", r.tenant, authorIDs).Order("id").Find(&authors).Error; err != nil {
+			return nil, err
+		}
+		if len(authors) != len(uniqueIDs(authorIDs)) {
+			return nil, gorm.ErrRecordNotFound
+		}
+	}
+
+	if err := r.db.Exec("DELETE FROM book_authors WHERE book_id = ?", bookID).Error; err != nil {
+		return nil, err
+	}
+	for _, author := range authors {
+		if err := r.db.Exec("INSERT INTO book_authors (book_id, author_id) VALUES (?, ?)", bookID, author.ID).Error; err != nil {
+			return nil, err
+		}
+	}
+	return authors, nil
+}
+
+func (r *BookRepository) AttachTag(bookID, tagID uint) ([]models.Tag, error) {
+	if err := r.db.Where("tenant_id = ?", r.tenant).First(&models.Tag{}, tagID).Error; err != nil {
+		return nil, err
+	}
+	if err := r.db.Exec("INSERT INTO book_tags (book_id, tag_id) VALUES (?, ?) ON CONFLICT DO NOTHING", bookID, tagID).Error; err != nil {
+		return nil, err
+	}
+	return r.bookTags(bookID)
+}
+
+func (r *BookRepository) DetachTag(bookID, tagID uint) ([]models.Tag, error) {
+	if err := r.db.Exec("DELETE FROM book_tags WHERE book_id = ? AND tag_id = ?", bookID, tagID).Error; err != nil {
+		return nil, err
+	}
+	return r.bookTags(bookID)
+}
+
+func (r *BookRepository) bookTags(bookID uint) ([]models.Tag, error) {
+	tags := []models.Tag{}
+	result := r.db.
+		Joins("JOIN book_tags ON book_tags.tag_id = tags.id").
+		Where("book_tags.book_id = ?", bookID).
+		Order("tags.id").
+		Find(&tags)
+	return tags, result.Error
+}
+
+func (r *BookRepository) Editions() EditionStore {
+	return NewEditionRepository(r.db).ForTenant(r.tenant)
+}
+
+func (r *BookRepository) Authors() AuthorStore {
+	return NewAuthorRepository(r.db).ForTenant(r.tenant)
+}
+
+func (r *BookRepository) Tags() TagStore {
+	return NewTagRepository(r.db).ForTenant(r.tenant)
+}
+
+func (r *BookRepository) LockBooksByAuthor(authorID uint) ([]models.Book, error) {
+	return r.lockLinkedBooks("book_authors", "author_id", authorID)
+}
+
+func (r *BookRepository) LockBooksByTag(tagID uint) ([]models.Book, error) {
+	return r.lockLinkedBooks("book_tags", "tag_id", tagID)
+}
+
+// lockLinkedBooks locks the tenant's live books with a row in the join
+// table whose column is id.
+func (r *BookRepository) lockLinkedBooks(table, column string, id uint) ([]models.Book, error) {
+	books := []models.Book{}
+	result := r.books().
+		Joins("JOIN "+table+" ON "+table+".book_id = books.id").
+		Where(table+"."+column+" = ?", id).
+		Clauses(clause.Locking{Strength: "UPDATE", Table: clause.Table{Name: "books"}}).
+		Order("books.id").
+		Find(&books)
+	return books, result.Error
+}
+
+func uniqueIDs(ids []uint) map[uint]bool {
+	set := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set
+}
+
+// searchQuery parses user input the way web search boxes do: quoted phrases,
+// OR and -exclusions are understood, and bad syntax never errors.
+const searchQuery = "websearch_to_tsquery('english', ?)"
+
+const headlineOptions = "StartSel=<mark>, StopSel=</mark>, HighlightAll=true"
+
+// Search ranks books against the search_vector column created in db.InitDB.
+func (r *BookRepository) Search(query string, limit, offset int) ([]models.BookSearchResult, error) {
+	var results []models.BookSearchResult
+	result := r.books().
+		Select("books.*, "+
+			"ts_rank(search_vector, "+searchQuery+") AS rank, "+
+			"ts_headline('english', title, "+searchQuery+", ?) AS title_highlight, "+
+			"ts_headline('english', author, "+searchQuery+", ?) AS author_highlight",
+			query, query, headlineOptions, query, headlineOptions).
+		Where("search_vector @@ "+searchQuery, query).
+		Order("rank DESC, books.id").
+		Limit(limit).
+		Offset(offset).
+		Scan(&results)
+	return results, result.Error
+}
+
+func (r *BookRepository) Transaction(fn func(tx BookStore) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&BookRepository{db: tx, tenant: r.tenant})
+	})
+}
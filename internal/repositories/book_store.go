@@ -0,0 +1,100 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/shani34/book-management-system/internal/models"
+)
+
+// BookStore is the persistence contract used by the book service. Lookups of
+// missing rows report gorm.ErrRecordNotFound regardless of the backing store,
+// so callers can keep using errors.Is against it.
+//
+// A store is bound to one tenant and every book query is scoped to it: books
+// of other tenants are reported missing, and Create assigns the tenant. A
+// store not bound to any tenant sees no books and can't create any
+// (models.ErrNoTenant); the outbox methods are not scoped.
+type BookStore interface {
+	OutboxStore
+
+	// ForTenant returns a store bound to tenantID on the same connection or
+	// transaction.
+	ForTenant(tenantID string) BookStore
+
+	GetAll(filter models.BookFilter, limit, offset int) ([]models.Book, error)
+	GetPage(filter models.BookFilter, cursor *models.BookCursor, limit int) ([]models.Book, error)
+	Count(filter models.BookFilter) (int64, error)
+	// Each streams every book matching filter to fn in listing order,
+	// stopping at the first error fn returns.
+	Each(filter models.BookFilter, fn func(book *models.Book) error) error
+	GetByID(id uint) (*models.Book, error)
+	GetByISBN13(isbn13 string) (*models.Book, error)
+	// Create and Update return models.ErrDuplicateISBN when another book
+	// of the tenant, live or trashed, has the same ISBN. Create fails the
+	// same way when book.ID is set and already taken by any tenant's book,
+	// as the primary key is the other unique constraint on books.
+	Create(book *models.Book) error
+	// Update saves book only if the stored row is still at book.Version,
+	// then bumps the version. Otherwise it returns models.ErrVersionConflict.
+	Update(book *models.Book) error
+	// Delete moves the book to the trash if it is at version, or at any
+	// version when version is 0.
+	Delete(id uint, version uint) error
+	ListDeleted(limit, offset int) ([]models.Book, error)
+	// Restore takes a book out of the trash and bumps its version.
+	Restore(id uint) (*models.Book, error)
+	// Purge permanently removes a book that is in the trash. Books with
+	// copies are kept for their loan history: models.ErrBookHasCopies.
+	// Its holds are deleted with it.
+	Purge(id uint) error
+	Search(query string, limit, offset int) ([]models.BookSearchResult, error)
+
+	// EnsureAuthor returns the tenant's author with exactly this name,
+	// creating it if needed.
+	EnsureAuthor(name string) (*models.Author, error)
+	// SetBookAuthors replaces the book's author links and returns the linked
+	// authors. Author IDs unknown to the tenant fail with
+	// gorm.ErrRecordNotFound.
+	SetBookAuthors(bookID uint, authorIDs []uint) ([]models.Author, error)
+	// AttachTag and DetachTag add or remove one tag on a book and return
+	// the book's tags afterwards. Both are idempotent; AttachTag fails with
+	// gorm.ErrRecordNotFound for a tag unknown to the tenant.
+	AttachTag(bookID, tagID uint) ([]models.Tag, error)
+	DetachTag(bookID, tagID uint) ([]models.Tag, error)
+
+	// Editions, Authors and Tags return those stores on the same connection
+	// or transaction and tenant, so a change to them can commit together
+	// with the version bumps and events of the books it touches.
+	Editions() EditionStore
+	Authors() AuthorStore
+	Tags() TagStore
+	// LockBooksByAuthor and LockBooksByTag read the tenant's live books
+	// linked to the author or carrying the tag, in ID order and without
+	// their associations, and inside a transaction lock them until it ends.
+	LockBooksByAuthor(authorID uint) ([]models.Book, error)
+	LockBooksByTag(tagID uint) ([]models.Book, error)
+
+	// Transaction runs fn against a store bound to a single transaction. It
+	// commits when fn returns nil and rolls back otherwise.
+	Transaction(fn func(tx BookStore) error) error
+}
+
+// OutboxStore persists events for the outbox relay.
+type OutboxStore interface {
+	EnqueueEvent(event *models.OutboxEvent) error
+
+	// ClaimEvents returns up to limit due, unsent events in insertion
+	// order and moves their NextAttemptAt lease into the future, so no
+	// other relay claims them while they are published. The claim is
+	// committed before it returns; a relay that dies holding it leaves the
+	// events to be claimed again once the lease runs out.
+	ClaimEvents(limit int, lease time.Duration) ([]models.OutboxEvent, error)
+	// SaveEvents records the outcome of publishing claimed events: their
+	// Attempts, LastError, NextAttemptAt and SentAt.
+	SaveEvents(events []models.OutboxEvent) error
+}
+
+var (
+	_ BookStore = (*BookRepository)(nil)
+	_ BookStore = (*MemoryBookRepository)(nil)
+)
@@ -0,0 +1,313 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"github.com/shani34/book-management-system/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CirculationRepository struct {
+	db     *gorm.DB
+	tenant string
+}
+
+// NewCirculationRepository returns a store bound to no tenant; see ForTenant.
+func NewCirculationRepository(db *gorm.DB) *CirculationRepository {
+	return &CirculationRepository{db: db}
+}
+
+func (r *CirculationRepository) ForTenant(tenantID string) CirculationStore {
+	return &CirculationRepository{db: r.db, tenant: tenantID}
+}
+
+// scoped starts a query limited to the tenant's rows of table.
+func (r *CirculationRepository) scoped(table string) *gorm.DB {
+	return r.db.Where(table+".tenant_id = ?", r.tenant)
+}
+
+func (r *CirculationRepository) GetCopies(bookID uint) ([]models.Copy, error) {
+	copies := []models.Copy{}
+	result := r.scoped("copies").Where("book_id = ?", bookID).Order("id").Find(&copies)
+	return copies, result.Error
+}
+
+func (r *CirculationRepository) GetCopy(id uint) (*models.Copy, error) {
+	var bookCopy models.Copy
+	if err := r.scoped("copies").First(&bookCopy, id).Error; err != nil {
+		return nil, err
+	}
+	return &bookCopy, nil
+}
+
+func (r *CirculationRepository) GetCopyByBarcode(barcode string) (*models.Copy, error) {
+	var bookCopy models.Copy
+	if err := r.scoped("copies").Where("barcode = ?", barcode).First(&bookCopy).Error; err != nil {
+		return nil, err
+	}
+	return &bookCopy, nil
+}
+
+func (r *CirculationRepository) LockCopy(id uint) (*models.Copy, error) {
+	var bookCopy models.Copy
+	result := r.scoped("copies").Clauses(clause.Locking{Strength: "UPDATE"}).First(&bookCopy, id)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &bookCopy, nil
+}
+
+func (r *CirculationRepository) LockCopies(bookID uint) ([]models.Copy, error) {
+	copies := []models.Copy{}
+	result := r.scoped("copies").Clauses(clause.Locking{Strength: "UPDATE"}).Where("book_id = ?", bookID).Order("id").Find(&copies)
+	return copies, result.Error
+}
+
+func (r *CirculationRepository) CreateCopy(bookCopy *models.Copy) error {
+	if r.tenant == "" {
+		return models.ErrNoTenant
+	}
+	bookCopy.TenantID = r.tenant
+	return translateCopyError(r.db.Create(bookCopy).Error)
+}
+
+func (r *CirculationRepository) UpdateCopy(bookCopy *models.Copy) error {
+	result := r.db.Model(bookCopy).Where("tenant_id = ?", r.tenant).Select("barcode", "status", "location", "updated_at").Updates(bookCopy)
+	if result.Error != nil {
+		return translateCopyError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// DeleteCopy removes a copy that has never been lent out; loan history is
+// kept, so copies that have it can only be marked lost.
+func (r *CirculationRepository) DeleteCopy(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var loans int64
+		if err := tx.Model(&models.Loan{}).Where("copy_id = ?", id).Count(&loans).Error; err != nil {
+			return err
+		}
+		if loans > 0 {
+			return models.ErrCopyHasLoans
+		}
+		result := tx.Where("tenant_id = ?", r.tenant).Delete(&models.Copy{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+func (r *CirculationRepository) GetMember(id uint) (*models.Member, error) {
+	var member models.Member
+	if err := r.scoped("members").Preload("Tier").First(&member, id).Error; err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+func (r *CirculationRepository) LockMember(id uint) (*models.Member, error) {
+	var member models.Member
+	result := r.scoped("members").Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Tier").First(&member, id)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &member, nil
+}
+
+func (r *CirculationRepository) CountActiveLoans(memberID uint) (int64, error) {
+	var count int64
+	result := r.scoped("loans").Model(&models.Loan{}).Where("member_id = ? AND returned_at IS NULL", memberID).Count(&count)
+	return count, result.Error
+}
+
+// GetLoans lists loans, most recent checkout first.
+func (r *CirculationRepository) GetLoans(filter models.LoanFilter, limit, offset int) ([]models.Loan, error) {
+	loans := []models.Loan{}
+	query := r.scoped("loans")
+	if filter.BookID != 0 {
+		query = query.Where("book_id = ?", filter.BookID)
+	}
+	if filter.CopyID != 0 {
+		query = query.Where("copy_id = ?", filter.CopyID)
+	}
+	if filter.MemberID != 0 {
+		query = query.Where("member_id = ?", filter.MemberID)
+	}
+	if filter.ActiveOnly {
+		query = query.Where("returned_at IS NULL")
+	}
+	result := query.Order("checked_out_at DESC, id DESC").Limit(limit).Offset(offset).Find(&loans)
+	return loans, result.Error
+}
+
+func (r *CirculationRepository) GetLoan(id uint) (*models.Loan, error) {
+	var loan models.Loan
+	if err := r.scoped("loans").First(&loan, id).Error; err != nil {
+		return nil, err
+	}
+	return &loan, nil
+}
+
+// CreateLoan relies on the partial unique index from db.InitDB as the last
+// line of defence against a second active loan of the same copy.
+func (r *CirculationRepository) CreateLoan(loan *models.Loan) error {
+	if r.tenant == "" {
+		return models.ErrNoTenant
+	}
+	loan.TenantID = r.tenant
+	err := r.db.Create(loan).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return models.ErrCopyUnavailable
+	}
+	return err
+}
+
+func (r *CirculationRepository) ReturnLoan(loan *models.Loan) error {
+	now := time.Now()
+	result := r.db.Model(loan).
+		Where("tenant_id = ? AND returned_at IS NULL", r.tenant).
+		Updates(map[string]interface{}{"returned_at": now, "updated_at": now})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		if _, err := r.GetLoan(loan.ID); err != nil {
+			return err
+		}
+		return models.ErrLoanReturned
+	}
+	loan.ReturnedAt = &now
+	loan.UpdatedAt = now
+	return nil
+}
+
+// holdsWithPosition selects holds with their place in the queue: the
+// number of waiting holds on the same book up to and including this one.
+func (r *CirculationRepository) holdsWithPosition() *gorm.DB {
+	return r.scoped("holds").Model(&models.Hold{}).Select(`holds.*, CASE WHEN holds.status = 'waiting' THEN (
+		SELECT count(*) FROM holds ahead
+		WHERE ahead.book_id = holds.book_id AND ahead.status = 'waiting' AND ahead.id <= holds.id
+	) ELSE 0 END AS position`)
+}
+
+func (r *CirculationRepository) GetHolds(filter models.HoldFilter, limit, offset int) ([]models.Hold, error) {
+	holds := []models.Hold{}
+	query := r.holdsWithPosition()
+	if filter.BookID != 0 {
+		query = query.Where("holds.book_id = ?", filter.BookID)
+	}
+	if filter.MemberID != 0 {
+		query = query.Where("holds.member_id = ?", filter.MemberID)
+	}
+	if filter.Status != "" {
+		query = query.Where("holds.status = ?", filter.Status)
+	}
+	result := query.Order("holds.id").Limit(limit).Offset(offset).Find(&holds)
+	return holds, result.Error
+}
+
+func (r *CirculationRepository) GetHold(id uint) (*models.Hold, error) {
+	var hold models.Hold
+	if err := r.holdsWithPosition().Where("holds.id = ?", id).First(&hold).Error; err != nil {
+		return nil, err
+	}
+	return &hold, nil
+}
+
+func (r *CirculationRepository) LockHold(id uint) (*models.Hold, error) {
+	var hold models.Hold
+	result := r.scoped("holds").Clauses(clause.Locking{Strength: "UPDATE"}).First(&hold, id)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &hold, nil
+}
+
+// CreateHold relies on the partial unique index from db.InitDB to allow
+// one active hold per member and book.
+func (r *CirculationRepository) CreateHold(hold *models.Hold) error {
+	if r.tenant == "" {
+		return models.ErrNoTenant
+	}
+	hold.TenantID = r.tenant
+	err := r.db.Create(hold).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return models.ErrDuplicateHold
+	}
+	return err
+}
+
+func (r *CirculationRepository) UpdateHold(hold *models.Hold) error {
+	result := r.db.Model(hold).Where("tenant_id = ?", r.tenant).Select("status", "copy_id", "ready_at", "pickup_by", "updated_at").Updates(hold)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *CirculationRepository) NextHold(bookID uint) (*models.Hold, error) {
+	var hold models.Hold
+	result := r.scoped("holds").Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("book_id = ? AND status = ?", bookID, models.HoldWaiting).
+		Order("id").
+		First(&hold)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &hold, nil
+}
+
+func (r *CirculationRepository) GetReadyHold(copyID uint) (*models.Hold, error) {
+	var hold models.Hold
+	result := r.scoped("holds").Where("copy_id = ? AND status = ?", copyID, models.HoldReady).First(&hold)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &hold, nil
+}
+
+func (r *CirculationRepository) GetExpiredHolds(now time.Time, limit int) ([]models.Hold, error) {
+	holds := []models.Hold{}
+	result := r.db.Where("status = ? AND pickup_by < ?", models.HoldReady, now).
+		Order("pickup_by, id").
+		Limit(limit).
+		Find(&holds)
+	return holds, result.Error
+}
+
+func (r *CirculationRepository) EnqueueEvent(event *models.OutboxEvent) error {
+	return enqueueOutboxEvent(r.db, event)
+}
+
+func (r *CirculationRepository) ClaimEvents(limit int, lease time.Duration) ([]models.OutboxEvent, error) {
+	return claimOutboxEvents(r.db, limit, lease)
+}
+
+func (r *CirculationRepository) SaveEvents(events []models.OutboxEvent) error {
+	return saveOutboxEvents(r.db, events)
+}
+
+func (r *CirculationRepository) Transaction(fn func(tx CirculationStore) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&CirculationRepository{db: tx, tenant: r.tenant})
+	})
+}
+
+func translateCopyError(err error) error {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return models.ErrDuplicateBarcode
+	}
+	return err
+}
@@ -0,0 +1,83 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/shani34/book-management-system/internal/models"
+)
+
+// CirculationStore persists copies, loans, holds and fines. Missing rows are
+// reported as gorm.ErrRecordNotFound. Like BookStore it writes events to
+// the outbox, so they commit with the loan or hold they describe.
+//
+// Like BookStore, a store is bound to one tenant: rows of other tenants are
+// reported missing and created rows get the tenant. Only GetExpiredHolds
+// and the outbox methods look across tenants, for the background workers.
+type CirculationStore interface {
+	OutboxStore
+	FineStore
+
+	// ForTenant returns a store bound to tenantID on the same connection or
+	// transaction.
+	ForTenant(tenantID string) CirculationStore
+
+	GetCopies(bookID uint) ([]models.Copy, error)
+	GetCopy(id uint) (*models.Copy, error)
+	GetCopyByBarcode(barcode string) (*models.Copy, error)
+	// LockCopy reads the copy and, inside a transaction, locks it until
+	// the transaction ends so concurrent checkouts of it are serialised.
+	LockCopy(id uint) (*models.Copy, error)
+	// LockCopies locks every copy of a book, in ID order, so no copy of it
+	// changes status until the transaction ends.
+	LockCopies(bookID uint) ([]models.Copy, error)
+	// CreateCopy and UpdateCopy return models.ErrDuplicateBarcode when
+	// another copy has the barcode.
+	CreateCopy(bookCopy *models.Copy) error
+	UpdateCopy(bookCopy *models.Copy) error
+	// DeleteCopy refuses with models.ErrCopyHasLoans once the copy has
+	// been lent out.
+	DeleteCopy(id uint) error
+
+	// GetMember reads the member with their tier.
+	GetMember(id uint) (*models.Member, error)
+	// LockMember reads the member with their tier and, inside a
+	// transaction, locks the member row so concurrent checkouts by one
+	// member are checked against the loan limit one at a time.
+	LockMember(id uint) (*models.Member, error)
+	CountActiveLoans(memberID uint) (int64, error)
+
+	GetLoans(filter models.LoanFilter, limit, offset int) ([]models.Loan, error)
+	GetLoan(id uint) (*models.Loan, error)
+	// CreateLoan returns models.ErrCopyUnavailable if the copy already has
+	// an active loan.
+	CreateLoan(loan *models.Loan) error
+	// ReturnLoan sets ReturnedAt on an active loan, or fails with
+	// models.ErrLoanReturned.
+	ReturnLoan(loan *models.Loan) error
+
+	// GetHolds and GetHold fill in the queue Position of waiting holds.
+	// Holds are listed in queue order.
+	GetHolds(filter models.HoldFilter, limit, offset int) ([]models.Hold, error)
+	GetHold(id uint) (*models.Hold, error)
+	// LockHold reads the hold and, inside a transaction, locks it until
+	// the transaction ends.
+	LockHold(id uint) (*models.Hold, error)
+	// CreateHold returns models.ErrDuplicateHold if the member already has
+	// an active hold on the book.
+	CreateHold(hold *models.Hold) error
+	UpdateHold(hold *models.Hold) error
+	// NextHold returns the waiting hold at the front of the book's queue.
+	NextHold(bookID uint) (*models.Hold, error)
+	// GetReadyHold returns the ready hold the copy is set aside for.
+	GetReadyHold(copyID uint) (*models.Hold, error)
+	// GetExpiredHolds lists ready holds of every tenant whose pickup
+	// deadline is before now, oldest deadline first.
+	GetExpiredHolds(now time.Time, limit int) ([]models.Hold, error)
+
+	Transaction(fn func(tx CirculationStore) error) error
+}
+
+var (
+	_ CirculationStore = (*CirculationRepository)(nil)
+	_ CirculationStore = (*MemoryCirculationRepository)(nil)
+)
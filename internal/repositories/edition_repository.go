@@ -0,0 +1,81 @@
+package repositories
+
+import (
+	"errors"
+	"github.com/shani34/book-management-system/internal/models"
+	"gorm.io/gorm"
+)
+
+type EditionRepository struct {
+	db     *gorm.DB
+	tenant string
+}
+
+// NewEditionRepository returns a store bound to no tenant; see ForTenant.
+func NewEditionRepository(db *gorm.DB) *EditionRepository {
+	return &EditionRepository{db: db}
+}
+
+func (r *EditionRepository) ForTenant(tenantID string) EditionStore {
+	return &EditionRepository{db: r.db, tenant: tenantID}
+}
+
+func (r *EditionRepository) scoped() *gorm.DB {
+	return r.db.Where("editions.tenant_id = ?", r.tenant)
+}
+
+func (r *EditionRepository) GetByBook(bookID uint) ([]models.Edition, error) {
+	editions := []models.Edition{}
+	result := r.scoped().Where("book_id = ?", bookID).Order("id").Find(&editions)
+	return editions, result.Error
+}
+
+func (r *EditionRepository) GetByID(bookID, id uint) (*models.Edition, error) {
+	var edition models.Edition
+	result := r.scoped().Where("book_id = ?", bookID).First(&edition, id)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &edition, nil
+}
+
+func (r *EditionRepository) Create(edition *models.Edition) error {
+	if r.tenant == "" {
+		return models.ErrNoTenant
+	}
+	edition.TenantID = r.tenant
+	return translateEditionError(r.db.Create(edition).Error)
+}
+
+func (r *EditionRepository) Update(edition *models.Edition) error {
+	edition.TenantID = r.tenant
+	result := r.db.Model(edition).
+		Where("tenant_id = ? AND book_id = ?", r.tenant, edition.BookID).
+		Select("publisher", "format", "language", "isbn", "publication_date", "updated_at").
+		Updates(edition)
+	if result.Error != nil {
+		return translateEditionError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *EditionRepository) Delete(bookID, id uint) error {
+	result := r.scoped().Where("book_id = ?", bookID).Delete(&models.Edition{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func translateEditionError(err error) error {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return models.ErrDuplicateISBN
+	}
+	return err
+}
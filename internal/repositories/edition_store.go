@@ -0,0 +1,25 @@
+package repositories
+
+import "github.com/shani34/book-management-system/internal/models"
+
+// EditionStore persists the editions of books. Editions are always
+// addressed through their book, so an edition ID under the wrong book is
+// reported as gorm.ErrRecordNotFound. The store only sees the editions of
+// the tenant it is bound to.
+type EditionStore interface {
+	// ForTenant returns a store bound to tenantID.
+	ForTenant(tenantID string) EditionStore
+
+	GetByBook(bookID uint) ([]models.Edition, error)
+	GetByID(bookID, id uint) (*models.Edition, error)
+	// Create and Update return models.ErrDuplicateISBN when another
+	// edition of the tenant has the same ISBN.
+	Create(edition *models.Edition) error
+	Update(edition *models.Edition) error
+	Delete(bookID, id uint) error
+}
+
+var (
+	_ EditionStore = (*EditionRepository)(nil)
+	_ EditionStore = (*MemoryEditionRepository)(nil)
+)
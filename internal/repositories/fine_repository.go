@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"github.com/shani34/book-management-system/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GetFines lists fines, most recent first.
+func (r *CirculationRepository) GetFines(filter models.FineFilter, limit, offset int) ([]models.Fine, error) {
+	fines := []models.Fine{}
+	query := r.scoped("fines")
+	if filter.MemberID != 0 {
+		query = query.Where("member_id = ?", filter.MemberID)
+	}
+	if filter.OpenOnly {
+		query = query.Where("balance > 0")
+	}
+	result := query.Order("id DESC").Limit(limit).Offset(offset).Find(&fines)
+	return fines, result.Error
+}
+
+func (r *CirculationRepository) GetFine(id uint) (*models.Fine, error) {
+	var fine models.Fine
+	if err := r.scoped("fines").First(&fine, id).Error; err != nil {
+		return nil, err
+	}
+	return &fine, nil
+}
+
+func (r *CirculationRepository) LockFine(id uint) (*models.Fine, error) {
+	var fine models.Fine
+	if err := r.scoped("fines").Clauses(clause.Locking{Strength: "UPDATE"}).First(&fine, id).Error; err != nil {
+		return nil, err
+	}
+	return &fine, nil
+}
+
+func (r *CirculationRepository) CreateFine(fine *models.Fine) error {
+	if r.tenant == "" {
+		return models.ErrNoTenant
+	}
+	fine.TenantID = r.tenant
+	return r.db.Create(fine).Error
+}
+
+func (r *CirculationRepository) UpdateFine(fine *models.Fine) error {
+	result := r.db.Model(fine).Where("tenant_id = ?", r.tenant).Select("paid", "waived", "balance", "updated_at").Updates(fine)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *CirculationRepository) GetFineBalance(memberID uint) (int64, error) {
+	var balance int64
+	result := r.scoped("fines").Model(&models.Fine{}).
+		Where("member_id = ?", memberID).
+		Select("COALESCE(SUM(balance), 0)").
+		Scan(&balance)
+	return balance, result.Error
+}
+
+func (r *CirculationRepository) AddLedgerEntry(entry *models.LedgerEntry) error {
+	if r.tenant == "" {
+		return models.ErrNoTenant
+	}
+	entry.TenantID = r.tenant
+	return r.db.Create(entry).Error
+}
+
+func (r *CirculationRepository) GetLedger(memberID uint, limit, offset int) ([]models.LedgerEntry, error) {
+	entries := []models.LedgerEntry{}
+	result := r.scoped("fine_ledger").Where("member_id = ?", memberID).Order("id").Limit(limit).Offset(offset).Find(&entries)
+	return entries, result.Error
+}
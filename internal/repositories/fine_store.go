@@ -0,0 +1,23 @@
+package repositories
+
+import "github.com/shani34/book-management-system/internal/models"
+
+// FineStore persists fines and their ledger. It is part of
+// CirculationStore so a fine is assessed in the same transaction as the
+// return that incurs it. Ledger entries are never updated or deleted.
+type FineStore interface {
+	GetFines(filter models.FineFilter, limit, offset int) ([]models.Fine, error)
+	GetFine(id uint) (*models.Fine, error)
+	// LockFine reads the fine and, inside a transaction, locks it until
+	// the transaction ends.
+	LockFine(id uint) (*models.Fine, error)
+	CreateFine(fine *models.Fine) error
+	// UpdateFine saves Paid, Waived and Balance.
+	UpdateFine(fine *models.Fine) error
+	// GetFineBalance sums the balances of the member's fines.
+	GetFineBalance(memberID uint) (int64, error)
+
+	AddLedgerEntry(entry *models.LedgerEntry) error
+	// GetLedger lists the member's ledger entries, oldest first.
+	GetLedger(memberID uint, limit, offset int) ([]models.LedgerEntry, error)
+}
@@ -0,0 +1,171 @@
+package repositories
+
+import (
+	"errors"
+
+	"github.com/shani34/book-management-system/internal/models"
+	"gorm.io/gorm"
+)
+
+type MemberRepository struct {
+	db     *gorm.DB
+	tenant string
+}
+
+// NewMemberRepository returns a store bound to no tenant; see ForTenant.
+func NewMemberRepository(db *gorm.DB) *MemberRepository {
+	return &MemberRepository{db: db}
+}
+
+func (r *MemberRepository) ForTenant(tenantID string) MemberStore {
+	return &MemberRepository{db: r.db, tenant: tenantID}
+}
+
+func (r *MemberRepository) scoped() *gorm.DB {
+	return r.db.Where("members.tenant_id = ?", r.tenant)
+}
+
+func (r *MemberRepository) GetAll(limit, offset int) ([]models.Member, error) {
+	members := []models.Member{}
+	result := r.scoped().Preload("Tier").Order("name, id").Limit(limit).Offset(offset).Find(&members)
+	return members, result.Error
+}
+
+func (r *MemberRepository) GetByID(id uint) (*models.Member, error) {
+	var member models.Member
+	if err := r.scoped().Preload("Tier").First(&member, id).Error; err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+func (r *MemberRepository) Create(member *models.Member) error {
+	if r.tenant == "" {
+		return models.ErrNoTenant
+	}
+	member.TenantID = r.tenant
+	return translateMemberError(r.db.Omit("Tier").Create(member).Error)
+}
+
+func (r *MemberRepository) Update(member *models.Member) error {
+	result := r.db.Model(member).Where("tenant_id = ?", r.tenant).
+		Select("name", "email", "tier_id", "blocked", "expires_on", "updated_at").
+		Updates(member)
+	if result.Error != nil {
+		return translateMemberError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *MemberRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var member models.Member
+		if err := tx.Where("tenant_id = ?", r.tenant).Select("id").First(&member, id).Error; err != nil {
+			return err
+		}
+		var loans int64
+		if err := tx.Model(&models.Loan{}).Where("member_id = ?", id).Count(&loans).Error; err != nil {
+			return err
+		}
+		if loans > 0 {
+			return models.ErrMemberHasLoans
+		}
+		var holds int64
+		if err := tx.Model(&models.Hold{}).Where("member_id = ? AND status IN ?", id, []string{models.HoldWaiting, models.HoldReady}).Count(&holds).Error; err != nil {
+			return err
+		}
+		if holds > 0 {
+			return models.ErrMemberHasHolds
+		}
+		if err := tx.Exec("DELETE FROM holds WHERE member_id = ?", id).Error; err != nil {
+			return err
+		}
+
+		result := tx.Delete(&models.Member{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+func (r *MemberRepository) tiers() *gorm.DB {
+	return r.db.Where("tiers.tenant_id = ?", r.tenant)
+}
+
+func (r *MemberRepository) GetTiers() ([]models.Tier, error) {
+	tiers := []models.Tier{}
+	result := r.tiers().Order("id").Find(&tiers)
+	return tiers, result.Error
+}
+
+func (r *MemberRepository) GetTier(id uint) (*models.Tier, error) {
+	var tier models.Tier
+	if err := r.tiers().First(&tier, id).Error; err != nil {
+		return nil, err
+	}
+	return &tier, nil
+}
+
+func (r *MemberRepository) CreateTier(tier *models.Tier) error {
+	if r.tenant == "" {
+		return models.ErrNoTenant
+	}
+	tier.TenantID = r.tenant
+	return translateTierError(r.db.Create(tier).Error)
+}
+
+func (r *MemberRepository) UpdateTier(tier *models.Tier) error {
+	tier.TenantID = r.tenant
+	result := r.db.Model(tier).Where("tenant_id = ?", r.tenant).Select("name", "max_loans", "loan_days", "fine_per_day", "fine_grace_days", "fine_cap", "fine_threshold", "updated_at").Updates(tier)
+	if result.Error != nil {
+		return translateTierError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// SeedTiers inserts all the tiers in one statement. Two requests seeding
+// the same tenant at once trip the unique index on the loser's insert,
+// which is fine: the tenant has its tiers either way.
+func (r *MemberRepository) SeedTiers(tiers []models.Tier) error {
+	if r.tenant == "" {
+		return models.ErrNoTenant
+	}
+	var count int64
+	if err := r.tiers().Model(&models.Tier{}).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 || len(tiers) == 0 {
+		return nil
+	}
+	for i := range tiers {
+		tiers[i].TenantID = r.tenant
+	}
+	if err := r.db.Create(&tiers).Error; err != nil && !errors.Is(err, gorm.ErrDuplicatedKey) {
+		return err
+	}
+	return nil
+}
+
+func translateMemberError(err error) error {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return models.ErrDuplicateEmail
+	}
+	return err
+}
+
+func translateTierError(err error) error {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return models.ErrDuplicateTier
+	}
+	return err
+}
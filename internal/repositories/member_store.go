@@ -0,0 +1,39 @@
+package repositories
+
+import "github.com/shani34/book-management-system/internal/models"
+
+// MemberStore persists members and their tiers. Members are read with
+// their Tier filled in. Missing rows are reported as
+// gorm.ErrRecordNotFound.
+//
+// Members and tiers belong to the tenant the store is bound to, like books.
+type MemberStore interface {
+	// ForTenant returns a store bound to tenantID.
+	ForTenant(tenantID string) MemberStore
+
+	GetAll(limit, offset int) ([]models.Member, error)
+	GetByID(id uint) (*models.Member, error)
+	// Create and Update return models.ErrDuplicateEmail when another
+	// member of the tenant has the email.
+	Create(member *models.Member) error
+	Update(member *models.Member) error
+	// Delete refuses with models.ErrMemberHasLoans once the member has
+	// borrowed anything, and with models.ErrMemberHasHolds while they have
+	// active holds. Closed holds are deleted with the member.
+	Delete(id uint) error
+
+	GetTiers() ([]models.Tier, error)
+	GetTier(id uint) (*models.Tier, error)
+	// CreateTier and UpdateTier return models.ErrDuplicateTier when the
+	// name is taken in the tenant.
+	CreateTier(tier *models.Tier) error
+	UpdateTier(tier *models.Tier) error
+	// SeedTiers creates tiers for the tenant if it has none yet, and
+	// otherwise does nothing.
+	SeedTiers(tiers []models.Tier) error
+}
+
+var (
+	_ MemberStore = (*MemberRepository)(nil)
+	_ MemberStore = (*MemoryMemberRepository)(nil)
+)
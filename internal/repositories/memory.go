@@ -0,0 +1,167 @@
+package repositories
+
+import (
+	"sync"
+
+	"github.com/shani34/book-management-system/internal/models"
+)
+
+// MemoryDB holds the state shared by the in-memory stores. Stores built on
+// the same MemoryDB see each other's writes and can share transactions.
+type MemoryDB struct {
+	mu   sync.RWMutex
+	data *memoryData
+}
+
+// NewMemoryDB returns an empty database.
+func NewMemoryDB() *MemoryDB {
+	return &MemoryDB{data: newMemoryData()}
+}
+
+type memoryData struct {
+	books       map[uint]models.Book
+	trash       map[uint]models.Book
+	authors     map[uint]models.Author
+	bookAuthors map[uint][]uint
+	tags        map[uint]models.Tag
+	bookTags    map[uint][]uint
+	editions    map[uint]models.Edition
+	copies      map[uint]models.Copy
+	loans       map[uint]models.Loan
+	tiers       map[uint]models.Tier
+	members     map[uint]models.Member
+	holds       map[uint]models.Hold
+	fines       map[uint]models.Fine
+	apiKeys     map[uint]models.APIKey
+	ledger      []models.LedgerEntry
+	outbox      []models.OutboxEvent
+	seq         map[string]uint
+}
+
+func newMemoryData() *memoryData {
+	return &memoryData{
+		books:       make(map[uint]models.Book),
+		trash:       make(map[uint]models.Book),
+		authors:     make(map[uint]models.Author),
+		bookAuthors: make(map[uint][]uint),
+		tags:        make(map[uint]models.Tag),
+		bookTags:    make(map[uint][]uint),
+		editions:    make(map[uint]models.Edition),
+		copies:      make(map[uint]models.Copy),
+		loans:       make(map[uint]models.Loan),
+		tiers:       make(map[uint]models.Tier),
+		members:     make(map[uint]models.Member),
+		holds:       make(map[uint]models.Hold),
+		fines:       make(map[uint]models.Fine),
+		apiKeys:     make(map[uint]models.APIKey),
+		seq:         make(map[string]uint),
+	}
+}
+
+// nextID hands out auto-increment IDs per table, like a Postgres sequence.
+func (d *memoryData) nextID(table string) uint {
+	d.seq[table]++
+	return d.seq[table]
+}
+
+// bumpID keeps the sequence ahead of explicitly assigned IDs.
+func (d *memoryData) bumpID(table string, id uint) {
+	if id > d.seq[table] {
+		d.seq[table] = id
+	}
+}
+
+func (d *memoryData) clone() *memoryData {
+	c := newMemoryData()
+	for id, book := range d.books {
+		c.books[id] = book
+	}
+	for id, book := range d.trash {
+		c.trash[id] = book
+	}
+	for id, author := range d.authors {
+		c.authors[id] = author
+	}
+	for id, authorIDs := range d.bookAuthors {
+		c.bookAuthors[id] = append([]uint(nil), authorIDs...)
+	}
+	for id, tag := range d.tags {
+		c.tags[id] = tag
+	}
+	for id, tagIDs := range d.bookTags {
+		c.bookTags[id] = append([]uint(nil), tagIDs...)
+	}
+	for id, edition := range d.editions {
+		c.editions[id] = edition
+	}
+	for id, bookCopy := range d.copies {
+		c.copies[id] = bookCopy
+	}
+	for id, loan := range d.loans {
+		c.loans[id] = loan
+	}
+	for id, tier := range d.tiers {
+		c.tiers[id] = tier
+	}
+	for id, member := range d.members {
+		c.members[id] = member
+	}
+	for id, hold := range d.holds {
+		c.holds[id] = hold
+	}
+	for id, fine := range d.fines {
+		c.fines[id] = fine
+	}
+	for id, key := range d.apiKeys {
+		c.apiKeys[id] = key
+	}
+	c.ledger = append([]models.LedgerEntry(nil), d.ledger...)
+	c.outbox = append([]models.OutboxEvent(nil), d.outbox...)
+	for table, id := range d.seq {
+		c.seq[table] = id
+	}
+	return c
+}
+
+// memoryView is embedded by every in-memory store. Outside a transaction it
+// guards access with the MemoryDB lock; inside one, tx is a private copy of
+// the data and the lock is already held by the transaction.
+type memoryView struct {
+	db *MemoryDB
+	tx *memoryData
+}
+
+func (v memoryView) read(fn func(d *memoryData) error) error {
+	if v.tx != nil {
+		return fn(v.tx)
+	}
+	v.db.mu.RLock()
+	defer v.db.mu.RUnlock()
+	return fn(v.db.data)
+}
+
+func (v memoryView) write(fn func(d *memoryData) error) error {
+	if v.tx != nil {
+		return fn(v.tx)
+	}
+	v.db.mu.Lock()
+	defer v.db.mu.Unlock()
+	return fn(v.db.data)
+}
+
+// transaction runs fn on a copy of the data and publishes the copy only if
+// fn succeeds. Transactions are serialised by the write lock.
+func (v memoryView) transaction(fn func(tx memoryView) error) error {
+	if v.tx != nil {
+		return fn(v)
+	}
+	v.db.mu.Lock()
+	defer v.db.mu.Unlock()
+
+	tx := memoryView{db: v.db, tx: v.db.data.clone()}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	v.db.data = tx.tx
+	return nil
+}
@@ -0,0 +1,129 @@
+package repositories
+
+import (
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/shani34/book-management-system/internal/models"
+	"gorm.io/gorm"
+)
+
+// MemoryAPIKeyRepository is the in-memory APIKeyStore.
+type MemoryAPIKeyRepository struct {
+	memoryView
+	tenant string
+}
+
+// NewMemoryAPIKeyRepository returns a store that sees every key; see
+// ForTenant.
+func NewMemoryAPIKeyRepository(db *MemoryDB) *MemoryAPIKeyRepository {
+	return &MemoryAPIKeyRepository{memoryView: memoryView{db: db}}
+}
+
+func (r *MemoryAPIKeyRepository) ForTenant(tenantID string) APIKeyStore {
+	return &MemoryAPIKeyRepository{memoryView: r.memoryView, tenant: tenantID}
+}
+
+// visible reports whether key is in the store's tenant, if it has one.
+func (r *MemoryAPIKeyRepository) visible(key models.APIKey) bool {
+	return r.tenant == "" || key.TenantID == r.tenant
+}
+
+func (r *MemoryAPIKeyRepository) GetAll(limit, offset int) ([]models.APIKey, error) {
+	keys := []models.APIKey{}
+	err := r.read(func(d *memoryData) error {
+		for _, key := range d.apiKeys {
+			if r.visible(key) {
+				keys = append(keys, copyAPIKey(key))
+			}
+		}
+		sort.Slice(keys, func(i, j int) bool { return keys[i].ID > keys[j].ID })
+		keys = paginate(keys, limit, offset)
+		return nil
+	})
+	return keys, err
+}
+
+func (r *MemoryAPIKeyRepository) GetByID(id uint) (*models.APIKey, error) {
+	var key models.APIKey
+	err := r.read(func(d *memoryData) error {
+		found, ok := d.apiKeys[id]
+		if !ok || !r.visible(found) {
+			return gorm.ErrRecordNotFound
+		}
+		key = copyAPIKey(found)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *MemoryAPIKeyRepository) GetByPrefix(prefix string) (*models.APIKey, error) {
+	var key models.APIKey
+	err := r.read(func(d *memoryData) error {
+		for _, found := range d.apiKeys {
+			if found.Prefix == prefix && r.visible(found) {
+				key = copyAPIKey(found)
+				return nil
+			}
+		}
+		return gorm.ErrRecordNotFound
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *MemoryAPIKeyRepository) Create(key *models.APIKey) error {
+	return r.write(func(d *memoryData) error {
+		for _, other := range d.apiKeys {
+			if other.Prefix == key.Prefix {
+				return gorm.ErrDuplicatedKey
+			}
+		}
+		now := time.Now()
+		key.ID = d.nextID("api_keys")
+		key.CreatedAt = now
+		key.UpdatedAt = now
+		d.apiKeys[key.ID] = copyAPIKey(*key)
+		return nil
+	})
+}
+
+func (r *MemoryAPIKeyRepository) Revoke(id uint, at time.Time) error {
+	return r.write(func(d *memoryData) error {
+		key, ok := d.apiKeys[id]
+		if !ok || !r.visible(key) {
+			return gorm.ErrRecordNotFound
+		}
+		if key.RevokedAt != nil {
+			return models.ErrAPIKeyRevoked
+		}
+		key.RevokedAt = &at
+		key.UpdatedAt = at
+		d.apiKeys[id] = key
+		return nil
+	})
+}
+
+func (r *MemoryAPIKeyRepository) Touch(id uint, at time.Time) error {
+	return r.write(func(d *memoryData) error {
+		key, ok := d.apiKeys[id]
+		if !ok || !r.visible(key) {
+			return gorm.ErrRecordNotFound
+		}
+		key.LastUsedAt = &at
+		d.apiKeys[id] = key
+		return nil
+	})
+}
+
+// copyAPIKey keeps callers from sharing the stored key's scope slice.
+func copyAPIKey(key models.APIKey) models.APIKey {
+	key.Scopes = slices.Clone(key.Scopes)
+	return key
+}
@@ -0,0 +1,170 @@
+package repositories
+
+import (
+	"sort"
+	"time"
+
+	"github.com/shani34/book-management-system/internal/models"
+	"gorm.io/gorm"
+)
+
+// MemoryAuthorRepository is the in-memory AuthorStore. It must share its
+// MemoryDB with the MemoryBookRepository so both see the same links.
+type MemoryAuthorRepository struct {
+	memoryView
+	tenant string
+}
+
+// NewMemoryAuthorRepository returns a store bound to no tenant; see
+// ForTenant.
+func NewMemoryAuthorRepository(db *MemoryDB) *MemoryAuthorRepository {
+	return &MemoryAuthorRepository{memoryView: memoryView{db: db}}
+}
+
+func (r *MemoryAuthorRepository) ForTenant(tenantID string) AuthorStore {
+	return &MemoryAuthorRepository{memoryView: r.memoryView, tenant: tenantID}
+}
+
+// author returns the tenant's author with id.
+func (r *MemoryAuthorRepository) author(d *memoryData, id uint) (models.Author, bool) {
+	author, ok := d.authors[id]
+	return author, ok && author.TenantID == r.tenant
+}
+
+func (r *MemoryAuthorRepository) GetAll(limit, offset int) ([]models.Author, error) {
+	var authors []models.Author
+	err := r.read(func(d *memoryData) error {
+		authors = make([]models.Author, 0, len(d.authors))
+		for _, author := range d.authors {
+			if author.TenantID == r.tenant {
+				authors = append(authors, author)
+			}
+		}
+		sort.Slice(authors, func(i, j int) bool {
+			if authors[i].Name != authors[j].Name {
+				return authors[i].Name < authors[j].Name
+			}
+			return authors[i].ID < authors[j].ID
+		})
+		authors = paginate(authors, limit, offset)
+		return nil
+	})
+	return authors, err
+}
+
+func (r *MemoryAuthorRepository) GetByID(id uint) (*models.Author, error) {
+	var author models.Author
+	err := r.read(func(d *memoryData) error {
+		found, ok := r.author(d, id)
+		if !ok {
+			return gorm.ErrRecordNotFound
+		}
+		author = found
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &author, nil
+}
+
+func (r *MemoryAuthorRepository) Create(author *models.Author) error {
+	if r.tenant == "" {
+		return models.ErrNoTenant
+	}
+	author.TenantID = r.tenant
+	return r.write(func(d *memoryData) error {
+		if d.authorByName(r.tenant, author.Name) != nil {
+			return models.ErrDuplicateAuthor
+		}
+		d.createAuthor(author)
+		return nil
+	})
+}
+
+func (r *MemoryAuthorRepository) Update(author *models.Author) error {
+	return r.write(func(d *memoryData) error {
+		stored, ok := r.author(d, author.ID)
+		if !ok {
+			return gorm.ErrRecordNotFound
+		}
+		if other := d.authorByName(r.tenant, author.Name); other != nil && other.ID != author.ID {
+			return models.ErrDuplicateAuthor
+		}
+		author.TenantID = stored.TenantID
+		author.CreatedAt = stored.CreatedAt
+		author.UpdatedAt = time.Now()
+		d.authors[author.ID] = *author
+		return nil
+	})
+}
+
+func (r *MemoryAuthorRepository) Delete(id uint) error {
+	return r.write(func(d *memoryData) error {
+		if _, ok := r.author(d, id); !ok {
+			return gorm.ErrRecordNotFound
+		}
+		for _, authorIDs := range d.bookAuthors {
+			for _, authorID := range authorIDs {
+				if authorID == id {
+					return models.ErrAuthorHasBooks
+				}
+			}
+		}
+		delete(d.authors, id)
+		return nil
+	})
+}
+
+func (r *MemoryAuthorRepository) GetBooks(authorID uint, limit, offset int) ([]models.Book, error) {
+	var books []models.Book
+	err := r.read(func(d *memoryData) error {
+		for bookID, authorIDs := range d.bookAuthors {
+			book, ok := d.books[bookID]
+			if !ok || book.TenantID != r.tenant {
+				continue
+			}
+			for _, id := range authorIDs {
+				if id == authorID {
+					books = append(books, d.withRelations(book))
+					break
+				}
+			}
+		}
+		sort.Slice(books, func(i, j int) bool { return books[i].ID < books[j].ID })
+		books = paginate(books, limit, offset)
+		return nil
+	})
+	return books, err
+}
+
+func (d *memoryData) authorByName(tenantID, name string) *models.Author {
+	for _, author := range d.authors {
+		if author.TenantID == tenantID && author.Name == name {
+			return &author
+		}
+	}
+	return nil
+}
+
+func (d *memoryData) createAuthor(author *models.Author) {
+	now := time.Now()
+	author.ID = d.nextID("authors")
+	author.CreatedAt = now
+	author.UpdatedAt = now
+	d.authors[author.ID] = *author
+}
+
+// withRelations fills in book.Authors and book.Tags from the link tables,
+// like Preload.
+func (d *memoryData) withRelations(book models.Book) models.Book {
+	book.Authors = nil
+	for _, id := range d.bookAuthors[book.ID] {
+		book.Authors = append(book.Authors, d.authors[id])
+	}
+	book.Tags = nil
+	for _, id := range d.bookTags[book.ID] {
+		book.Tags = append(book.Tags, d.tags[id])
+	}
+	return book
+}
@@ -0,0 +1,81 @@
+package repositories
+
+import (
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/shani34/book-management-system/internal/models"
+)
+
+func matchesBookFilter(book models.Book, filter models.BookFilter) bool {
+	if filter.Author != "" && book.Author != filter.Author {
+		return false
+	}
+	if filter.AuthorContains != "" &&
+		!strings.Contains(strings.ToLower(book.Author), strings.ToLower(filter.AuthorContains)) {
+		return false
+	}
+	if filter.TitlePrefix != "" &&
+		!strings.HasPrefix(strings.ToLower(book.Title), strings.ToLower(filter.TitlePrefix)) {
+		return false
+	}
+	if filter.YearFrom != nil && book.Year < *filter.YearFrom {
+		return false
+	}
+	if filter.YearTo != nil && book.Year > *filter.YearTo {
+		return false
+	}
+	if len(filter.Tags) > 0 {
+		names := uniqueTagNames(filter.Tags)
+		matched := 0
+		for _, name := range names {
+			if slices.ContainsFunc(book.Tags, func(tag models.Tag) bool { return tag.Name == name }) {
+				matched++
+			}
+		}
+		if matched == 0 || (filter.AllTags && matched < len(names)) {
+			return false
+		}
+	}
+	return true
+}
+
+// compareBooks orders books the same way filterBooks does in SQL.
+func compareBooks(a, b models.Book, filter models.BookFilter) int {
+	c := 0
+	switch filter.Sort {
+	case "title":
+		c = strings.Compare(a.Title, b.Title)
+	case "author":
+		c = strings.Compare(a.Author, b.Author)
+	case "year":
+		c = a.Year - b.Year
+	case "created_at":
+		c = a.CreatedAt.Compare(b.CreatedAt)
+	}
+	if c == 0 {
+		c = int(a.ID) - int(b.ID)
+	}
+	if filter.Desc {
+		return -c
+	}
+	return c
+}
+
+func (d *memoryData) filteredBooks(tenantID string, filter models.BookFilter) []models.Book {
+	books := make([]models.Book, 0, len(d.books))
+	for _, book := range d.books {
+		if book.TenantID != tenantID {
+			continue
+		}
+		book = d.withRelations(book)
+		if matchesBookFilter(book, filter) {
+			books = append(books, book)
+		}
+	}
+	sort.Slice(books, func(i, j int) bool {
+		return compareBooks(books[i], books[j], filter) < 0
+	})
+	return books
+}
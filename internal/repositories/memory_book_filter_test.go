@@ -0,0 +1,64 @@
+package repositories
+
+import (
+	"testing"
+
+	"github.com/shani34/book-management-system/internal/models"
+)
+
+func filterYear(y int) *int { return &y }
+
+func TestGetAllFilters(t *testing.T) {
+	repo := NewMemoryBookRepository(NewMemoryDB()).ForTenant("t")
+	for _, book := range []models.Book{
+		{Title: "Dune", Author: "Frank Herbert", Year: 1965},
+		{Title: "Emma", Author: "Jane Austen", Year: 1815},
+		{Title: "dune messiah", Author: "Frank Herbert", Year: 1969},
+		{Title: "100% Dune", Author: "Brian Herbert", Year: 1999},
+		{Title: "Persuasion", Author: "Jane Austen", Year: 1817},
+	} {
+		book := book
+		if err := repo.Create(&book); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter models.BookFilter
+		want   []uint
+	}{
+		{"no filter lists by id", models.BookFilter{}, []uint{1, 2, 3, 4, 5}},
+		{"exact author", models.BookFilter{Author: "Frank Herbert"}, []uint{1, 3}},
+		{"exact author is case-sensitive", models.BookFilter{Author: "frank herbert"}, nil},
+		{"author substring", models.BookFilter{AuthorContains: "HERB"}, []uint{1, 3, 4}},
+		{"title prefix", models.BookFilter{TitlePrefix: "DUNE"}, []uint{1, 3}},
+		{"wildcards match literally", models.BookFilter{TitlePrefix: "100%"}, []uint{4}},
+		{"no wildcard expansion", models.BookFilter{TitlePrefix: "_une"}, nil},
+		{"year range", models.BookFilter{YearFrom: filterYear(1817), YearTo: filterYear(1969)}, []uint{1, 3, 5}},
+		{"filters combine", models.BookFilter{AuthorContains: "herbert", YearFrom: filterYear(1966)}, []uint{3, 4}},
+		{"sort by year", models.BookFilter{Sort: "year"}, []uint{2, 5, 1, 3, 4}},
+		{"sort by year descending", models.BookFilter{Sort: "year", Desc: true}, []uint{4, 3, 1, 5, 2}},
+		{"ties break by id", models.BookFilter{Sort: "author"}, []uint{4, 1, 3, 2, 5}},
+		{"ties break by id descending", models.BookFilter{Sort: "author", Desc: true}, []uint{5, 2, 3, 1, 4}},
+		{"descending without a field", models.BookFilter{Desc: true}, []uint{5, 4, 3, 2, 1}},
+	}
+	for _, tt := range tests {
+		books, err := repo.GetAll(tt.filter, -1, 0)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		got := make([]uint, len(books))
+		for i, book := range books {
+			got[i] = book.ID
+		}
+		if !equalIDs(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	books, err := repo.GetAll(models.BookFilter{Sort: "year"}, 2, 1)
+	if err != nil || len(books) != 2 || books[0].ID != 5 || books[1].ID != 1 {
+		t.Errorf("paged: got %v, %v; want books 5 and 1", books, err)
+	}
+}
@@ -0,0 +1,448 @@
+package repositories
+
+import (
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/shani34/book-management-system/internal/models"
+	"gorm.io/gorm"
+)
+
+// MemoryBookRepository is a thread-safe BookStore kept entirely in process
+// memory. It is meant for local runs and tests where no Postgres is available.
+type MemoryBookRepository struct {
+	memoryView
+	tenant string
+}
+
+// NewMemoryBookRepository returns a store bound to no tenant; see ForTenant.
+func NewMemoryBookRepository(db *MemoryDB) *MemoryBookRepository {
+	return &MemoryBookRepository{memoryView: memoryView{db: db}}
+}
+
+func (r *MemoryBookRepository) ForTenant(tenantID string) BookStore {
+	return &MemoryBookRepository{memoryView: r.memoryView, tenant: tenantID}
+}
+
+// liveBook returns the tenant's book with id, if it isn't in the trash.
+func (r *MemoryBookRepository) liveBook(d *memoryData, id uint) (models.Book, bool) {
+	book, ok := d.books[id]
+	return book, ok && book.TenantID == r.tenant
+}
+
+// trashedBook returns the tenant's book with id, if it is in the trash.
+func (r *MemoryBookRepository) trashedBook(d *memoryData, id uint) (models.Book, bool) {
+	book, ok := d.trash[id]
+	return book, ok && book.TenantID == r.tenant
+}
+
+func (r *MemoryBookRepository) GetAll(filter models.BookFilter, limit, offset int) ([]models.Book, error) {
+	var books []models.Book
+	err := r.read(func(d *memoryData) error {
+		books = paginate(d.filteredBooks(r.tenant, filter), limit, offset)
+		return nil
+	})
+	return books, err
+}
+
+func (r *MemoryBookRepository) GetPage(filter models.BookFilter, cursor *models.BookCursor, limit int) ([]models.Book, error) {
+	var page []models.Book
+	err := r.read(func(d *memoryData) error {
+		books := d.filteredBooks(r.tenant, filter)
+		if cursor == nil {
+			page = paginate(books, limit, 0)
+			return nil
+		}
+
+		position, err := cursor.Position()
+		if err != nil {
+			return models.ErrInvalidCursor
+		}
+		if cursor.Before {
+			// Books are sorted, so everything before the cursor is a prefix.
+			end := sort.Search(len(books), func(i int) bool {
+				return compareBooks(books[i], position, filter) >= 0
+			})
+			start := 0
+			if limit >= 0 && end > limit {
+				start = end - limit
+			}
+			page = books[start:end]
+			return nil
+		}
+		start := sort.Search(len(books), func(i int) bool {
+			return compareBooks(books[i], position, filter) > 0
+		})
+		page = paginate(books[start:], limit, 0)
+		return nil
+	})
+	return page, err
+}
+
+func (r *MemoryBookRepository) Count(filter models.BookFilter) (int64, error) {
+	var total int64
+	err := r.read(func(d *memoryData) error {
+		total = int64(len(d.filteredBooks(r.tenant, filter)))
+		return nil
+	})
+	return total, err
+}
+
+// Each iterates over a snapshot so fn runs without holding the lock.
+func (r *MemoryBookRepository) Each(filter models.BookFilter, fn func(book *models.Book) error) error {
+	var books []models.Book
+	err := r.read(func(d *memoryData) error {
+		books = d.filteredBooks(r.tenant, filter)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for i := range books {
+		if err := fn(&books[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *MemoryBookRepository) GetByID(id uint) (*models.Book, error) {
+	var book models.Book
+	err := r.read(func(d *memoryData) error {
+		found, ok := r.liveBook(d, id)
+		if !ok {
+			return gorm.ErrRecordNotFound
+		}
+		book = d.withRelations(found)
+		book.Editions = d.bookEditions(found.ID)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &book, nil
+}
+
+func (r *MemoryBookRepository) GetByISBN13(isbn13 string) (*models.Book, error) {
+	var book models.Book
+	err := r.read(func(d *memoryData) error {
+		for _, found := range d.books {
+			if found.TenantID == r.tenant && found.ISBN13 == isbn13 {
+				book = d.withRelations(found)
+				book.Editions = d.bookEditions(found.ID)
+				return nil
+			}
+		}
+		return gorm.ErrRecordNotFound
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &book, nil
+}
+
+func (r *MemoryBookRepository) Create(book *models.Book) error {
+	if r.tenant == "" {
+		return models.ErrNoTenant
+	}
+	book.TenantID = r.tenant
+	return r.write(func(d *memoryData) error {
+		if d.isbnTaken(book) {
+			return models.ErrDuplicateISBN
+		}
+		now := time.Now()
+		if book.ID == 0 {
+			book.ID = d.nextID("books")
+		} else if _, taken := d.books[book.ID]; taken {
+			return translateBookError(gorm.ErrDuplicatedKey)
+		} else if _, taken := d.trash[book.ID]; taken {
+			return translateBookError(gorm.ErrDuplicatedKey)
+		}
+		d.bumpID("books", book.ID)
+		if book.CreatedAt.IsZero() {
+			book.CreatedAt = now
+		}
+		if book.UpdatedAt.IsZero() {
+			book.UpdatedAt = now
+		}
+		if book.Version == 0 {
+			book.Version = 1
+		}
+		stored := *book
+		stored.Authors, stored.AuthorIDs, stored.Tags, stored.Editions = nil, nil, nil, nil
+		d.books[book.ID] = stored
+		return nil
+	})
+}
+
+func (r *MemoryBookRepository) Update(book *models.Book) error {
+	return r.write(func(d *memoryData) error {
+		stored, ok := r.liveBook(d, book.ID)
+		if !ok {
+			return gorm.ErrRecordNotFound
+		}
+		if stored.Version != book.Version {
+			return models.ErrVersionConflict
+		}
+		book.TenantID = r.tenant
+		if d.isbnTaken(book) {
+			return models.ErrDuplicateISBN
+		}
+		book.Version++
+		book.UpdatedAt = time.Now()
+		stored = *book
+		stored.Authors, stored.AuthorIDs, stored.Tags, stored.Editions = nil, nil, nil, nil
+		d.books[book.ID] = stored
+		return nil
+	})
+}
+
+// isbnTaken reports whether a book of the same tenant other than book,
+// including one in the trash, has one of its ISBNs, like the unique indexes
+// on books.
+func (d *memoryData) isbnTaken(book *models.Book) bool {
+	taken := func(other models.Book) bool {
+		if other.ID == book.ID || other.TenantID != book.TenantID {
+			return false
+		}
+		return (book.ISBN10 != "" && other.ISBN10 == book.ISBN10) ||
+			(book.ISBN13 != "" && other.ISBN13 == book.ISBN13)
+	}
+	for _, other := range d.books {
+		if taken(other) {
+			return true
+		}
+	}
+	for _, other := range d.trash {
+		if taken(other) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *MemoryBookRepository) Delete(id uint, version uint) error {
+	return r.write(func(d *memoryData) error {
+		stored, ok := r.liveBook(d, id)
+		if !ok {
+			return gorm.ErrRecordNotFound
+		}
+		if version != 0 && stored.Version != version {
+			return models.ErrVersionConflict
+		}
+		stored.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+		d.trash[id] = stored
+		delete(d.books, id)
+		return nil
+	})
+}
+
+func (r *MemoryBookRepository) ListDeleted(limit, offset int) ([]models.Book, error) {
+	var books []models.Book
+	err := r.read(func(d *memoryData) error {
+		books = make([]models.Book, 0, len(d.trash))
+		for _, book := range d.trash {
+			if book.TenantID == r.tenant {
+				books = append(books, book)
+			}
+		}
+		sort.Slice(books, func(i, j int) bool {
+			if !books[i].DeletedAt.Time.Equal(books[j].DeletedAt.Time) {
+				return books[i].DeletedAt.Time.After(books[j].DeletedAt.Time)
+			}
+			return books[i].ID < books[j].ID
+		})
+		books = paginate(books, limit, offset)
+		return nil
+	})
+	return books, err
+}
+
+func (r *MemoryBookRepository) Restore(id uint) (*models.Book, error) {
+	var book models.Book
+	err := r.write(func(d *memoryData) error {
+		trashed, ok := r.trashedBook(d, id)
+		if !ok {
+			return gorm.ErrRecordNotFound
+		}
+		trashed.DeletedAt = gorm.DeletedAt{}
+		trashed.Version++
+		trashed.UpdatedAt = time.Now()
+		d.books[id] = trashed
+		delete(d.trash, id)
+		book = d.withRelations(trashed)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &book, nil
+}
+
+func (r *MemoryBookRepository) Purge(id uint) error {
+	return r.write(func(d *memoryData) error {
+		if _, ok := r.trashedBook(d, id); !ok {
+			return gorm.ErrRecordNotFound
+		}
+		for _, bookCopy := range d.copies {
+			if bookCopy.BookID == id {
+				return models.ErrBookHasCopies
+			}
+		}
+		delete(d.trash, id)
+		delete(d.bookAuthors, id)
+		delete(d.bookTags, id)
+		for editionID, edition := range d.editions {
+			if edition.BookID == id {
+				delete(d.editions, editionID)
+			}
+		}
+		for holdID, hold := range d.holds {
+			if hold.BookID == id {
+				delete(d.holds, holdID)
+			}
+		}
+		return nil
+	})
+}
+
+func (r *MemoryBookRepository) EnsureAuthor(name string) (*models.Author, error) {
+	if r.tenant == "" {
+		return nil, models.ErrNoTenant
+	}
+	var author models.Author
+	err := r.write(func(d *memoryData) error {
+		if found := d.authorByName(r.tenant, name); found != nil {
+			author = *found
+			return nil
+		}
+		author.TenantID = r.tenant
+		author.Name = name
+		d.createAuthor(&author)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &author, nil
+}
+
+func (r *MemoryBookRepository) SetBookAuthors(bookID uint, authorIDs []uint) ([]models.Author, error) {
+	authors := []models.Author{}
+	err := r.write(func(d *memoryData) error {
+		seen := make(map[uint]bool)
+		var ids []uint
+		for _, id := range authorIDs {
+			author, ok := d.authors[id]
+			if !ok || author.TenantID != r.tenant {
+				return gorm.ErrRecordNotFound
+			}
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+				authors = append(authors, author)
+			}
+		}
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+		sort.Slice(authors, func(i, j int) bool { return authors[i].ID < authors[j].ID })
+		d.bookAuthors[bookID] = ids
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return authors, nil
+}
+
+func (r *MemoryBookRepository) AttachTag(bookID, tagID uint) ([]models.Tag, error) {
+	var tags []models.Tag
+	err := r.write(func(d *memoryData) error {
+		if tag, ok := d.tags[tagID]; !ok || tag.TenantID != r.tenant {
+			return gorm.ErrRecordNotFound
+		}
+		if !slices.Contains(d.bookTags[bookID], tagID) {
+			d.bookTags[bookID] = append(d.bookTags[bookID], tagID)
+			slices.Sort(d.bookTags[bookID])
+		}
+		tags = d.withRelations(models.Book{ID: bookID}).Tags
+		return nil
+	})
+	return tags, err
+}
+
+func (r *MemoryBookRepository) DetachTag(bookID, tagID uint) ([]models.Tag, error) {
+	var tags []models.Tag
+	err := r.write(func(d *memoryData) error {
+		d.bookTags[bookID] = slices.DeleteFunc(d.bookTags[bookID], func(id uint) bool { return id == tagID })
+		tags = d.withRelations(models.Book{ID: bookID}).Tags
+		return nil
+	})
+	return tags, err
+}
+
+func (r *MemoryBookRepository) Editions() EditionStore {
+	return &MemoryEditionRepository{memoryView: r.memoryView, tenant: r.tenant}
+}
+
+func (r *MemoryBookRepository) Authors() AuthorStore {
+	return &MemoryAuthorRepository{memoryView: r.memoryView, tenant: r.tenant}
+}
+
+func (r *MemoryBookRepository) Tags() TagStore {
+	return &MemoryTagRepository{memoryView: r.memoryView, tenant: r.tenant}
+}
+
+func (r *MemoryBookRepository) LockBooksByAuthor(authorID uint) ([]models.Book, error) {
+	return r.linkedBooks(func(d *memoryData) map[uint][]uint { return d.bookAuthors }, authorID)
+}
+
+func (r *MemoryBookRepository) LockBooksByTag(tagID uint) ([]models.Book, error) {
+	return r.linkedBooks(func(d *memoryData) map[uint][]uint { return d.bookTags }, tagID)
+}
+
+// linkedBooks returns the tenant's live books whose links include id.
+// Transactions hold the write lock, so there is nothing else to lock.
+func (r *MemoryBookRepository) linkedBooks(links func(d *memoryData) map[uint][]uint, id uint) ([]models.Book, error) {
+	books := []models.Book{}
+	err := r.read(func(d *memoryData) error {
+		for bookID, ids := range links(d) {
+			if book, ok := r.liveBook(d, bookID); ok && slices.Contains(ids, id) {
+				books = append(books, book)
+			}
+		}
+		sort.Slice(books, func(i, j int) bool { return books[i].ID < books[j].ID })
+		return nil
+	})
+	return books, err
+}
+
+func (r *MemoryBookRepository) Transaction(fn func(tx BookStore) error) error {
+	return r.transaction(func(tx memoryView) error {
+		return fn(&MemoryBookRepository{memoryView: tx, tenant: r.tenant})
+	})
+}
+
+func (r *MemoryBookRepository) EnqueueEvent(event *models.OutboxEvent) error {
+	return r.write(func(d *memoryData) error {
+		d.enqueueEvent(event)
+		return nil
+	})
+}
+
+func (r *MemoryBookRepository) ClaimEvents(limit int, lease time.Duration) ([]models.OutboxEvent, error) {
+	var claimed []models.OutboxEvent
+	err := r.write(func(d *memoryData) error {
+		claimed = d.claimEvents(limit, lease)
+		return nil
+	})
+	return claimed, err
+}
+
+func (r *MemoryBookRepository) SaveEvents(events []models.OutboxEvent) error {
+	return r.write(func(d *memoryData) error {
+		d.saveEvents(events)
+		return nil
+	})
+}
@@ -0,0 +1,43 @@
+package repositories
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shani34/book-management-system/internal/models"
+)
+
+func TestMemoryCreateRefusesExistingID(t *testing.T) {
+	repo := NewMemoryBookRepository(NewMemoryDB())
+	a, b := repo.ForTenant("a"), repo.ForTenant("b")
+
+	book := &models.Book{Title: "Dune", Author: "Frank Herbert"}
+	if err := a.Create(book); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	taken := &models.Book{ID: book.ID, Title: "Forged", Author: "Mallory"}
+	if err := b.Create(taken); err == nil {
+		t.Fatal("Create over an existing ID succeeded")
+	}
+	got, err := a.GetByID(book.ID)
+	if err != nil || got.Title != "Dune" || got.TenantID != "a" {
+		t.Errorf("book after refused create: got %+v, %v", got, err)
+	}
+
+	if err := a.Delete(book.ID, 0); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	trashed := &models.Book{ID: book.ID, Title: "Forged", Author: "Mallory"}
+	if err := b.Create(trashed); err == nil {
+		t.Error("Create over a trashed book's ID succeeded")
+	}
+}
+
+func TestMemoryCreateNeedsTenant(t *testing.T) {
+	repo := NewMemoryBookRepository(NewMemoryDB())
+	err := repo.Create(&models.Book{Title: "Dune", Author: "Frank Herbert"})
+	if !errors.Is(err, models.ErrNoTenant) {
+		t.Errorf("Create on an unbound store: got %v, want ErrNoTenant", err)
+	}
+}
@@ -0,0 +1,39 @@
+package repositories
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shani34/book-management-system/internal/models"
+)
+
+func TestUpdateChecksVersion(t *testing.T) {
+	repo := NewMemoryBookRepository(NewMemoryDB()).ForTenant("t")
+	book := models.Book{Title: "Dune", Author: "Frank Herbert", Year: 1965}
+	if err := repo.Create(&book); err != nil || book.Version != 1 {
+		t.Fatalf("create: got version %d, %v", book.Version, err)
+	}
+
+	first, second := book, book
+	first.Year = 1966
+	if err := repo.Update(&first); err != nil || first.Version != 2 {
+		t.Fatalf("first update: got version %d, %v", first.Version, err)
+	}
+	// The second writer read version 1 too and must not overwrite the first.
+	second.Year = 1967
+	if err := repo.Update(&second); !errors.Is(err, models.ErrVersionConflict) {
+		t.Fatalf("second update: got %v, want ErrVersionConflict", err)
+	}
+
+	stored, err := repo.GetByID(book.ID)
+	if err != nil || stored.Year != 1966 || stored.Version != 2 {
+		t.Errorf("stored: got %+v, %v", stored, err)
+	}
+
+	if err := repo.Delete(book.ID, 1); !errors.Is(err, models.ErrVersionConflict) {
+		t.Errorf("stale delete: got %v, want ErrVersionConflict", err)
+	}
+	if err := repo.Delete(book.ID, 0); err != nil {
+		t.Errorf("unconditional delete: %v", err)
+	}
+}
@@ -0,0 +1,454 @@
+package repositories
+
+import (
+	"sort"
+	"time"
+
+	"github.com/shani34/book-management-system/internal/models"
+	"gorm.io/gorm"
+)
+
+// MemoryCirculationRepository is the in-memory CirculationStore. Its
+// transactions hold the MemoryDB write lock, which serialises checkouts
+// the way LockCopy does in Postgres.
+type MemoryCirculationRepository struct {
+	memoryView
+	tenant string
+}
+
+// NewMemoryCirculationRepository returns a store bound to no tenant; see
+// ForTenant.
+func NewMemoryCirculationRepository(db *MemoryDB) *MemoryCirculationRepository {
+	return &MemoryCirculationRepository{memoryView: memoryView{db: db}}
+}
+
+func (r *MemoryCirculationRepository) ForTenant(tenantID string) CirculationStore {
+	return &MemoryCirculationRepository{memoryView: r.memoryView, tenant: tenantID}
+}
+
+func (r *MemoryCirculationRepository) GetCopies(bookID uint) ([]models.Copy, error) {
+	copies := []models.Copy{}
+	err := r.read(func(d *memoryData) error {
+		for _, bookCopy := range d.copies {
+			if bookCopy.TenantID == r.tenant && bookCopy.BookID == bookID {
+				copies = append(copies, bookCopy)
+			}
+		}
+		sort.Slice(copies, func(i, j int) bool { return copies[i].ID < copies[j].ID })
+		return nil
+	})
+	return copies, err
+}
+
+func (r *MemoryCirculationRepository) GetCopy(id uint) (*models.Copy, error) {
+	var bookCopy models.Copy
+	err := r.read(func(d *memoryData) error {
+		found, ok := d.copies[id]
+		if !ok || found.TenantID != r.tenant {
+			return gorm.ErrRecordNotFound
+		}
+		bookCopy = found
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &bookCopy, nil
+}
+
+func (r *MemoryCirculationRepository) GetCopyByBarcode(barcode string) (*models.Copy, error) {
+	var bookCopy models.Copy
+	err := r.read(func(d *memoryData) error {
+		for _, found := range d.copies {
+			if found.TenantID == r.tenant && found.Barcode == barcode {
+				bookCopy = found
+				return nil
+			}
+		}
+		return gorm.ErrRecordNotFound
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &bookCopy, nil
+}
+
+func (r *MemoryCirculationRepository) LockCopy(id uint) (*models.Copy, error) {
+	return r.GetCopy(id)
+}
+
+func (r *MemoryCirculationRepository) LockCopies(bookID uint) ([]models.Copy, error) {
+	return r.GetCopies(bookID)
+}
+
+func (r *MemoryCirculationRepository) CreateCopy(bookCopy *models.Copy) error {
+	if r.tenant == "" {
+		return models.ErrNoTenant
+	}
+	bookCopy.TenantID = r.tenant
+	return r.write(func(d *memoryData) error {
+		if d.barcodeTaken(bookCopy) {
+			return models.ErrDuplicateBarcode
+		}
+		now := time.Now()
+		bookCopy.ID = d.nextID("copies")
+		bookCopy.CreatedAt = now
+		bookCopy.UpdatedAt = now
+		d.copies[bookCopy.ID] = *bookCopy
+		return nil
+	})
+}
+
+func (r *MemoryCirculationRepository) UpdateCopy(bookCopy *models.Copy) error {
+	return r.write(func(d *memoryData) error {
+		stored, ok := d.copies[bookCopy.ID]
+		if !ok || stored.TenantID != r.tenant {
+			return gorm.ErrRecordNotFound
+		}
+		bookCopy.TenantID = stored.TenantID
+		if d.barcodeTaken(bookCopy) {
+			return models.ErrDuplicateBarcode
+		}
+		bookCopy.BookID = stored.BookID
+		bookCopy.CreatedAt = stored.CreatedAt
+		bookCopy.UpdatedAt = time.Now()
+		d.copies[bookCopy.ID] = *bookCopy
+		return nil
+	})
+}
+
+func (r *MemoryCirculationRepository) DeleteCopy(id uint) error {
+	return r.write(func(d *memoryData) error {
+		if bookCopy, ok := d.copies[id]; !ok || bookCopy.TenantID != r.tenant {
+			return gorm.ErrRecordNotFound
+		}
+		for _, loan := range d.loans {
+			if loan.CopyID == id {
+				return models.ErrCopyHasLoans
+			}
+		}
+		delete(d.copies, id)
+		return nil
+	})
+}
+
+func (r *MemoryCirculationRepository) GetMember(id uint) (*models.Member, error) {
+	var member models.Member
+	err := r.read(func(d *memoryData) error {
+		found, ok := d.members[id]
+		if !ok || found.TenantID != r.tenant {
+			return gorm.ErrRecordNotFound
+		}
+		member = d.withTier(found)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+func (r *MemoryCirculationRepository) LockMember(id uint) (*models.Member, error) {
+	return r.GetMember(id)
+}
+
+func (r *MemoryCirculationRepository) CountActiveLoans(memberID uint) (int64, error) {
+	var count int64
+	err := r.read(func(d *memoryData) error {
+		for _, loan := range d.loans {
+			if loan.TenantID == r.tenant && loan.MemberID == memberID && loan.ReturnedAt == nil {
+				count++
+			}
+		}
+		return nil
+	})
+	return count, err
+}
+
+func (r *MemoryCirculationRepository) GetLoans(filter models.LoanFilter, limit, offset int) ([]models.Loan, error) {
+	loans := []models.Loan{}
+	err := r.read(func(d *memoryData) error {
+		for _, loan := range d.loans {
+			if loan.TenantID == r.tenant && matchesLoanFilter(loan, filter) {
+				loans = append(loans, loan)
+			}
+		}
+		sort.Slice(loans, func(i, j int) bool {
+			if !loans[i].CheckedOutAt.Equal(loans[j].CheckedOutAt) {
+				return loans[i].CheckedOutAt.After(loans[j].CheckedOutAt)
+			}
+			return loans[i].ID > loans[j].ID
+		})
+		loans = paginate(loans, limit, offset)
+		return nil
+	})
+	return loans, err
+}
+
+func (r *MemoryCirculationRepository) GetLoan(id uint) (*models.Loan, error) {
+	var loan models.Loan
+	err := r.read(func(d *memoryData) error {
+		found, ok := d.loans[id]
+		if !ok || found.TenantID != r.tenant {
+			return gorm.ErrRecordNotFound
+		}
+		loan = found
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &loan, nil
+}
+
+func (r *MemoryCirculationRepository) CreateLoan(loan *models.Loan) error {
+	if r.tenant == "" {
+		return models.ErrNoTenant
+	}
+	loan.TenantID = r.tenant
+	return r.write(func(d *memoryData) error {
+		for _, other := range d.loans {
+			if other.CopyID == loan.CopyID && other.ReturnedAt == nil {
+				return models.ErrCopyUnavailable
+			}
+		}
+		now := time.Now()
+		loan.ID = d.nextID("loans")
+		loan.CreatedAt = now
+		loan.UpdatedAt = now
+		d.loans[loan.ID] = *loan
+		return nil
+	})
+}
+
+func (r *MemoryCirculationRepository) ReturnLoan(loan *models.Loan) error {
+	return r.write(func(d *memoryData) error {
+		stored, ok := d.loans[loan.ID]
+		if !ok || stored.TenantID != r.tenant {
+			return gorm.ErrRecordNotFound
+		}
+		if stored.ReturnedAt != nil {
+			return models.ErrLoanReturned
+		}
+		now := time.Now()
+		stored.ReturnedAt = &now
+		stored.UpdatedAt = now
+		d.loans[loan.ID] = stored
+		*loan = stored
+		return nil
+	})
+}
+
+func (r *MemoryCirculationRepository) GetHolds(filter models.HoldFilter, limit, offset int) ([]models.Hold, error) {
+	holds := []models.Hold{}
+	err := r.read(func(d *memoryData) error {
+		for _, hold := range d.holds {
+			if hold.TenantID == r.tenant && matchesHoldFilter(hold, filter) {
+				holds = append(holds, d.withPosition(hold))
+			}
+		}
+		sort.Slice(holds, func(i, j int) bool { return holds[i].ID < holds[j].ID })
+		holds = paginate(holds, limit, offset)
+		return nil
+	})
+	return holds, err
+}
+
+func (r *MemoryCirculationRepository) GetHold(id uint) (*models.Hold, error) {
+	var hold models.Hold
+	err := r.read(func(d *memoryData) error {
+		found, ok := d.holds[id]
+		if !ok || found.TenantID != r.tenant {
+			return gorm.ErrRecordNotFound
+		}
+		hold = d.withPosition(found)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &hold, nil
+}
+
+func (r *MemoryCirculationRepository) LockHold(id uint) (*models.Hold, error) {
+	return r.GetHold(id)
+}
+
+func (r *MemoryCirculationRepository) CreateHold(hold *models.Hold) error {
+	if r.tenant == "" {
+		return models.ErrNoTenant
+	}
+	hold.TenantID = r.tenant
+	return r.write(func(d *memoryData) error {
+		for _, other := range d.holds {
+			if other.BookID == hold.BookID && other.MemberID == hold.MemberID && other.Active() {
+				return models.ErrDuplicateHold
+			}
+		}
+		now := time.Now()
+		hold.ID = d.nextID("holds")
+		hold.Position = 0
+		hold.CreatedAt = now
+		hold.UpdatedAt = now
+		d.holds[hold.ID] = *hold
+		return nil
+	})
+}
+
+func (r *MemoryCirculationRepository) UpdateHold(hold *models.Hold) error {
+	return r.write(func(d *memoryData) error {
+		stored, ok := d.holds[hold.ID]
+		if !ok || stored.TenantID != r.tenant {
+			return gorm.ErrRecordNotFound
+		}
+		stored.Status = hold.Status
+		stored.CopyID = hold.CopyID
+		stored.ReadyAt = hold.ReadyAt
+		stored.PickupBy = hold.PickupBy
+		stored.UpdatedAt = time.Now()
+		d.holds[hold.ID] = stored
+		hold.UpdatedAt = stored.UpdatedAt
+		return nil
+	})
+}
+
+func (r *MemoryCirculationRepository) NextHold(bookID uint) (*models.Hold, error) {
+	var next *models.Hold
+	err := r.read(func(d *memoryData) error {
+		for _, hold := range d.holds {
+			if hold.TenantID == r.tenant && hold.BookID == bookID && hold.Status == models.HoldWaiting && (next == nil || hold.ID < next.ID) {
+				found := hold
+				next = &found
+			}
+		}
+		if next == nil {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return next, nil
+}
+
+func (r *MemoryCirculationRepository) GetReadyHold(copyID uint) (*models.Hold, error) {
+	var hold models.Hold
+	err := r.read(func(d *memoryData) error {
+		for _, found := range d.holds {
+			if found.TenantID == r.tenant && found.Status == models.HoldReady && found.CopyID != nil && *found.CopyID == copyID {
+				hold = found
+				return nil
+			}
+		}
+		return gorm.ErrRecordNotFound
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &hold, nil
+}
+
+func (r *MemoryCirculationRepository) GetExpiredHolds(now time.Time, limit int) ([]models.Hold, error) {
+	holds := []models.Hold{}
+	err := r.read(func(d *memoryData) error {
+		for _, hold := range d.holds {
+			if hold.Status == models.HoldReady && hold.PickupBy != nil && hold.PickupBy.Before(now) {
+				holds = append(holds, hold)
+			}
+		}
+		sort.Slice(holds, func(i, j int) bool {
+			if !holds[i].PickupBy.Equal(*holds[j].PickupBy) {
+				return holds[i].PickupBy.Before(*holds[j].PickupBy)
+			}
+			return holds[i].ID < holds[j].ID
+		})
+		holds = paginate(holds, limit, 0)
+		return nil
+	})
+	return holds, err
+}
+
+func (r *MemoryCirculationRepository) EnqueueEvent(event *models.OutboxEvent) error {
+	return r.write(func(d *memoryData) error {
+		d.enqueueEvent(event)
+		return nil
+	})
+}
+
+func (r *MemoryCirculationRepository) ClaimEvents(limit int, lease time.Duration) ([]models.OutboxEvent, error) {
+	var claimed []models.OutboxEvent
+	err := r.write(func(d *memoryData) error {
+		claimed = d.claimEvents(limit, lease)
+		return nil
+	})
+	return claimed, err
+}
+
+func (r *MemoryCirculationRepository) SaveEvents(events []models.OutboxEvent) error {
+	return r.write(func(d *memoryData) error {
+		d.saveEvents(events)
+		return nil
+	})
+}
+
+func (r *MemoryCirculationRepository) Transaction(fn func(tx CirculationStore) error) error {
+	return r.transaction(func(tx memoryView) error {
+		return fn(&MemoryCirculationRepository{memoryView: tx, tenant: r.tenant})
+	})
+}
+
+func matchesLoanFilter(loan models.Loan, filter models.LoanFilter) bool {
+	if filter.BookID != 0 && loan.BookID != filter.BookID {
+		return false
+	}
+	if filter.CopyID != 0 && loan.CopyID != filter.CopyID {
+		return false
+	}
+	if filter.MemberID != 0 && loan.MemberID != filter.MemberID {
+		return false
+	}
+	if filter.ActiveOnly && loan.ReturnedAt != nil {
+		return false
+	}
+	return true
+}
+
+func matchesHoldFilter(hold models.Hold, filter models.HoldFilter) bool {
+	if filter.BookID != 0 && hold.BookID != filter.BookID {
+		return false
+	}
+	if filter.MemberID != 0 && hold.MemberID != filter.MemberID {
+		return false
+	}
+	if filter.Status != "" && hold.Status != filter.Status {
+		return false
+	}
+	return true
+}
+
+// withPosition fills in the queue position of a waiting hold, as the
+// subquery in CirculationRepository.holdsWithPosition does.
+func (d *memoryData) withPosition(hold models.Hold) models.Hold {
+	hold.Position = 0
+	if hold.Status != models.HoldWaiting {
+		return hold
+	}
+	for _, ahead := range d.holds {
+		if ahead.BookID == hold.BookID && ahead.Status == models.HoldWaiting && ahead.ID <= hold.ID {
+			hold.Position++
+		}
+	}
+	return hold
+}
+
+// barcodeTaken reports whether another copy of the same tenant has the
+// copy's barcode, like the unique index on copies.
+func (d *memoryData) barcodeTaken(bookCopy *models.Copy) bool {
+	for _, other := range d.copies {
+		if other.ID != bookCopy.ID && other.TenantID == bookCopy.TenantID && other.Barcode == bookCopy.Barcode {
+			return true
+		}
+	}
+	return false
+}
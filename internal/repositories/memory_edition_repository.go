@@ -0,0 +1,131 @@
+package repositories
+
+import (
+	"sort"
+	"time"
+
+	"github.com/shani34/book-management-system/internal/models"
+	"gorm.io/gorm"
+)
+
+// MemoryEditionRepository is the in-memory EditionStore. It must share its
+// MemoryDB with the MemoryBookRepository so purges remove editions too.
+type MemoryEditionRepository struct {
+	memoryView
+	tenant string
+}
+
+// NewMemoryEditionRepository returns a store bound to no tenant; see
+// ForTenant.
+func NewMemoryEditionRepository(db *MemoryDB) *MemoryEditionRepository {
+	return &MemoryEditionRepository{memoryView: memoryView{db: db}}
+}
+
+func (r *MemoryEditionRepository) ForTenant(tenantID string) EditionStore {
+	return &MemoryEditionRepository{memoryView: r.memoryView, tenant: tenantID}
+}
+
+// edition returns the tenant's edition with id, if it belongs to bookID.
+func (r *MemoryEditionRepository) edition(d *memoryData, bookID, id uint) (models.Edition, bool) {
+	edition, ok := d.editions[id]
+	return edition, ok && edition.TenantID == r.tenant && edition.BookID == bookID
+}
+
+func (r *MemoryEditionRepository) GetByBook(bookID uint) ([]models.Edition, error) {
+	editions := []models.Edition{}
+	err := r.read(func(d *memoryData) error {
+		for _, edition := range d.bookEditions(bookID) {
+			if edition.TenantID == r.tenant {
+				editions = append(editions, edition)
+			}
+		}
+		return nil
+	})
+	return editions, err
+}
+
+func (r *MemoryEditionRepository) GetByID(bookID, id uint) (*models.Edition, error) {
+	var edition models.Edition
+	err := r.read(func(d *memoryData) error {
+		found, ok := r.edition(d, bookID, id)
+		if !ok {
+			return gorm.ErrRecordNotFound
+		}
+		edition = found
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &edition, nil
+}
+
+func (r *MemoryEditionRepository) Create(edition *models.Edition) error {
+	if r.tenant == "" {
+		return models.ErrNoTenant
+	}
+	edition.TenantID = r.tenant
+	return r.write(func(d *memoryData) error {
+		if d.editionISBNTaken(edition) {
+			return models.ErrDuplicateISBN
+		}
+		now := time.Now()
+		edition.ID = d.nextID("editions")
+		edition.CreatedAt = now
+		edition.UpdatedAt = now
+		d.editions[edition.ID] = *edition
+		return nil
+	})
+}
+
+func (r *MemoryEditionRepository) Update(edition *models.Edition) error {
+	return r.write(func(d *memoryData) error {
+		stored, ok := r.edition(d, edition.BookID, edition.ID)
+		if !ok {
+			return gorm.ErrRecordNotFound
+		}
+		edition.TenantID = stored.TenantID
+		if d.editionISBNTaken(edition) {
+			return models.ErrDuplicateISBN
+		}
+		edition.CreatedAt = stored.CreatedAt
+		edition.UpdatedAt = time.Now()
+		d.editions[edition.ID] = *edition
+		return nil
+	})
+}
+
+func (r *MemoryEditionRepository) Delete(bookID, id uint) error {
+	return r.write(func(d *memoryData) error {
+		if _, ok := r.edition(d, bookID, id); !ok {
+			return gorm.ErrRecordNotFound
+		}
+		delete(d.editions, id)
+		return nil
+	})
+}
+
+func (d *memoryData) bookEditions(bookID uint) []models.Edition {
+	editions := []models.Edition{}
+	for _, edition := range d.editions {
+		if edition.BookID == bookID {
+			editions = append(editions, edition)
+		}
+	}
+	sort.Slice(editions, func(i, j int) bool { return editions[i].ID < editions[j].ID })
+	return editions
+}
+
+// editionISBNTaken reports whether another edition of the same tenant has
+// the edition's ISBN, like idx_editions_tenant_isbn.
+func (d *memoryData) editionISBNTaken(edition *models.Edition) bool {
+	if edition.ISBN == "" {
+		return false
+	}
+	for _, other := range d.editions {
+		if other.ID != edition.ID && other.TenantID == edition.TenantID && other.ISBN == edition.ISBN {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+package repositories
+
+import (
+	"sort"
+	"time"
+
+	"github.com/shani34/book-management-system/internal/models"
+	"gorm.io/gorm"
+)
+
+func (r *MemoryCirculationRepository) GetFines(filter models.FineFilter, limit, offset int) ([]models.Fine, error) {
+	fines := []models.Fine{}
+	err := r.read(func(d *memoryData) error {
+		for _, fine := range d.fines {
+			if fine.TenantID != r.tenant {
+				continue
+			}
+			if filter.MemberID != 0 && fine.MemberID != filter.MemberID {
+				continue
+			}
+			if filter.OpenOnly && fine.Balance <= 0 {
+				continue
+			}
+			fines = append(fines, fine)
+		}
+		sort.Slice(fines, func(i, j int) bool { return fines[i].ID > fines[j].ID })
+		fines = paginate(fines, limit, offset)
+		return nil
+	})
+	return fines, err
+}
+
+func (r *MemoryCirculationRepository) GetFine(id uint) (*models.Fine, error) {
+	var fine models.Fine
+	err := r.read(func(d *memoryData) error {
+		found, ok := d.fines[id]
+		if !ok || found.TenantID != r.tenant {
+			return gorm.ErrRecordNotFound
+		}
+		fine = found
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &fine, nil
+}
+
+func (r *MemoryCirculationRepository) LockFine(id uint) (*models.Fine, error) {
+	return r.GetFine(id)
+}
+
+func (r *MemoryCirculationRepository) CreateFine(fine *models.Fine) error {
+	if r.tenant == "" {
+		return models.ErrNoTenant
+	}
+	fine.TenantID = r.tenant
+	return r.write(func(d *memoryData) error {
+		for _, other := range d.fines {
+			if other.LoanID == fine.LoanID {
+				return gorm.ErrDuplicatedKey
+			}
+		}
+		now := time.Now()
+		fine.ID = d.nextID("fines")
+		fine.CreatedAt = now
+		fine.UpdatedAt = now
+		d.fines[fine.ID] = *fine
+		return nil
+	})
+}
+
+func (r *MemoryCirculationRepository) UpdateFine(fine *models.Fine) error {
+	return r.write(func(d *memoryData) error {
+		stored, ok := d.fines[fine.ID]
+		if !ok || stored.TenantID != r.tenant {
+			return gorm.ErrRecordNotFound
+		}
+		stored.Paid = fine.Paid
+		stored.Waived = fine.Waived
+		stored.Balance = fine.Balance
+		stored.UpdatedAt = time.Now()
+		d.fines[fine.ID] = stored
+		fine.UpdatedAt = stored.UpdatedAt
+		return nil
+	})
+}
+
+func (r *MemoryCirculationRepository) GetFineBalance(memberID uint) (int64, error) {
+	var balance int64
+	err := r.read(func(d *memoryData) error {
+		for _, fine := range d.fines {
+			if fine.TenantID == r.tenant && fine.MemberID == memberID {
+				balance += fine.Balance
+			}
+		}
+		return nil
+	})
+	return balance, err
+}
+
+func (r *MemoryCirculationRepository) AddLedgerEntry(entry *models.LedgerEntry) error {
+	if r.tenant == "" {
+		return models.ErrNoTenant
+	}
+	entry.TenantID = r.tenant
+	return r.write(func(d *memoryData) error {
+		entry.ID = d.nextID("fine_ledger")
+		entry.CreatedAt = time.Now()
+		d.ledger = append(d.ledger, *entry)
+		return nil
+	})
+}
+
+func (r *MemoryCirculationRepository) GetLedger(memberID uint, limit, offset int) ([]models.LedgerEntry, error) {
+	entries := []models.LedgerEntry{}
+	err := r.read(func(d *memoryData) error {
+		for _, entry := range d.ledger {
+			if entry.TenantID == r.tenant && entry.MemberID == memberID {
+				entries = append(entries, entry)
+			}
+		}
+		entries = paginate(entries, limit, offset)
+		return nil
+	})
+	return entries, err
+}
@@ -0,0 +1,249 @@
+package repositories
+
+import (
+	"sort"
+	"time"
+
+	"github.com/shani34/book-management-system/internal/models"
+	"gorm.io/gorm"
+)
+
+// MemoryMemberRepository is the in-memory MemberStore. It must share its
+// MemoryDB with the MemoryCirculationRepository so loans and members stay
+// consistent.
+type MemoryMemberRepository struct {
+	memoryView
+	tenant string
+}
+
+// NewMemoryMemberRepository returns a store bound to no tenant; see
+// ForTenant.
+func NewMemoryMemberRepository(db *MemoryDB) *MemoryMemberRepository {
+	return &MemoryMemberRepository{memoryView: memoryView{db: db}}
+}
+
+func (r *MemoryMemberRepository) ForTenant(tenantID string) MemberStore {
+	return &MemoryMemberRepository{memoryView: r.memoryView, tenant: tenantID}
+}
+
+func (r *MemoryMemberRepository) GetAll(limit, offset int) ([]models.Member, error) {
+	members := []models.Member{}
+	err := r.read(func(d *memoryData) error {
+		for _, member := range d.members {
+			if member.TenantID != r.tenant {
+				continue
+			}
+			members = append(members, d.withTier(member))
+		}
+		sort.Slice(members, func(i, j int) bool {
+			if members[i].Name != members[j].Name {
+				return members[i].Name < members[j].Name
+			}
+			return members[i].ID < members[j].ID
+		})
+		members = paginate(members, limit, offset)
+		return nil
+	})
+	return members, err
+}
+
+func (r *MemoryMemberRepository) GetByID(id uint) (*models.Member, error) {
+	var member models.Member
+	err := r.read(func(d *memoryData) error {
+		found, ok := d.members[id]
+		if !ok || found.TenantID != r.tenant {
+			return gorm.ErrRecordNotFound
+		}
+		member = d.withTier(found)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+func (r *MemoryMemberRepository) Create(member *models.Member) error {
+	if r.tenant == "" {
+		return models.ErrNoTenant
+	}
+	member.TenantID = r.tenant
+	return r.write(func(d *memoryData) error {
+		if d.emailTaken(member) {
+			return models.ErrDuplicateEmail
+		}
+		if tier, ok := d.tiers[member.TierID]; !ok || tier.TenantID != r.tenant {
+			return gorm.ErrForeignKeyViolated
+		}
+		now := time.Now()
+		member.ID = d.nextID("members")
+		member.CreatedAt = now
+		member.UpdatedAt = now
+		member.Tier = nil
+		d.members[member.ID] = *member
+		return nil
+	})
+}
+
+func (r *MemoryMemberRepository) Update(member *models.Member) error {
+	return r.write(func(d *memoryData) error {
+		stored, ok := d.members[member.ID]
+		if !ok || stored.TenantID != r.tenant {
+			return gorm.ErrRecordNotFound
+		}
+		member.TenantID = stored.TenantID
+		if d.emailTaken(member) {
+			return models.ErrDuplicateEmail
+		}
+		if tier, ok := d.tiers[member.TierID]; !ok || tier.TenantID != r.tenant {
+			return gorm.ErrForeignKeyViolated
+		}
+		member.CreatedAt = stored.CreatedAt
+		member.UpdatedAt = time.Now()
+		member.Tier = nil
+		d.members[member.ID] = *member
+		return nil
+	})
+}
+
+func (r *MemoryMemberRepository) Delete(id uint) error {
+	return r.write(func(d *memoryData) error {
+		if member, ok := d.members[id]; !ok || member.TenantID != r.tenant {
+			return gorm.ErrRecordNotFound
+		}
+		for _, loan := range d.loans {
+			if loan.MemberID == id {
+				return models.ErrMemberHasLoans
+			}
+		}
+		for _, hold := range d.holds {
+			if hold.MemberID == id && hold.Active() {
+				return models.ErrMemberHasHolds
+			}
+		}
+		for holdID, hold := range d.holds {
+			if hold.MemberID == id {
+				delete(d.holds, holdID)
+			}
+		}
+		delete(d.members, id)
+		return nil
+	})
+}
+
+func (r *MemoryMemberRepository) GetTiers() ([]models.Tier, error) {
+	tiers := []models.Tier{}
+	err := r.read(func(d *memoryData) error {
+		for _, tier := range d.tiers {
+			if tier.TenantID == r.tenant {
+				tiers = append(tiers, tier)
+			}
+		}
+		sort.Slice(tiers, func(i, j int) bool { return tiers[i].ID < tiers[j].ID })
+		return nil
+	})
+	return tiers, err
+}
+
+func (r *MemoryMemberRepository) GetTier(id uint) (*models.Tier, error) {
+	var tier models.Tier
+	err := r.read(func(d *memoryData) error {
+		found, ok := d.tiers[id]
+		if !ok || found.TenantID != r.tenant {
+			return gorm.ErrRecordNotFound
+		}
+		tier = found
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &tier, nil
+}
+
+func (r *MemoryMemberRepository) CreateTier(tier *models.Tier) error {
+	if r.tenant == "" {
+		return models.ErrNoTenant
+	}
+	tier.TenantID = r.tenant
+	return r.write(func(d *memoryData) error {
+		if d.tierNameTaken(tier) {
+			return models.ErrDuplicateTier
+		}
+		d.createTier(tier)
+		return nil
+	})
+}
+
+func (r *MemoryMemberRepository) UpdateTier(tier *models.Tier) error {
+	return r.write(func(d *memoryData) error {
+		stored, ok := d.tiers[tier.ID]
+		if !ok || stored.TenantID != r.tenant {
+			return gorm.ErrRecordNotFound
+		}
+		tier.TenantID = stored.TenantID
+		if d.tierNameTaken(tier) {
+			return models.ErrDuplicateTier
+		}
+		tier.CreatedAt = stored.CreatedAt
+		tier.UpdatedAt = time.Now()
+		d.tiers[tier.ID] = *tier
+		return nil
+	})
+}
+
+func (r *MemoryMemberRepository) SeedTiers(tiers []models.Tier) error {
+	if r.tenant == "" {
+		return models.ErrNoTenant
+	}
+	return r.write(func(d *memoryData) error {
+		for _, tier := range d.tiers {
+			if tier.TenantID == r.tenant {
+				return nil
+			}
+		}
+		for i := range tiers {
+			tiers[i].TenantID = r.tenant
+			d.createTier(&tiers[i])
+		}
+		return nil
+	})
+}
+
+// withTier returns member with its Tier filled in, like Preload("Tier").
+func (d *memoryData) withTier(member models.Member) models.Member {
+	if tier, ok := d.tiers[member.TierID]; ok {
+		member.Tier = &tier
+	}
+	return member
+}
+
+func (d *memoryData) createTier(tier *models.Tier) {
+	now := time.Now()
+	tier.ID = d.nextID("tiers")
+	tier.CreatedAt = now
+	tier.UpdatedAt = now
+	d.tiers[tier.ID] = *tier
+}
+
+// emailTaken reports whether another member of the same tenant has the
+// member's email, like the unique index on members.
+func (d *memoryData) emailTaken(member *models.Member) bool {
+	for _, other := range d.members {
+		if other.ID != member.ID && other.TenantID == member.TenantID && other.Email == member.Email {
+			return true
+		}
+	}
+	return false
+}
+
+// tierNameTaken reports whether another tier of the same tenant has the
+// tier's name, like the unique index on tiers.
+func (d *memoryData) tierNameTaken(tier *models.Tier) bool {
+	for _, other := range d.tiers {
+		if other.ID != tier.ID && other.TenantID == tier.TenantID && other.Name == tier.Name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/shani34/book-management-system/internal/models"
+)
+
+func (d *memoryData) enqueueEvent(event *models.OutboxEvent) {
+	now := time.Now()
+	event.ID = d.nextID("outbox_events")
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = now
+	}
+	if event.NextAttemptAt.IsZero() {
+		event.NextAttemptAt = now
+	}
+	d.outbox = append(d.outbox, *event)
+}
+
+func (d *memoryData) claimEvents(limit int, lease time.Duration) []models.OutboxEvent {
+	now := time.Now()
+	var claimed []models.OutboxEvent
+	for i := range d.outbox {
+		if len(claimed) >= limit {
+			break
+		}
+		event := &d.outbox[i]
+		if event.SentAt != nil || event.NextAttemptAt.After(now) {
+			continue
+		}
+		event.NextAttemptAt = now.Add(lease)
+		claimed = append(claimed, *event)
+	}
+	return claimed
+}
+
+func (d *memoryData) saveEvents(events []models.OutboxEvent) {
+	saved := make(map[uint]models.OutboxEvent, len(events))
+	for _, event := range events {
+		saved[event.ID] = event
+	}
+	for i, event := range d.outbox {
+		if update, ok := saved[event.ID]; ok {
+			d.outbox[i].Attempts = update.Attempts
+			d.outbox[i].LastError = update.LastError
+			d.outbox[i].NextAttemptAt = update.NextAttemptAt
+			d.outbox[i].SentAt = update.SentAt
+		}
+	}
+
+	// Drop delivered events so a long-running process doesn't grow without
+	// bound; the Postgres outbox keeps them for auditing instead.
+	pending := d.outbox[:0]
+	for _, event := range d.outbox {
+		if event.SentAt == nil {
+			pending = append(pending, event)
+		}
+	}
+	d.outbox = pending
+}
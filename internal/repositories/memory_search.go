@@ -0,0 +1,125 @@
+package repositories
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/shani34/book-management-system/internal/models"
+)
+
+// Search approximates the Postgres full-text search: every query term must
+// prefix-match a word in the title or author, title hits outrank author
+// hits, and no stemming is applied.
+func (r *MemoryBookRepository) Search(query string, limit, offset int) ([]models.BookSearchResult, error) {
+	terms := searchTerms(query)
+	var results []models.BookSearchResult
+	err := r.read(func(d *memoryData) error {
+		if len(terms) == 0 {
+			return nil
+		}
+		for _, book := range d.books {
+			if book.TenantID != r.tenant {
+				continue
+			}
+			titleHits, titleHighlight := highlightTerms(book.Title, terms)
+			authorHits, authorHighlight := highlightTerms(book.Author, terms)
+			if !allMatched(terms, titleHits, authorHits) {
+				continue
+			}
+			results = append(results, models.BookSearchResult{
+				Book:            book,
+				Rank:            float64(len(titleHits)) + 0.4*float64(len(authorHits)),
+				TitleHighlight:  titleHighlight,
+				AuthorHighlight: authorHighlight,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Rank != results[j].Rank {
+			return results[i].Rank > results[j].Rank
+		}
+		return results[i].ID < results[j].ID
+	})
+	return paginate(results, limit, offset), nil
+}
+
+func searchTerms(query string) []string {
+	return strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// highlightTerms wraps every word of text that starts with one of terms in
+// <mark> tags and reports which terms matched.
+func highlightTerms(text string, terms []string) (map[string]bool, string) {
+	hits := make(map[string]bool)
+	var b strings.Builder
+	word := func(w string) {
+		lower := strings.ToLower(w)
+		for _, term := range terms {
+			if strings.HasPrefix(lower, term) {
+				hits[term] = true
+				b.WriteString("<mark>" + w + "</mark>")
+				return
+			}
+		}
+		b.WriteString(w)
+	}
+
+	start := -1
+	for i, r := range text {
+		isWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		switch {
+		case isWord && start < 0:
+			start = i
+		case !isWord && start >= 0:
+			word(text[start:i])
+			start = -1
+			b.WriteRune(r)
+		case !isWord:
+			b.WriteRune(r)
+		}
+	}
+	if start >= 0 {
+		word(text[start:])
+	}
+	return hits, b.String()
+}
+
+func allMatched(terms []string, hits ...map[string]bool) bool {
+	for _, term := range terms {
+		found := false
+		for _, h := range hits {
+			if h[term] {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// paginate applies limit/offset the way GORM does: a negative limit means
+// no limit.
+func paginate[T any](items []T, limit, offset int) []T {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > len(items) {
+		offset = len(items)
+	}
+	items = items[offset:]
+	if limit >= 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	return items
+}
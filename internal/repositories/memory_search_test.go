@@ -0,0 +1,121 @@
+package repositories
+
+import (
+	"testing"
+
+	"github.com/shani34/book-management-system/internal/models"
+)
+
+func newSearchRepo(t *testing.T, books ...models.Book) BookStore {
+	t.Helper()
+	repo := NewMemoryBookRepository(NewMemoryDB()).ForTenant("t")
+	for i := range books {
+		if err := repo.Create(&books[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return repo
+}
+
+func searchIDs(results []models.BookSearchResult) []uint {
+	ids := make([]uint, len(results))
+	for i, result := range results {
+		ids[i] = result.ID
+	}
+	return ids
+}
+
+func TestSearch(t *testing.T) {
+	repo := newSearchRepo(t,
+		models.Book{Title: "The Go Programming Language", Author: "Alan Donovan"},
+		models.Book{Title: "Learning Python", Author: "Mark Lutz"},
+		models.Book{Title: "Programming Pearls", Author: "Jon Bentley"},
+		models.Book{Title: "Concurrency in Practice", Author: "Brian Go"},
+	)
+
+	tests := []struct {
+		query string
+		want  []uint
+	}{
+		// Prefixes match whole words, case-insensitively.
+		{"prog", []uint{1, 3}},
+		{"PYTHON", []uint{2}},
+		{"gram", nil},
+		// Every term has to match, in the title or the author.
+		{"programming donovan", []uint{1}},
+		{"programming lutz", nil},
+		// A title hit outranks an author hit.
+		{"go", []uint{1, 4}},
+		// Punctuation only separates terms.
+		{"go,  language!", []uint{1}},
+		{"  ", nil},
+	}
+	for _, tt := range tests {
+		results, err := repo.Search(tt.query, -1, 0)
+		if err != nil {
+			t.Fatalf("Search(%q): %v", tt.query, err)
+		}
+		if got := searchIDs(results); !equalIDs(got, tt.want) {
+			t.Errorf("Search(%q): got %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestSearchHighlights(t *testing.T) {
+	repo := newSearchRepo(t, models.Book{Title: "The Go Programming Language", Author: "Alan Donovan"})
+
+	results, err := repo.Search("go don", -1, 0)
+	if err != nil || len(results) != 1 {
+		t.Fatalf("got %v, %v; want one result", results, err)
+	}
+	result := results[0]
+	if result.TitleHighlight != "The <mark>Go</mark> Programming Language" {
+		t.Errorf("title highlight: got %q", result.TitleHighlight)
+	}
+	if result.AuthorHighlight != "Alan <mark>Donovan</mark>" {
+		t.Errorf("author highlight: got %q", result.AuthorHighlight)
+	}
+	if result.Rank != 1.4 {
+		t.Errorf("rank: got %v, want 1.4", result.Rank)
+	}
+}
+
+func TestSearchPagination(t *testing.T) {
+	repo := newSearchRepo(t,
+		models.Book{Title: "Dune", Author: "Frank Herbert"},
+		models.Book{Title: "Dune Messiah", Author: "Frank Herbert"},
+		models.Book{Title: "Children of Dune", Author: "Frank Herbert"},
+	)
+
+	tests := []struct {
+		limit, offset int
+		want          []uint
+	}{
+		{-1, 0, []uint{1, 2, 3}},
+		{2, 0, []uint{1, 2}},
+		{2, 2, []uint{3}},
+		{2, 5, nil},
+		{0, 0, nil},
+	}
+	for _, tt := range tests {
+		results, err := repo.Search("dune", tt.limit, tt.offset)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := searchIDs(results); !equalIDs(got, tt.want) {
+			t.Errorf("limit %d offset %d: got %v, want %v", tt.limit, tt.offset, got, tt.want)
+		}
+	}
+}
+
+func equalIDs(a, b []uint) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
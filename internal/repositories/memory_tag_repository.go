@@ -0,0 +1,129 @@
+package repositories
+
+import (
+	"sort"
+	"time"
+
+	"github.com/shani34/book-management-system/internal/models"
+	"gorm.io/gorm"
+)
+
+// MemoryTagRepository is the in-memory TagStore. Like the author store it
+// must share its MemoryDB with the MemoryBookRepository.
+type MemoryTagRepository struct {
+	memoryView
+	tenant string
+}
+
+// NewMemoryTagRepository returns a store bound to no tenant; see ForTenant.
+func NewMemoryTagRepository(db *MemoryDB) *MemoryTagRepository {
+	return &MemoryTagRepository{memoryView: memoryView{db: db}}
+}
+
+func (r *MemoryTagRepository) ForTenant(tenantID string) TagStore {
+	return &MemoryTagRepository{memoryView: r.memoryView, tenant: tenantID}
+}
+
+// tag returns the tenant's tag with id.
+func (r *MemoryTagRepository) tag(d *memoryData, id uint) (models.Tag, bool) {
+	tag, ok := d.tags[id]
+	return tag, ok && tag.TenantID == r.tenant
+}
+
+func (r *MemoryTagRepository) GetAll(limit, offset int) ([]models.Tag, error) {
+	var tags []models.Tag
+	err := r.read(func(d *memoryData) error {
+		tags = make([]models.Tag, 0, len(d.tags))
+		for _, tag := range d.tags {
+			if tag.TenantID == r.tenant {
+				tags = append(tags, tag)
+			}
+		}
+		sort.Slice(tags, func(i, j int) bool {
+			if tags[i].Name != tags[j].Name {
+				return tags[i].Name < tags[j].Name
+			}
+			return tags[i].ID < tags[j].ID
+		})
+		tags = paginate(tags, limit, offset)
+		return nil
+	})
+	return tags, err
+}
+
+func (r *MemoryTagRepository) GetByID(id uint) (*models.Tag, error) {
+	var tag models.Tag
+	err := r.read(func(d *memoryData) error {
+		found, ok := r.tag(d, id)
+		if !ok {
+			return gorm.ErrRecordNotFound
+		}
+		tag = found
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+func (r *MemoryTagRepository) Create(tag *models.Tag) error {
+	if r.tenant == "" {
+		return models.ErrNoTenant
+	}
+	tag.TenantID = r.tenant
+	return r.write(func(d *memoryData) error {
+		if d.tagByName(r.tenant, tag.Name) != nil {
+			return models.ErrDuplicateTag
+		}
+		now := time.Now()
+		tag.ID = d.nextID("tags")
+		tag.CreatedAt = now
+		tag.UpdatedAt = now
+		d.tags[tag.ID] = *tag
+		return nil
+	})
+}
+
+func (r *MemoryTagRepository) Update(tag *models.Tag) error {
+	return r.write(func(d *memoryData) error {
+		stored, ok := r.tag(d, tag.ID)
+		if !ok {
+			return gorm.ErrRecordNotFound
+		}
+		if other := d.tagByName(r.tenant, tag.Name); other != nil && other.ID != tag.ID {
+			return models.ErrDuplicateTag
+		}
+		tag.TenantID = stored.TenantID
+		tag.CreatedAt = stored.CreatedAt
+		tag.UpdatedAt = time.Now()
+		d.tags[tag.ID] = *tag
+		return nil
+	})
+}
+
+func (r *MemoryTagRepository) Delete(id uint) error {
+	return r.write(func(d *memoryData) error {
+		if _, ok := r.tag(d, id); !ok {
+			return gorm.ErrRecordNotFound
+		}
+		for _, tagIDs := range d.bookTags {
+			for _, tagID := range tagIDs {
+				if tagID == id {
+					return models.ErrTagInUse
+				}
+			}
+		}
+		delete(d.tags, id)
+		return nil
+	})
+}
+
+func (d *memoryData) tagByName(tenantID, name string) *models.Tag {
+	for _, tag := range d.tags {
+		if tag.TenantID == tenantID && tag.Name == name {
+			return &tag
+		}
+	}
+	return nil
+}
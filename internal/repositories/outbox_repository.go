@@ -0,0 +1,72 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/shani34/book-management-system/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (r *BookRepository) EnqueueEvent(event *models.OutboxEvent) error {
+	return enqueueOutboxEvent(r.db, event)
+}
+
+func (r *BookRepository) ClaimEvents(limit int, lease time.Duration) ([]models.OutboxEvent, error) {
+	return claimOutboxEvents(r.db, limit, lease)
+}
+
+func (r *BookRepository) SaveEvents(events []models.OutboxEvent) error {
+	return saveOutboxEvents(r.db, events)
+}
+
+func enqueueOutboxEvent(db *gorm.DB, event *models.OutboxEvent) error {
+	if event.NextAttemptAt.IsZero() {
+		event.NextAttemptAt = time.Now()
+	}
+	return db.Create(event).Error
+}
+
+// claimOutboxEvents locks the due rows with SKIP LOCKED only for as long as
+// it takes to push their lease forward, so several replicas can relay
+// concurrently without claiming the same event and without holding row
+// locks while the events are published.
+func claimOutboxEvents(db *gorm.DB, limit int, lease time.Duration) ([]models.OutboxEvent, error) {
+	var claimed []models.OutboxEvent
+	err := db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("sent_at IS NULL AND next_attempt_at <= ?", now).
+			Order("id").
+			Limit(limit).
+			Find(&claimed)
+		if result.Error != nil || len(claimed) == 0 {
+			return result.Error
+		}
+
+		ids := make([]uint, len(claimed))
+		for i := range claimed {
+			ids[i] = claimed[i].ID
+			claimed[i].NextAttemptAt = now.Add(lease)
+		}
+		return tx.Model(&models.OutboxEvent{}).Where("id IN ?", ids).Update("next_attempt_at", now.Add(lease)).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return claimed, nil
+}
+
+func saveOutboxEvents(db *gorm.DB, events []models.OutboxEvent) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		for i := range events {
+			err := tx.Model(&events[i]).
+				Select("attempts", "last_error", "next_attempt_at", "sent_at").
+				Updates(&events[i]).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,92 @@
+package repositories
+
+import (
+	"errors"
+	"github.com/shani34/book-management-system/internal/models"
+	"gorm.io/gorm"
+)
+
+type TagRepository struct {
+	db     *gorm.DB
+	tenant string
+}
+
+// NewTagRepository returns a store bound to no tenant; see ForTenant.
+func NewTagRepository(db *gorm.DB) *TagRepository {
+	return &TagRepository{db: db}
+}
+
+func (r *TagRepository) ForTenant(tenantID string) TagStore {
+	return &TagRepository{db: r.db, tenant: tenantID}
+}
+
+func (r *TagRepository) scoped() *gorm.DB {
+	return r.db.Where("tags.tenant_id = ?", r.tenant)
+}
+
+func (r *TagRepository) GetAll(limit, offset int) ([]models.Tag, error) {
+	var tags []models.Tag
+	result := r.scoped().Order("name, id").Limit(limit).Offset(offset).Find(&tags)
+	return tags, result.Error
+}
+
+func (r *TagRepository) GetByID(id uint) (*models.Tag, error) {
+	var tag models.Tag
+	result := r.scoped().First(&tag, id)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &tag, nil
+}
+
+func (r *TagRepository) Create(tag *models.Tag) error {
+	if r.tenant == "" {
+		return models.ErrNoTenant
+	}
+	tag.TenantID = r.tenant
+	return translateTagError(r.db.Create(tag).Error)
+}
+
+func (r *TagRepository) Update(tag *models.Tag) error {
+	tag.TenantID = r.tenant
+	result := r.db.Model(tag).Where("tenant_id = ?", r.tenant).Select("name", "updated_at").Updates(tag)
+	if result.Error != nil {
+		return translateTagError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *TagRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var tag models.Tag
+		if err := tx.Where("tenant_id = ?", r.tenant).Select("id").First(&tag, id).Error; err != nil {
+			return err
+		}
+		var linked int64
+		if err := tx.Table("book_tags").Where("tag_id = ?", id).Count(&linked).Error; err != nil {
+			return err
+		}
+		if linked > 0 {
+			return models.ErrTagInUse
+		}
+
+		result := tx.Delete(&models.Tag{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+func translateTagError(err error) error {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return models.ErrDuplicateTag
+	}
+	return err
+}
@@ -0,0 +1,24 @@
+package repositories
+
+import "github.com/shani34/book-management-system/internal/models"
+
+// TagStore persists the tag vocabulary of the tenant it is bound to.
+// Missing rows are reported as gorm.ErrRecordNotFound.
+type TagStore interface {
+	// ForTenant returns a store bound to tenantID.
+	ForTenant(tenantID string) TagStore
+
+	GetAll(limit, offset int) ([]models.Tag, error)
+	GetByID(id uint) (*models.Tag, error)
+	// Create and Update return models.ErrDuplicateTag when the name is
+	// already taken in the tenant.
+	Create(tag *models.Tag) error
+	Update(tag *models.Tag) error
+	// Delete refuses with models.ErrTagInUse while books carry the tag.
+	Delete(id uint) error
+}
+
+var (
+	_ TagStore = (*TagRepository)(nil)
+	_ TagStore = (*MemoryTagRepository)(nil)
+)
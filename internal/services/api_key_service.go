@@ -0,0 +1,183 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/shani34/book-management-system/internal/models"
+	"github.com/shani34/book-management-system/internal/repositories"
+	"gorm.io/gorm"
+)
+
+// apiKeyTag starts every key, so leaked keys are easy to recognise.
+const apiKeyTag = "bms"
+
+// touchInterval limits how often a busy key's last-used time is written.
+const touchInterval = time.Minute
+
+var (
+	// ErrInvalidAPIKey wraps validation failures of a key request, like
+	// ErrInvalidBook.
+	ErrInvalidAPIKey = errors.New("invalid api key")
+	// ErrUnknownAPIKey is returned by Authenticate for a key that is
+	// malformed or doesn't match any issued key.
+	ErrUnknownAPIKey = errors.New("unknown api key")
+	// ErrForeignTenant is returned when a tenant-bound caller asks for a
+	// key outside its own tenant.
+	ErrForeignTenant = errors.New("api key must be bound to the caller's tenant")
+)
+
+type APIKeyService struct {
+	repo   repositories.APIKeyStore
+	tenant string
+}
+
+// NewAPIKeyService returns a service that manages every tenant's keys; see
+// ForTenant.
+func NewAPIKeyService(repo repositories.APIKeyStore) *APIKeyService {
+	return &APIKeyService{repo: repo}
+}
+
+// ForTenant returns a service for a caller bound to tenantID. It only sees
+// and revokes that tenant's keys and only issues keys bound to it, so a
+// tenant's admin can't mint credentials for other tenants or for all of
+// them. An empty tenantID leaves the service unbound.
+func (s *APIKeyService) ForTenant(tenantID string) *APIKeyService {
+	if tenantID == "" {
+		return s
+	}
+	return &APIKeyService{repo: s.repo.ForTenant(tenantID), tenant: tenantID}
+}
+
+func (s *APIKeyService) GetAPIKeys(limit, offset int) ([]models.APIKey, error) {
+	return s.repo.GetAll(limit, offset)
+}
+
+func (s *APIKeyService) GetAPIKey(id uint) (*models.APIKey, error) {
+	return s.repo.GetByID(id)
+}
+
+// IssueAPIKey creates a key from the request. The returned Key is the only
+// copy of the secret; only its hash is stored.
+func (s *APIKeyService) IssueAPIKey(request *models.APIKeyRequest) (*models.IssuedAPIKey, error) {
+	if s.tenant != "" && request.TenantID != s.tenant {
+		return nil, fmt.Errorf("%w: tenant_id must be %q", ErrForeignTenant, s.tenant)
+	}
+	key := models.APIKey{
+		Name:      request.Name,
+		Scopes:    request.Scopes,
+		TenantID:  request.TenantID,
+		ExpiresAt: request.ExpiresAt,
+	}
+	if err := validateAPIKey(&key, time.Now()); err != nil {
+		return nil, err
+	}
+
+	prefix, err := randomString(6, hex.EncodeToString)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := randomString(32, base64.RawURLEncoding.EncodeToString)
+	if err != nil {
+		return nil, err
+	}
+	raw := apiKeyTag + "_" + prefix + "_" + secret
+
+	key.Prefix = prefix
+	key.SecretHash = hashAPIKey(raw)
+	if err := s.repo.Create(&key); err != nil {
+		return nil, err
+	}
+	return &models.IssuedAPIKey{APIKey: key, Key: raw}, nil
+}
+
+// RevokeAPIKey stops a key authenticating. It returns the revoked key.
+func (s *APIKeyService) RevokeAPIKey(id uint) (*models.APIKey, error) {
+	if err := s.repo.Revoke(id, time.Now()); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(id)
+}
+
+// Authenticate returns the key matching raw, as sent in the X-API-Key
+// header. It fails with ErrUnknownAPIKey, models.ErrAPIKeyRevoked or
+// models.ErrAPIKeyExpired when the key can't be used.
+func (s *APIKeyService) Authenticate(raw string) (*models.APIKey, error) {
+	tag, rest, _ := strings.Cut(raw, "_")
+	prefix, _, found := strings.Cut(rest, "_")
+	if tag != apiKeyTag || !found || prefix == "" {
+		return nil, ErrUnknownAPIKey
+	}
+
+	key, err := s.repo.GetByPrefix(prefix)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrUnknownAPIKey
+	} else if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(hashAPIKey(raw)), []byte(key.SecretHash)) != 1 {
+		return nil, ErrUnknownAPIKey
+	}
+
+	now := time.Now()
+	if err := key.Usable(now); err != nil {
+		return nil, err
+	}
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= touchInterval {
+		if err := s.repo.Touch(key.ID, now); err != nil {
+			return nil, err
+		}
+		key.LastUsedAt = &now
+	}
+	return key, nil
+}
+
+func validateAPIKey(key *models.APIKey, now time.Time) error {
+	key.Name = strings.TrimSpace(key.Name)
+	if key.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidAPIKey)
+	}
+	if len(key.Scopes) == 0 {
+		return fmt.Errorf("%w: at least one scope is required", ErrInvalidAPIKey)
+	}
+	scopes := models.Scopes{}
+	for _, scope := range key.Scopes {
+		if !slices.Contains(models.KnownScopes, scope) {
+			return fmt.Errorf("%w: unknown scope %q, want one of %s", ErrInvalidAPIKey, scope, strings.Join(models.KnownScopes, ", "))
+		}
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	key.Scopes = scopes
+	if key.TenantID != "" && !models.ValidTenantID(key.TenantID) {
+		return fmt.Errorf("%w: tenant_id must be lowercase letters, digits and hyphens", ErrInvalidAPIKey)
+	}
+	if key.ExpiresAt != nil && !key.ExpiresAt.After(now) {
+		return fmt.Errorf("%w: expires_at must be in the future", ErrInvalidAPIKey)
+	}
+	return nil
+}
+
+// hashAPIKey is the stored form of a key. Keys carry 256 random bits, so a
+// fast hash is enough; there is nothing to brute-force.
+func hashAPIKey(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomString(n int, encode func([]byte) string) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return encode(b), nil
+}
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shani34/book-management-system/internal/models"
+	"github.com/shani34/book-management-system/internal/repositories"
+	"gorm.io/gorm"
+)
+
+func newTestAPIKeyService() *APIKeyService {
+	return NewAPIKeyService(repositories.NewMemoryAPIKeyRepository(repositories.NewMemoryDB()))
+}
+
+// issueKey issues a books:read key bound to tenant, failing the test on
+// error.
+func issueKey(t *testing.T, service *APIKeyService, tenant string) *models.IssuedAPIKey {
+	t.Helper()
+	issued, err := service.IssueAPIKey(&models.APIKeyRequest{Name: "key-" + tenant, Scopes: []string{models.ScopeBooksRead}, TenantID: tenant})
+	if err != nil {
+		t.Fatalf("IssueAPIKey(%q): %v", tenant, err)
+	}
+	return issued
+}
+
+func TestTenantBoundAPIKeyIssuing(t *testing.T) {
+	north := newTestAPIKeyService().ForTenant("north")
+
+	tests := []struct {
+		name   string
+		tenant string
+		err    error
+	}{
+		{"own tenant", "north", nil},
+		{"unbound", "", ErrForeignTenant},
+		{"other tenant", "south", ErrForeignTenant},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issued, err := north.IssueAPIKey(&models.APIKeyRequest{Name: "k", Scopes: []string{models.ScopeAdmin}, TenantID: tt.tenant})
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("IssueAPIKey: got %v, want %v", err, tt.err)
+			}
+			if err == nil && issued.TenantID != "north" {
+				t.Errorf("TenantID = %q, want north", issued.TenantID)
+			}
+		})
+	}
+}
+
+func TestTenantBoundAPIKeyManagement(t *testing.T) {
+	service := newTestAPIKeyService()
+	own := issueKey(t, service, "north")
+	foreign := issueKey(t, service, "south")
+	unbound := issueKey(t, service, "")
+	north := service.ForTenant("north")
+
+	keys, err := north.GetAPIKeys(10, 0)
+	if err != nil || len(keys) != 1 || keys[0].ID != own.ID {
+		t.Errorf("GetAPIKeys from north: got %+v, %v; want only key %d", keys, err, own.ID)
+	}
+	if all, err := service.GetAPIKeys(10, 0); err != nil || len(all) != 3 {
+		t.Errorf("GetAPIKeys unbound: got %d keys, %v; want 3", len(all), err)
+	}
+
+	for _, other := range []*models.IssuedAPIKey{foreign, unbound} {
+		if _, err := north.GetAPIKey(other.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Errorf("GetAPIKey(%d) from north: got %v, want not found", other.ID, err)
+		}
+		if _, err := north.RevokeAPIKey(other.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Errorf("RevokeAPIKey(%d) from north: got %v, want not found", other.ID, err)
+		}
+		if key, err := service.GetAPIKey(other.ID); err != nil || key.RevokedAt != nil {
+			t.Errorf("key %d after north's revoke: got %+v, %v; want it untouched", other.ID, key, err)
+		}
+	}
+
+	if key, err := north.RevokeAPIKey(own.ID); err != nil || key.RevokedAt == nil {
+		t.Errorf("RevokeAPIKey(own) from north: got %+v, %v", key, err)
+	}
+}
+
+func TestAPIKeyIsStoredHashed(t *testing.T) {
+	service := newTestAPIKeyService()
+	issued := issueKey(t, service, "north")
+
+	tag, rest, _ := strings.Cut(issued.Key, "_")
+	prefix, secret, _ := strings.Cut(rest, "_")
+	if tag != apiKeyTag || prefix != issued.Prefix || len(secret) < 40 {
+		t.Fatalf("issued key %q: want %s_<prefix %s>_<secret>", issued.Key, apiKeyTag, issued.Prefix)
+	}
+	stored, err := service.GetAPIKey(issued.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.SecretHash != hashAPIKey(issued.Key) || strings.Contains(stored.SecretHash, secret) {
+		t.Errorf("stored hash %q: want the SHA-256 of the key", stored.SecretHash)
+	}
+	if body, _ := json.Marshal(stored); strings.Contains(string(body), stored.SecretHash) {
+		t.Errorf("key JSON exposes the hash: %s", body)
+	}
+}
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	service := newTestAPIKeyService()
+	issued := issueKey(t, service, "north")
+
+	key, err := service.Authenticate(issued.Key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key.ID != issued.ID || key.TenantID != "north" || !key.Scopes.Has(models.ScopeBooksRead) || key.LastUsedAt == nil {
+		t.Errorf("Authenticate: got %+v", key)
+	}
+	// A key used again within touchInterval isn't written to.
+	again, err := service.Authenticate(issued.Key)
+	if err != nil || !again.LastUsedAt.Equal(*key.LastUsedAt) {
+		t.Errorf("second Authenticate: got %+v, %v; want last used %v", again, err, key.LastUsedAt)
+	}
+	if stored, err := service.GetAPIKey(issued.ID); err != nil || stored.LastUsedAt == nil || !stored.LastUsedAt.Equal(*key.LastUsedAt) {
+		t.Errorf("stored key: got %+v, %v; want last used %v", stored, err, key.LastUsedAt)
+	}
+}
+
+func TestAuthenticateRejectsAPIKey(t *testing.T) {
+	service := newTestAPIKeyService()
+	issued := issueKey(t, service, "north")
+	revoked := issueKey(t, service, "north")
+	if _, err := service.RevokeAPIKey(revoked.ID); err != nil {
+		t.Fatal(err)
+	}
+	// Keys can't be issued already expired, so this one goes to the store.
+	expired := apiKeyTag + "_0123456789ab_secret"
+	past := time.Now().Add(-time.Minute)
+	if err := service.repo.Create(&models.APIKey{Name: "expired", Prefix: "0123456789ab", SecretHash: hashAPIKey(expired), Scopes: models.Scopes{models.ScopeBooksRead}, ExpiresAt: &past}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		raw  string
+		err  error
+	}{
+		{"empty", "", ErrUnknownAPIKey},
+		{"no prefix", apiKeyTag + "_", ErrUnknownAPIKey},
+		{"wrong tag", "xyz_" + issued.Prefix + "_secret", ErrUnknownAPIKey},
+		{"unknown prefix", apiKeyTag + "_ffffffffffff_secret", ErrUnknownAPIKey},
+		{"wrong secret", apiKeyTag + "_" + issued.Prefix + "_secret", ErrUnknownAPIKey},
+		{"truncated", issued.Key[:len(issued.Key)-1], ErrUnknownAPIKey},
+		{"revoked", revoked.Key, models.ErrAPIKeyRevoked},
+		{"expired", expired, models.ErrAPIKeyExpired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if key, err := service.Authenticate(tt.raw); !errors.Is(err, tt.err) {
+				t.Errorf("Authenticate: got %+v, %v; want %v", key, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestIssueAPIKeyValidation(t *testing.T) {
+	past := time.Now().Add(-time.Minute)
+	tests := []struct {
+		name    string
+		request models.APIKeyRequest
+	}{
+		{"no name", models.APIKeyRequest{Name: " ", Scopes: []string{models.ScopeBooksRead}}},
+		{"no scopes", models.APIKeyRequest{Name: "k"}},
+		{"unknown scope", models.APIKeyRequest{Name: "k", Scopes: []string{"books:delete"}}},
+		{"invalid tenant", models.APIKeyRequest{Name: "k", Scopes: []string{models.ScopeBooksRead}, TenantID: "A_B"}},
+		{"already expired", models.APIKeyRequest{Name: "k", Scopes: []string{models.ScopeBooksRead}, ExpiresAt: &past}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := newTestAPIKeyService().IssueAPIKey(&tt.request); !errors.Is(err, ErrInvalidAPIKey) {
+				t.Errorf("IssueAPIKey: got %v, want ErrInvalidAPIKey", err)
+			}
+		})
+	}
+}
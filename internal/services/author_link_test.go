@@ -0,0 +1,158 @@
+package services
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/shani34/book-management-system/internal/models"
+	"github.com/shani34/book-management-system/internal/repositories"
+	"github.com/shani34/book-management-system/pkg/cache"
+)
+
+func newAuthorLinks() (*BookService, *AuthorService) {
+	db := repositories.NewMemoryDB()
+	books := repositories.NewMemoryBookRepository(db)
+	c := cache.NewLRU(0)
+	return NewBookService(books, c).ForTenant("t"),
+		NewAuthorService(repositories.NewMemoryAuthorRepository(db), books, c).ForTenant("t")
+}
+
+func authorNames(authors []models.Author) string {
+	names := make([]string, len(authors))
+	for i, author := range authors {
+		names[i] = author.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+func TestCreateBookLinksAuthorByName(t *testing.T) {
+	books, authors := newAuthorLinks()
+
+	dune := &models.Book{Title: "Dune", Author: " Frank Herbert ", Year: 1965}
+	messiah := &models.Book{Title: "Dune Messiah", Author: "Frank Herbert", Year: 1969}
+	for _, book := range []*models.Book{dune, messiah} {
+		if err := books.CreateBook(book); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if authorNames(dune.Authors) != "Frank Herbert" || authorNames(messiah.Authors) != "Frank Herbert" ||
+		dune.Authors[0].ID != messiah.Authors[0].ID {
+		t.Fatalf("got %v and %v, want both linked to one Frank Herbert", dune.Authors, messiah.Authors)
+	}
+
+	all, err := authors.GetAllAuthors(10, 0)
+	if err != nil || len(all) != 1 {
+		t.Fatalf("authors: got %v, %v; want one", all, err)
+	}
+	linked, err := authors.GetAuthorBooks(all[0].ID, 10, 0)
+	if err != nil || len(linked) != 2 {
+		t.Errorf("author's books: got %d, %v; want 2", len(linked), err)
+	}
+
+	stored, err := books.GetBookByID(dune.ID)
+	if err != nil || authorNames(stored.Authors) != "Frank Herbert" {
+		t.Errorf("stored book: got %+v, %v", stored, err)
+	}
+}
+
+func TestAuthorIDs(t *testing.T) {
+	books, authors := newAuthorLinks()
+	pratchett := &models.Author{Name: "Terry Pratchett"}
+	gaiman := &models.Author{Name: "Neil Gaiman"}
+	for _, author := range []*models.Author{pratchett, gaiman} {
+		if err := authors.CreateAuthor(author); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	omens := &models.Book{Title: "Good Omens", Author: "Pratchett & Gaiman", Year: 1990, AuthorIDs: []uint{pratchett.ID, gaiman.ID}}
+	if err := books.CreateBook(omens); err != nil {
+		t.Fatal(err)
+	}
+	if len(omens.Authors) != 2 || omens.AuthorIDs != nil {
+		t.Fatalf("create: got authors %v, ids %v", omens.Authors, omens.AuthorIDs)
+	}
+
+	// An edit that keeps the author string keeps the explicit links...
+	edit := &models.Book{Title: "Good Omens", Author: "Pratchett & Gaiman", Year: 1991}
+	if err := books.UpdateBook(omens.ID, 0, edit); err != nil {
+		t.Fatal(err)
+	}
+	if len(edit.Authors) != 2 {
+		t.Errorf("unrelated edit: got authors %v, want both kept", edit.Authors)
+	}
+
+	// ...while changing it relinks by name.
+	rename := &models.Book{Title: "Good Omens", Author: "Neil Gaiman", Year: 1991}
+	if err := books.UpdateBook(omens.ID, 0, rename); err != nil {
+		t.Fatal(err)
+	}
+	if authorNames(rename.Authors) != "Neil Gaiman" || rename.Authors[0].ID != gaiman.ID {
+		t.Errorf("rename: got authors %v, want Neil Gaiman", rename.Authors)
+	}
+
+	unknown := &models.Book{Title: "Nation", Author: "Terry Pratchett", Year: 2008, AuthorIDs: []uint{99}}
+	if err := books.CreateBook(unknown); !errors.Is(err, ErrInvalidBook) {
+		t.Errorf("unknown author id: got %v, want ErrInvalidBook", err)
+	}
+	if _, err := books.GetBookByID(omens.ID + 1); err == nil {
+		t.Error("the book with an unknown author was saved")
+	}
+}
+
+func TestAuthorRules(t *testing.T) {
+	books, authors := newAuthorLinks()
+	if err := books.CreateBook(&models.Book{Title: "Emma", Author: "Jane Austen", Year: 1815}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := authors.CreateAuthor(&models.Author{Name: "  "}); !errors.Is(err, ErrInvalidAuthor) {
+		t.Errorf("blank name: got %v, want ErrInvalidAuthor", err)
+	}
+	if err := authors.CreateAuthor(&models.Author{Name: "Jane Austen"}); !errors.Is(err, models.ErrDuplicateAuthor) {
+		t.Errorf("taken name: got %v, want ErrDuplicateAuthor", err)
+	}
+
+	if err := authors.DeleteAuthor(1); !errors.Is(err, models.ErrAuthorHasBooks) {
+		t.Errorf("delete with books: got %v, want ErrAuthorHasBooks", err)
+	}
+	unused := &models.Author{Name: "Anonymous"}
+	if err := authors.CreateAuthor(unused); err != nil {
+		t.Fatal(err)
+	}
+	if err := authors.DeleteAuthor(unused.ID); err != nil {
+		t.Errorf("delete without books: %v", err)
+	}
+	if _, err := authors.GetAuthorByID(unused.ID); err == nil {
+		t.Error("deleted author is still there")
+	}
+}
+
+func TestImportLinksAuthors(t *testing.T) {
+	books, authors := newAuthorLinks()
+	gaiman := &models.Author{Name: "Neil Gaiman"}
+	if err := authors.CreateAuthor(gaiman); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := books.ImportBooks(strings.NewReader(`{"title":"Dune","author":"Frank Herbert","year":1965}
+{"title":"Dune Messiah","author":"Frank Herbert","year":1969}
+{"title":"Coraline","author":"N. Gaiman","year":2002,"author_ids":[`+strconv.FormatUint(uint64(gaiman.ID), 10)+`]}
+`), "ndjson")
+	if err != nil || report.Created != 3 {
+		t.Fatalf("got %+v, %v; want 3 created", report, err)
+	}
+
+	all, err := authors.GetAllAuthors(10, 0)
+	if err != nil || authorNames(all) != "Frank Herbert, Neil Gaiman" {
+		t.Fatalf("authors: got %v, %v", all, err)
+	}
+	for id, want := range map[uint]string{report.Rows[0].ID: "Frank Herbert", report.Rows[1].ID: "Frank Herbert", report.Rows[2].ID: "Neil Gaiman"} {
+		book, err := books.GetBookByID(id)
+		if err != nil || authorNames(book.Authors) != want {
+			t.Errorf("book %d: got %+v, %v; want linked to %s", id, book, err, want)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/shani34/book-management-system/internal/models"
+	"github.com/shani34/book-management-system/internal/repositories"
+	"github.com/shani34/book-management-system/pkg/cache"
+)
+
+// AuthorService manages one tenant's authors; get it for a request's
+// tenant with ForTenant. Books embed their authors, so changing an author
+// goes through books to version and event every book linked to it.
+type AuthorService struct {
+	repo  repositories.AuthorStore
+	books repositories.BookStore
+	cache cache.Cache
+}
+
+func NewAuthorService(repo repositories.AuthorStore, books repositories.BookStore, cache cache.Cache) *AuthorService {
+	return &AuthorService{
+		repo:  repo,
+		books: books,
+		cache: cache,
+	}
+}
+
+// ForTenant returns the service for tenantID's authors and books.
+func (s *AuthorService) ForTenant(tenantID string) *AuthorService {
+	return &AuthorService{
+		repo:  s.repo.ForTenant(tenantID),
+		books: s.books.ForTenant(tenantID),
+		cache: cache.ForTenant(s.cache, tenantID),
+	}
+}
+
+func (s *AuthorService) GetAllAuthors(limit, offset int) ([]models.Author, error) {
+	return s.repo.GetAll(limit, offset)
+}
+
+func (s *AuthorService) GetAuthorByID(id uint) (*models.Author, error) {
+	return s.repo.GetByID(id)
+}
+
+// GetAuthorBooks lists the books linked to the author with id.
+func (s *AuthorService) GetAuthorBooks(id uint, limit, offset int) ([]models.Book, error) {
+	if _, err := s.repo.GetByID(id); err != nil {
+		return nil, err
+	}
+	return s.repo.GetBooks(id, limit, offset)
+}
+
+func (s *AuthorService) CreateAuthor(author *models.Author) error {
+	if err := validateAuthor(author); err != nil {
+		return err
+	}
+	return s.repo.Create(author)
+}
+
+func (s *AuthorService) UpdateAuthor(id uint, author *models.Author) error {
+	existing, err := s.repo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if err := validateAuthor(author); err != nil {
+		return err
+	}
+
+	// Every linked book gets a new version and a book_updated event in the
+	// same transaction as the rename.
+	author.ID = existing.ID
+	var linked []models.Book
+	err = s.books.Transaction(func(tx repositories.BookStore) error {
+		var err error
+		if linked, err = tx.LockBooksByAuthor(author.ID); err != nil {
+			return err
+		}
+		if err := tx.Authors().Update(author); err != nil {
+			return err
+		}
+		return touchBooks(tx, linked)
+	})
+	if err != nil {
+		return err
+	}
+
+	// Cached books and listings embed their authors, so a rename makes
+	// them stale.
+	forgetBooks(s.cache, linked)
+	return nil
+}
+
+// DeleteAuthor deletes an author no book links to any more.
+func (s *AuthorService) DeleteAuthor(id uint) error {
+	return s.repo.Delete(id)
+}
+
+// ErrInvalidAuthor wraps author validation failures, like ErrInvalidBook.
+var ErrInvalidAuthor = errors.New("invalid author")
+
+func validateAuthor(author *models.Author) error {
+	author.Name = strings.TrimSpace(author.Name)
+	if author.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidAuthor)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shani34/book-management-system/internal/models"
+	"github.com/shani34/book-management-system/internal/repositories"
+	"github.com/shani34/book-management-system/pkg/cache"
+	"gorm.io/gorm"
+)
+
+func TestAuthorRenameTouchesLinkedBooks(t *testing.T) {
+	db := repositories.NewMemoryDB()
+	repo := repositories.NewMemoryBookRepository(db)
+	bookCache := cache.NewLRU(100)
+	books := NewBookService(repo, bookCache)
+	authors := NewAuthorService(repositories.NewMemoryAuthorRepository(db), repo, bookCache)
+
+	dune := &models.Book{Title: "Dune", Author: "Frank Herbert"}
+	if err := books.ForTenant("a").CreateBook(dune); err != nil {
+		t.Fatalf("CreateBook: %v", err)
+	}
+	messiah := &models.Book{Title: "Dune Messiah", Author: "Frank Herbert"}
+	if err := books.ForTenant("b").CreateBook(messiah); err != nil {
+		t.Fatalf("CreateBook: %v", err)
+	}
+	emma := &models.Book{Title: "Emma", Author: "Jane Austen"}
+	if err := books.ForTenant("a").CreateBook(emma); err != nil {
+		t.Fatalf("CreateBook: %v", err)
+	}
+	relayBookEvents(t, repo)
+	if dune.Authors[0].ID == messiah.Authors[0].ID {
+		t.Fatalf("tenants a and b share author %d", dune.Authors[0].ID)
+	}
+
+	// Warm the cache so a stale entry would show.
+	cached, err := books.ForTenant("a").GetBookByID(dune.ID)
+	if err != nil || len(cached.Authors) != 1 {
+		t.Fatalf("GetBookByID: got %+v, %v; want one linked author", cached, err)
+	}
+	if err := authors.ForTenant("a").UpdateAuthor(cached.Authors[0].ID, &models.Author{Name: "Franklin Herbert"}); err != nil {
+		t.Fatalf("UpdateAuthor: %v", err)
+	}
+
+	if got, err := books.ForTenant("a").GetBookByID(dune.ID); err != nil || got.Version != 2 ||
+		len(got.Authors) != 1 || got.Authors[0].Name != "Franklin Herbert" {
+		t.Errorf("linked book after rename: got %+v, %v; want version 2 and the new name", got, err)
+	}
+	if got, err := books.ForTenant("a").GetBookByID(emma.ID); err != nil || got.Version != 1 {
+		t.Errorf("unlinked book after rename: got %+v, %v; want version 1", got, err)
+	}
+	if got, err := books.ForTenant("b").GetBookByID(messiah.ID); err != nil || got.Version != 1 ||
+		got.Authors[0].Name != "Frank Herbert" {
+		t.Errorf("b's book after a's rename: got %+v, %v; want it unchanged", got, err)
+	}
+
+	relayed := relayBookEvents(t, repo)
+	if len(relayed) != 1 {
+		t.Fatalf("got %d book events, want 1", len(relayed))
+	}
+	book := relayed[0].Payload
+	if relayed[0].EventType != "book_updated" || relayed[0].TenantID != "a" || book.ID != dune.ID ||
+		book.Version != 2 || len(book.Authors) != 1 || book.Authors[0].Name != "Franklin Herbert" {
+		t.Errorf("event: got %s for %q of tenant %q at version %d, authors %+v",
+			relayed[0].EventType, book.Title, relayed[0].TenantID, book.Version, book.Authors)
+	}
+}
+
+func TestAuthorsAreIsolated(t *testing.T) {
+	db := repositories.NewMemoryDB()
+	repo := repositories.NewMemoryBookRepository(db)
+	service := NewAuthorService(repositories.NewMemoryAuthorRepository(db), repo, cache.Noop{})
+	a, b := service.ForTenant("a"), service.ForTenant("b")
+
+	author := &models.Author{Name: "Ursula K. Le Guin"}
+	if err := a.CreateAuthor(author); err != nil {
+		t.Fatalf("CreateAuthor: %v", err)
+	}
+	if err := b.CreateAuthor(&models.Author{Name: author.Name}); err != nil {
+		t.Errorf("CreateAuthor of a name taken in another tenant: %v", err)
+	}
+
+	if _, err := b.GetAuthorByID(author.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("GetAuthorByID from b: got %v, want not found", err)
+	}
+	if err := b.UpdateAuthor(author.ID, &models.Author{Name: "Someone Else"}); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("UpdateAuthor from b: got %v, want not found", err)
+	}
+	if err := b.DeleteAuthor(author.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("DeleteAuthor from b: got %v, want not found", err)
+	}
+	if all, err := b.GetAllAuthors(10, 0); err != nil || len(all) != 1 || all[0].ID == author.ID {
+		t.Errorf("GetAllAuthors from b: got %+v, %v; want only b's own author", all, err)
+	}
+
+	book := &models.Book{Title: "Earthsea", Author: "Ursula K. Le Guin", AuthorIDs: []uint{author.ID}}
+	if err := NewBookService(repo, cache.Noop{}).ForTenant("b").CreateBook(book); !errors.Is(err, ErrInvalidBook) {
+		t.Errorf("b linking a's author: got %v, want ErrInvalidBook", err)
+	}
+	if got, err := a.GetAuthorByID(author.ID); err != nil || got.Name != "Ursula K. Le Guin" {
+		t.Errorf("a's author after b's writes: got %+v, %v", got, err)
+	}
+}
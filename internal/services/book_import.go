@@ -0,0 +1,273 @@
+package services
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/shani34/book-management-system/internal/models"
+	"github.com/shani34/book-management-system/internal/repositories"
+)
+
+// importBatchSize is how many valid rows are inserted per transaction.
+const importBatchSize = 500
+
+// ErrInvalidImport is returned when the upload as a whole can't be read,
+// e.g. an unknown format or a CSV without the required columns. Problems
+// with individual rows are reported per row instead.
+var ErrInvalidImport = errors.New("invalid import")
+
+type importRecord struct {
+	row  int
+	book models.Book
+	err  error
+}
+
+// ImportBooks reads books from r in the given format ("csv" or "ndjson"),
+// validates each row and inserts the valid ones in batched transactions.
+// A row repeating an ISBN of an earlier row fails before anything is
+// inserted. Each created book still gets its own book_created event, but
+// the list cache is invalidated once for the whole import.
+func (s *BookService) ImportBooks(r io.Reader, format string) (*models.ImportReport, error) {
+	var next func() (*importRecord, error)
+	switch format {
+	case "csv":
+		reader, err := newCSVImportReader(r)
+		if err != nil {
+			return nil, err
+		}
+		next = reader.next
+	case "ndjson":
+		next = newNDJSONImportReader(r).next
+	case "":
+		return nil, fmt.Errorf("%w: could not detect the format, pass format=csv or format=ndjson", ErrInvalidImport)
+	default:
+		return nil, fmt.Errorf("%w: unsupported format %q", ErrInvalidImport, format)
+	}
+
+	report := &models.ImportReport{Rows: []models.ImportRow{}}
+	seen := make(map[string]int)
+	var batch []*importRecord
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		s.insertImportBatch(batch, report)
+		batch = batch[:0]
+	}
+
+	for {
+		record, err := next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		report.Total++
+		if record.err == nil {
+			record.err = validateBook(&record.book)
+		}
+		if record.err == nil {
+			record.err = checkImportISBNs(seen, record)
+		}
+		if record.err != nil {
+			report.Failed++
+			report.Rows = append(report.Rows, models.ImportRow{Row: record.row, Error: record.err.Error()})
+			continue
+		}
+
+		batch = append(batch, record)
+		if len(batch) == importBatchSize {
+			flush()
+		}
+	}
+	flush()
+	sort.Slice(report.Rows, func(i, j int) bool { return report.Rows[i].Row < report.Rows[j].Row })
+
+	if report.Created > 0 {
+		s.cache.DeletePrefix("books:")
+	}
+	return report, nil
+}
+
+// checkImportISBNs fails a record whose ISBNs were already used by an
+// earlier row of the same import, and otherwise remembers them in seen.
+func checkImportISBNs(seen map[string]int, record *importRecord) error {
+	for _, number := range []string{record.book.ISBN10, record.book.ISBN13} {
+		if row, ok := seen[number]; ok && number != "" {
+			return fmt.Errorf("%w: isbn %s is also on row %d", models.ErrDuplicateISBN, number, row)
+		}
+	}
+	for _, number := range []string{record.book.ISBN10, record.book.ISBN13} {
+		if number != "" {
+			seen[number] = record.row
+		}
+	}
+	return nil
+}
+
+// insertImportBatch writes one batch atomically. If the transaction fails,
+// e.g. because a row's ISBN is already in the catalogue, the rows are
+// retried one transaction each so only the offending rows are reported.
+func (s *BookService) insertImportBatch(batch []*importRecord, report *models.ImportReport) {
+	originals := make([]models.Book, len(batch))
+	for i, record := range batch {
+		originals[i] = record.book
+	}
+
+	err := s.insertImportRecords(batch)
+	if err != nil && len(batch) > 1 {
+		for i, record := range batch {
+			// Drop what the failed transaction filled in, such as the ID.
+			record.book = originals[i]
+			reportImportRow(report, record, s.insertImportRecords(batch[i:i+1]))
+		}
+		return
+	}
+	for _, record := range batch {
+		reportImportRow(report, record, err)
+	}
+}
+
+// insertImportRecords creates the books of records in one transaction.
+func (s *BookService) insertImportRecords(records []*importRecord) error {
+	return s.repo.Transaction(func(tx repositories.BookStore) error {
+		for _, record := range records {
+			if err := tx.Create(&record.book); err != nil {
+				return err
+			}
+			if err := linkAuthors(tx, &record.book, true); err != nil {
+				return err
+			}
+			if err := s.publishKafkaEvent(tx, "book_created", &record.book); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// reportImportRow adds the outcome of inserting record to report.
+func reportImportRow(report *models.ImportReport, record *importRecord, err error) {
+	row := models.ImportRow{Row: record.row}
+	if err != nil {
+		row.Error = err.Error()
+		report.Failed++
+	} else {
+		row.ID = record.book.ID
+		report.Created++
+	}
+	report.Rows = append(report.Rows, row)
+}
+
+// csvImportReader maps CSV records onto books using the header row, so
+// column order doesn't matter and extra columns are ignored.
+type csvImportReader struct {
+	reader  *csv.Reader
+	columns map[string]int
+}
+
+func newCSVImportReader(r io.Reader) (*csvImportReader, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: reading CSV header: %w", ErrInvalidImport, err)
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"title", "author"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("%w: CSV header is missing the %q column", ErrInvalidImport, required)
+		}
+	}
+	return &csvImportReader{reader: reader, columns: columns}, nil
+}
+
+func (c *csvImportReader) next() (*importRecord, error) {
+	fields, err := c.reader.Read()
+	if err == io.EOF {
+		return nil, io.EOF
+	}
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return &importRecord{row: parseErr.StartLine, err: err}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	field := func(name string) string {
+		if i, ok := c.columns[name]; ok && i < len(fields) {
+			return strings.TrimSpace(fields[i])
+		}
+		return ""
+	}
+
+	line, _ := c.reader.FieldPos(0)
+	record := &importRecord{row: line}
+	record.book.Title = field("title")
+	record.book.Author = field("author")
+	record.book.ISBN10 = field("isbn10")
+	record.book.ISBN13 = field("isbn13")
+	if year := field("year"); year != "" {
+		if record.book.Year, err = strconv.Atoi(year); err != nil {
+			record.err = fmt.Errorf("%w: year %q is not a number", ErrInvalidBook, year)
+		}
+	}
+	return record, nil
+}
+
+// ndjsonImportReader decodes one book per line; blank lines are skipped.
+type ndjsonImportReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func newNDJSONImportReader(r io.Reader) *ndjsonImportReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	return &ndjsonImportReader{scanner: scanner}
+}
+
+func (n *ndjsonImportReader) next() (*importRecord, error) {
+	for n.scanner.Scan() {
+		n.line++
+		text := strings.TrimSpace(n.scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		var request models.BookRequest
+		record := &importRecord{row: n.line}
+		if err := json.Unmarshal([]byte(text), &request); err != nil {
+			record.err = fmt.Errorf("%w: %v", ErrInvalidBook, err)
+			return record, nil
+		}
+		record.book = models.Book{
+			Title:     request.Title,
+			Author:    request.Author,
+			Year:      request.Year,
+			ISBN10:    request.ISBN10,
+			ISBN13:    request.ISBN13,
+			AuthorIDs: request.AuthorIDs,
+		}
+		return record, nil
+	}
+	if err := n.scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidImport, err)
+	}
+	return nil, io.EOF
+}
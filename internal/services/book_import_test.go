@@ -0,0 +1,73 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/shani34/book-management-system/internal/models"
+	"github.com/shani34/book-management-system/internal/repositories"
+	"github.com/shani34/book-management-system/pkg/cache"
+)
+
+func TestImportReportsDuplicateISBNsPerRow(t *testing.T) {
+	books := NewBookService(repositories.NewMemoryBookRepository(repositories.NewMemoryDB()), cache.Noop{}).ForTenant("a")
+	existing := &models.Book{Title: "Dune", Author: "Frank Herbert", ISBN13: "9780441013593"}
+	if err := books.CreateBook(existing); err != nil {
+		t.Fatalf("CreateBook: %v", err)
+	}
+	trashed := &models.Book{Title: "Emma", Author: "Jane Austen", ISBN13: "9780141439587"}
+	if err := books.CreateBook(trashed); err != nil {
+		t.Fatalf("CreateBook: %v", err)
+	}
+	if err := books.DeleteBook(trashed.ID, 0); err != nil {
+		t.Fatalf("DeleteBook: %v", err)
+	}
+
+	upload := strings.Join([]string{
+		"title,author,isbn13,isbn10",
+		"Gilead,Marilynne Robinson,9780312424404,",       // row 2: new
+		"Dune,Frank Herbert,978-0-441-01359-3,",          // row 3: in the catalogue
+		"Home,Marilynne Robinson,,0-312-42440-X",         // row 4: same book as row 2
+		"Emma,Jane Austen,9780141439587,",                // row 5: in the trash
+		"Persuasion,Jane Austen,9780141439686,",          // row 6: new
+		"Persuasion again,Jane Austen,9780141439686,",    // row 7: same as row 6
+		"Housekeeping,Marilynne Robinson,9780312424091,", // row 8: new
+	}, "\n")
+	report, err := books.ImportBooks(strings.NewReader(upload), "csv")
+	if err != nil {
+		t.Fatalf("ImportBooks: %v", err)
+	}
+
+	if report.Total != 7 || report.Created != 3 || report.Failed != 4 {
+		t.Errorf("report: total %d, created %d, failed %d; want 7, 3, 4", report.Total, report.Created, report.Failed)
+	}
+	failed := map[int]bool{3: true, 4: true, 5: true, 7: true}
+	for _, row := range report.Rows {
+		switch {
+		case failed[row.Row] && !strings.Contains(row.Error, models.ErrDuplicateISBN.Error()):
+			t.Errorf("row %d: got error %q, want a duplicate ISBN", row.Row, row.Error)
+		case !failed[row.Row] && (row.Error != "" || row.ID == 0):
+			t.Errorf("row %d: got %+v, want it created", row.Row, row)
+		}
+	}
+	if !strings.Contains(report.Rows[2].Error, "row 2") {
+		t.Errorf("row 4 error %q does not name row 2", report.Rows[2].Error)
+	}
+
+	for _, isbn13 := range []string{"9780312424404", "9780141439686", "9780312424091"} {
+		if _, err := books.GetBookByISBN(isbn13); err != nil {
+			t.Errorf("GetBookByISBN(%s) after import: %v", isbn13, err)
+		}
+	}
+	if got, err := books.GetBookByID(existing.ID); err != nil || got.Title != "Dune" || got.Version != 1 {
+		t.Errorf("existing book after import: got %+v, %v", got, err)
+	}
+}
+
+func TestImportRejectsUnknownFormat(t *testing.T) {
+	books := NewBookService(repositories.NewMemoryBookRepository(repositories.NewMemoryDB()), cache.Noop{}).ForTenant("a")
+	if _, err := books.ImportBooks(strings.NewReader(""), "xml"); !errors.Is(err, ErrInvalidImport) {
+		t.Errorf("ImportBooks as xml: got %v, want ErrInvalidImport", err)
+	}
+}
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shani34/book-management-system/internal/models"
+	"github.com/shani34/book-management-system/internal/repositories"
+	"github.com/shani34/book-management-system/pkg/cache"
+)
+
+// newPagedBooks stores seven books whose years repeat, so pages have to
+// break ties on the sort key by ID.
+func newPagedBooks(t *testing.T) *BookService {
+	t.Helper()
+	books := NewBookService(repositories.NewMemoryBookRepository(repositories.NewMemoryDB()), cache.NewLRU(0)).ForTenant("t")
+	for i, year := range []int{1990, 1980, 1990, 2000, 1980, 1990, 2010} {
+		book := &models.Book{Title: string(rune('G' - i)), Author: "A", Year: year}
+		if err := books.CreateBook(book); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return books
+}
+
+func pageIDs(page *models.BookPage) []uint {
+	ids := make([]uint, len(page.Items))
+	for i, book := range page.Items {
+		ids[i] = book.ID
+	}
+	return ids
+}
+
+func sameIDs(a, b []uint) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestGetBooksPageWalk(t *testing.T) {
+	books := newPagedBooks(t)
+	tests := []struct {
+		filter models.BookFilter
+		pages  [][]uint
+	}{
+		{models.BookFilter{}, [][]uint{{1, 2, 3}, {4, 5, 6}, {7}}},
+		{models.BookFilter{Sort: "year"}, [][]uint{{2, 5, 1}, {3, 6, 4}, {7}}},
+		{models.BookFilter{Sort: "year", Desc: true}, [][]uint{{7, 4, 6}, {3, 1, 5}, {2}}},
+		{models.BookFilter{Sort: "title"}, [][]uint{{7, 6, 5}, {4, 3, 2}, {1}}},
+		{models.BookFilter{Sort: "year", YearFrom: intPtr(1990)}, [][]uint{{1, 3, 6}, {4, 7}}},
+	}
+	for _, tt := range tests {
+		name := tt.filter.CacheKey()
+
+		// Forwards to the end...
+		var seen []*models.BookPage
+		token := ""
+		for i, want := range tt.pages {
+			page, err := books.GetBooksPage(tt.filter, token, 3)
+			if err != nil {
+				t.Fatalf("%s page %d: %v", name, i, err)
+			}
+			if got := pageIDs(page); !sameIDs(got, want) {
+				t.Fatalf("%s page %d: got %v, want %v", name, i, got, want)
+			}
+			if last := i == len(tt.pages)-1; (page.NextCursor == "") != last {
+				t.Errorf("%s page %d: next cursor %q", name, i, page.NextCursor)
+			}
+			if (page.PrevCursor == "") != (i == 0) {
+				t.Errorf("%s page %d: prev cursor %q", name, i, page.PrevCursor)
+			}
+			if total := countIDs(tt.pages); page.Total != int64(total) {
+				t.Errorf("%s page %d: total %d, want %d", name, i, page.Total, total)
+			}
+			seen = append(seen, page)
+			token = page.NextCursor
+		}
+
+		// ...and back to the start.
+		for i := len(tt.pages) - 2; i >= 0; i-- {
+			page, err := books.GetBooksPage(tt.filter, seen[i+1].PrevCursor, 3)
+			if err != nil {
+				t.Fatalf("%s back to page %d: %v", name, i, err)
+			}
+			if got := pageIDs(page); !sameIDs(got, tt.pages[i]) {
+				t.Errorf("%s back to page %d: got %v, want %v", name, i, got, tt.pages[i])
+			}
+			if page.NextCursor == "" || (page.PrevCursor == "") != (i == 0) {
+				t.Errorf("%s back to page %d: cursors %q, %q", name, i, page.NextCursor, page.PrevCursor)
+			}
+		}
+	}
+}
+
+func TestGetBooksPageSeesWrites(t *testing.T) {
+	books := newPagedBooks(t)
+	first, err := books.GetBooksPage(models.BookFilter{}, "", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := books.CreateBook(&models.Book{Title: "H", Author: "A", Year: 2020}); err != nil {
+		t.Fatal(err)
+	}
+	// A book added after the first page still turns up on the next one, as
+	// keyset pages don't shift the way offsets do.
+	next, err := books.GetBooksPage(models.BookFilter{}, first.NextCursor, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := pageIDs(next); !sameIDs(got, []uint{6, 7, 8}) || next.Total != 8 {
+		t.Errorf("got %v of %d, want [6 7 8] of 8", got, next.Total)
+	}
+}
+
+func TestGetBooksPageInvalidCursor(t *testing.T) {
+	books := newPagedBooks(t)
+	page, err := books.GetBooksPage(models.BookFilter{Sort: "year"}, "", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, token := range []string{"garbage", page.NextCursor} {
+		if _, err := books.GetBooksPage(models.BookFilter{Sort: "title"}, token, 2); !errors.Is(err, models.ErrInvalidCursor) {
+			t.Errorf("%q: got %v, want ErrInvalidCursor", token, err)
+		}
+	}
+}
+
+func countIDs(pages [][]uint) int {
+	n := 0
+	for _, page := range pages {
+		n += len(page)
+	}
+	return n
+}
+
+func intPtr(n int) *int { return &n }
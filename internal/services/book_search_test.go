@@ -0,0 +1,47 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/shani34/book-management-system/internal/models"
+	"github.com/shani34/book-management-system/internal/repositories"
+	"github.com/shani34/book-management-system/pkg/cache"
+)
+
+func TestSearchBooksCacheInvalidation(t *testing.T) {
+	books := NewBookService(repositories.NewMemoryBookRepository(repositories.NewMemoryDB()), cache.NewLRU(0)).ForTenant("t")
+	if err := books.CreateBook(&models.Book{Title: "Dune", Author: "Frank Herbert", Year: 1965}); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := books.SearchBooks("dune", 10, 0)
+	if err != nil || len(results) != 1 {
+		t.Fatalf("first search: got %d results, %v; want 1", len(results), err)
+	}
+
+	// The cached result must not hide a book created after it.
+	if err := books.CreateBook(&models.Book{Title: "Dune Messiah", Author: "Frank Herbert", Year: 1969}); err != nil {
+		t.Fatal(err)
+	}
+	results, err = books.SearchBooks("dune", 10, 0)
+	if err != nil || len(results) != 2 {
+		t.Fatalf("after create: got %d results, %v; want 2", len(results), err)
+	}
+
+	// Nor a change to the title that matched.
+	if err := books.UpdateBook(2, 0, &models.Book{Title: "Messiah", Author: "Frank Herbert", Year: 1969}); err != nil {
+		t.Fatal(err)
+	}
+	results, err = books.SearchBooks("dune", 10, 0)
+	if err != nil || len(results) != 1 || results[0].ID != 1 {
+		t.Fatalf("after update: got %v, %v; want book 1 only", results, err)
+	}
+
+	if err := books.DeleteBook(1, 0); err != nil {
+		t.Fatal(err)
+	}
+	results, err = books.SearchBooks("dune", 10, 0)
+	if err != nil || len(results) != 0 {
+		t.Fatalf("after delete: got %v, %v; want none", results, err)
+	}
+}
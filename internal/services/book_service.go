@@ -2,22 +2,33 @@ package services
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
 	"github.com/shani34/book-management-system/internal/models"
 	"github.com/shani34/book-management-system/internal/repositories"
-	"github.com/shani34/book-management-system/pkg/kafka"
-	"github.com/shani34/book-management-system/pkg/redis"
-	"log"
+	"github.com/shani34/book-management-system/pkg/cache"
+	"github.com/shani34/book-management-system/pkg/isbn"
+	"gorm.io/gorm"
 	"time"
 )
 
+const bookEventsTopic = "book_events"
+
+// BookService serves one tenant's catalogue at a time; get it for a
+// request's tenant with ForTenant. The service returned by NewBookService
+// is bound to no tenant and sees no books.
 type BookService struct {
-	repo    *repositories.BookRepository
-	cache   *redis.RedisClient
+	repo    repositories.BookStore
+	cache   cache.Cache
 	timeout time.Duration
+	tenant  string
 }
 
-func NewBookService(repo *repositories.BookRepository, cache *redis.RedisClient) *BookService {
+func NewBookService(repo repositories.BookStore, cache cache.Cache) *BookService {
 	return &BookService{
 		repo:    repo,
 		cache:   cache,
@@ -25,8 +36,19 @@ func NewBookService(repo *repositories.BookRepository, cache *redis.RedisClient)
 	}
 }
 
-func (s *BookService) GetAllBooks(limit, offset int) ([]models.Book, error) {
-	cacheKey := fmt.Sprintf("books:%d:%d", limit, offset)
+// ForTenant returns the service for tenantID. Its queries, cache keys and
+// events are all confined to that tenant.
+func (s *BookService) ForTenant(tenantID string) *BookService {
+	return &BookService{
+		repo:    s.repo.ForTenant(tenantID),
+		cache:   cache.ForTenant(s.cache, tenantID),
+		timeout: s.timeout,
+		tenant:  tenantID,
+	}
+}
+
+func (s *BookService) GetAllBooks(filter models.BookFilter, limit, offset int) ([]models.Book, error) {
+	cacheKey := fmt.Sprintf("books:list:%s:%d:%d", filter.CacheKey(), limit, offset)
 	
 	if cached, err := s.cache.Get(cacheKey); err == nil {
 		var books []models.Book
@@ -35,7 +57,7 @@ func (s *BookService) GetAllBooks(limit, offset int) ([]models.Book, error) {
 		}
 	}
 
-	books, err := s.repo.GetAll(limit, offset)
+	books, err := s.repo.GetAll(filter, limit, offset)
 	if err != nil {
 		return nil, err
 	}
@@ -47,12 +69,77 @@ func (s *BookService) GetAllBooks(limit, offset int) ([]models.Book, error) {
 	return books, nil
 }
 
+// GetBooksPage returns one page of books in keyset order. An empty cursor
+// token starts from the beginning of the listing.
+func (s *BookService) GetBooksPage(filter models.BookFilter, token string, limit int) (*models.BookPage, error) {
+	var cursor *models.BookCursor
+	if token != "" {
+		var err error
+		if cursor, err = models.DecodeBookCursor(token, filter); err != nil {
+			return nil, err
+		}
+	}
+
+	cacheKey := fmt.Sprintf("books:page:%s:%s:%d", filter.CacheKey(), token, limit)
+
+	if cached, err := s.cache.Get(cacheKey); err == nil {
+		var page models.BookPage
+		if json.Unmarshal([]byte(cached), &page) == nil {
+			return &page, nil
+		}
+	}
+
+	// Read one extra row to learn whether there is anything beyond this page.
+	books, err := s.repo.GetPage(filter, cursor, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	backwards := cursor != nil && cursor.Before
+	hasMore := len(books) > limit
+	if hasMore {
+		if backwards {
+			books = books[1:]
+		} else {
+			books = books[:limit]
+		}
+	}
+
+	total, err := s.repo.Count(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.BookPage{Items: books, Total: total}
+	if len(books) > 0 {
+		// Paging backwards always leaves the page we came from ahead of us,
+		// and paging forwards from a cursor always leaves one behind.
+		if hasMore || backwards {
+			page.NextCursor = models.NewBookCursor(books[len(books)-1], filter, false).Encode()
+		}
+		if (hasMore && backwards) || (cursor != nil && !backwards) {
+			page.PrevCursor = models.NewBookCursor(books[0], filter, true).Encode()
+		}
+	}
+
+	if serialized, err := json.Marshal(page); err == nil {
+		s.cache.Set(cacheKey, string(serialized), s.timeout)
+	}
+
+	return page, nil
+}
+
+// ExportBooks streams every book matching filter to fn. Exports bypass the
+// cache since they read the whole table.
+func (s *BookService) ExportBooks(filter models.BookFilter, fn func(book *models.Book) error) error {
+	return s.repo.Each(filter, fn)
+}
+
 func (s *BookService) GetBookByID(id uint) (*models.Book, error) {
 	cacheKey := fmt.Sprintf("book:%d", id)
 	
-	if cached, err := s.cache.Get(cacheKey); err == nil {
+	if cached, err := s.cache.Get(cacheKey); err == nil && !s.isTrashed(id) {
 		var book models.Book
-		if json.Unmarshal([]byte(cached), &book) == nil {
+		if json.Unmarshal([]byte(cached), &book) == nil && !book.DeletedAt.Valid {
 			return &book, nil
 		}
 	}
@@ -69,75 +156,470 @@ func (s *BookService) GetBookByID(id uint) (*models.Book, error) {
 	return book, nil
 }
 
+// GetBookByISBN looks a book up by ISBN-10 or ISBN-13, hyphenated or not.
+// The cache maps the ISBN to the book ID and the book itself is read
+// through GetBookByID, so its entry and tombstone stay the single source
+// of truth; a mapping left stale by an update or delete is dropped.
+func (s *BookService) GetBookByISBN(value string) (*models.Book, error) {
+	normalized, err := isbn.Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidISBN, err)
+	}
+	if len(normalized) == 10 {
+		normalized = isbn.To13(normalized)
+	}
+	cacheKey := fmt.Sprintf("book:isbn:%s", normalized)
+
+	if cached, err := s.cache.Get(cacheKey); err == nil {
+		if id, err := strconv.ParseUint(cached, 10, 64); err == nil {
+			book, err := s.GetBookByID(uint(id))
+			if err == nil && book.ISBN13 == normalized {
+				return book, nil
+			}
+		}
+		s.cache.Delete(cacheKey)
+	}
+
+	book, err := s.repo.GetByISBN13(normalized)
+	if err != nil {
+		return nil, err
+	}
+
+	s.cache.Set(cacheKey, strconv.FormatUint(uint64(book.ID), 10), s.timeout)
+	if serialized, err := json.Marshal(book); err == nil {
+		s.cache.Set(fmt.Sprintf("book:%d", book.ID), string(serialized), s.timeout)
+	}
+
+	return book, nil
+}
+
+// SearchBooks runs a full-text query over title and author, best matches
+// first. Results share the "books:" cache prefix so writes invalidate them.
+func (s *BookService) SearchBooks(query string, limit, offset int) ([]models.BookSearchResult, error) {
+	cacheKey := fmt.Sprintf("books:search:%s:%d:%d", url.QueryEscape(query), limit, offset)
+
+	if cached, err := s.cache.Get(cacheKey); err == nil {
+		var results []models.BookSearchResult
+		if json.Unmarshal([]byte(cached), &results) == nil {
+			return results, nil
+		}
+	}
+
+	results, err := s.repo.Search(query, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	if serialized, err := json.Marshal(results); err == nil {
+		s.cache.Set(cacheKey, string(serialized), s.timeout)
+	}
+
+	return results, nil
+}
+
+// CreateBook adds book to the tenant's catalogue. Fields the server owns
+// are cleared first: a client-chosen id could otherwise land on another
+// book, and deleted_at would create it straight into the trash.
 func (s *BookService) CreateBook(book *models.Book) error {
 	if err := validateBook(book); err != nil {
 		return err
 	}
+	book.ID, book.Version, book.TenantID = 0, 0, ""
+	book.CreatedAt, book.UpdatedAt = time.Time{}, time.Time{}
+	book.DeletedAt = gorm.DeletedAt{}
+	book.Tags, book.Editions = nil, nil
 
-	if err := s.repo.Create(book); err != nil {
+	err := s.repo.Transaction(func(tx repositories.BookStore) error {
+		if err := tx.Create(book); err != nil {
+			return err
+		}
+		if err := linkAuthors(tx, book, true); err != nil {
+			return err
+		}
+		return s.publishKafkaEvent(tx, "book_created", book)
+	})
+	if err != nil {
 		return err
 	}
 
-	s.cache.Delete("books:*")
-	s.publishKafkaEvent("book_created", book)
+	s.cache.DeletePrefix("books:")
 	return nil
 }
 
-func (s *BookService) UpdateBook(id uint, book *models.Book) error {
+// UpdateBook replaces the book with id. version is the version the caller
+// last saw; 0 skips the check, for If-Match: *.
+func (s *BookService) UpdateBook(id uint, version uint, book *models.Book) error {
 	existing, err := s.repo.GetByID(id)
 	if err != nil {
 		return err
 	}
+	if err := checkVersion(existing, version); err != nil {
+		return err
+	}
 
 	if err := validateBook(book); err != nil {
 		return err
 	}
 
-	book.ID = id
+	return s.replaceBook(existing, book)
+}
+
+// PatchBook applies apply to the JSON form of the stored book and saves the
+// result if it still validates. id, tenant_id, version, created_at and
+// deleted_at cannot be patched.
+func (s *BookService) PatchBook(id uint, version uint, apply func(doc []byte) ([]byte, error)) (*models.Book, error) {
+	existing, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion(existing, version); err != nil {
+		return nil, err
+	}
+
+	doc, err := json.Marshal(existing)
+	if err != nil {
+		return nil, err
+	}
+	patched, err := apply(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var book models.Book
+	if err := json.Unmarshal(patched, &book); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBook, err)
+	}
+	// The document carries both ISBN forms; when the patch changed only
+	// one, re-derive the other instead of reporting a mismatch.
+	if book.ISBN13 != existing.ISBN13 && book.ISBN10 == existing.ISBN10 {
+		book.ISBN10 = ""
+	} else if book.ISBN10 != existing.ISBN10 && book.ISBN13 == existing.ISBN13 {
+		book.ISBN13 = ""
+	}
+	if err := validateBook(&book); err != nil {
+		return nil, err
+	}
+
+	if err := s.replaceBook(existing, &book); err != nil {
+		return nil, err
+	}
+	return &book, nil
+}
+
+// replaceBook saves book over existing. The store only writes it if the row
+// is still at existing.Version, so an update that raced ours since we read
+// existing is reported as models.ErrVersionConflict instead of being lost.
+// The tenant and trash state are kept: a body or patch can set neither.
+func (s *BookService) replaceBook(existing, book *models.Book) error {
+	book.ID = existing.ID
+	book.TenantID = existing.TenantID
 	book.CreatedAt = existing.CreatedAt
-	
-	if err := s.repo.Update(book); err != nil {
+	book.DeletedAt = existing.DeletedAt
+	book.Version = existing.Version
+	// Tags and editions only change through their own endpoints.
+	book.Tags = existing.Tags
+	book.Editions = existing.Editions
+
+	// Only relink by name when the author string changed, so links made
+	// explicitly through author_ids survive an unrelated edit.
+	renamed := strings.TrimSpace(book.Author) != strings.TrimSpace(existing.Author)
+	if len(book.AuthorIDs) == 0 && !renamed {
+		book.Authors = existing.Authors
+	}
+
+	err := s.repo.Transaction(func(tx repositories.BookStore) error {
+		if err := tx.Update(book); err != nil {
+			return err
+		}
+		if err := linkAuthors(tx, book, renamed); err != nil {
+			return err
+		}
+		return s.publishKafkaEvent(tx, "book_updated", book)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.cache.Delete(fmt.Sprintf("book:%d", book.ID))
+	s.cache.DeletePrefix("books:")
+	return nil
+}
+
+// ErrTagNotFound is returned when attaching a tag that isn't in the
+// vocabulary.
+var ErrTagNotFound = errors.New("tag not found")
+
+// AttachTag adds the tag to the book and DetachTag removes it. A change
+// bumps the book's version and publishes book_updated; attaching a tag the
+// book already has, or detaching one it lacks, changes nothing.
+func (s *BookService) AttachTag(bookID, tagID uint) (*models.Book, error) {
+	return s.changeTags(bookID, tagID, true)
+}
+
+func (s *BookService) DetachTag(bookID, tagID uint) (*models.Book, error) {
+	return s.changeTags(bookID, tagID, false)
+}
+
+func (s *BookService) changeTags(bookID, tagID uint, attach bool) (*models.Book, error) {
+	var book *models.Book
+	err := s.repo.Transaction(func(tx repositories.BookStore) error {
+		var err error
+		if book, err = tx.GetByID(bookID); err != nil {
+			return err
+		}
+		tagged := slices.ContainsFunc(book.Tags, func(tag models.Tag) bool { return tag.ID == tagID })
+		if tagged == attach {
+			return nil
+		}
+
+		var tags []models.Tag
+		if attach {
+			tags, err = tx.AttachTag(bookID, tagID)
+		} else {
+			tags, err = tx.DetachTag(bookID, tagID)
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrTagNotFound
+		}
+		if err != nil {
+			return err
+		}
+		book.Tags = tags
+
+		if err := tx.Update(book); err != nil {
+			return err
+		}
+		return s.publishKafkaEvent(tx, "book_updated", book)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.cache.Delete(fmt.Sprintf("book:%d", bookID))
+	s.cache.DeletePrefix("books:")
+	return book, nil
+}
+
+// DeleteBook deletes the book with id if it is still at version; 0 skips
+// the check.
+func (s *BookService) DeleteBook(id uint, version uint) error {
+	err := s.repo.Transaction(func(tx repositories.BookStore) error {
+		if err := tx.Delete(id, version); err != nil {
+			return err
+		}
+		return s.publishKafkaEvent(tx, "book_deleted", map[string]interface{}{"id": id})
+	})
+	if err != nil {
+		return err
+	}
+
+	s.cache.Set(trashedKey(id), "1", s.timeout)
+	s.cache.Delete(fmt.Sprintf("book:%d", id))
+	s.cache.DeletePrefix("books:")
+	return nil
+}
+
+// ListTrash returns soft-deleted books. The trash is never cached.
+func (s *BookService) ListTrash(limit, offset int) ([]models.Book, error) {
+	return s.repo.ListDeleted(limit, offset)
+}
+
+func (s *BookService) RestoreBook(id uint) (*models.Book, error) {
+	var book *models.Book
+	err := s.repo.Transaction(func(tx repositories.BookStore) error {
+		var err error
+		if book, err = tx.Restore(id); err != nil {
+			return err
+		}
+		return s.publishKafkaEvent(tx, "book_restored", book)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.cache.Delete(trashedKey(id), fmt.Sprintf("book:%d", id))
+	s.cache.DeletePrefix("books:")
+	return book, nil
+}
+
+// PurgeBook permanently deletes a book that is already in the trash.
+func (s *BookService) PurgeBook(id uint) error {
+	err := s.repo.Transaction(func(tx repositories.BookStore) error {
+		if err := tx.Purge(id); err != nil {
+			return err
+		}
+		return s.publishKafkaEvent(tx, "book_purged", map[string]interface{}{"id": id})
+	})
+	if err != nil {
 		return err
 	}
 
-	s.cache.Delete(fmt.Sprintf("book:%d", id), "books:*")
-	s.publishKafkaEvent("book_updated", book)
+	// As in DeleteBook, the marker keeps a copy cached by a racing read
+	// from being served.
+	s.cache.Set(trashedKey(id), "1", s.timeout)
+	s.cache.Delete(fmt.Sprintf("book:%d", id))
+	s.cache.DeletePrefix("books:")
 	return nil
 }
 
-func (s *BookService) DeleteBook(id uint) error {
-	if err := s.repo.Delete(id); err != nil {
+// linkAuthors links book to the authors in book.AuthorIDs. Without IDs, and
+// when byName is set, it links the single author named by book.Author,
+// creating the Author row if needed. The free-text Author field is kept as
+// the display string either way.
+func linkAuthors(tx repositories.BookStore, book *models.Book, byName bool) error {
+	ids := book.AuthorIDs
+	if len(ids) == 0 {
+		if !byName {
+			return nil
+		}
+		author, err := tx.EnsureAuthor(strings.TrimSpace(book.Author))
+		if err != nil {
+			return err
+		}
+		ids = []uint{author.ID}
+	}
+
+	authors, err := tx.SetBookAuthors(book.ID, ids)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("%w: unknown author id", ErrInvalidBook)
+	}
+	if err != nil {
 		return err
 	}
+	book.Authors = authors
+	book.AuthorIDs = nil
+	return nil
+}
+
+// trashedKey marks a book as deleted for as long as a cached copy of it
+// could survive. A read that raced the delete may still have written the
+// live book back to the cache after we invalidated it; the marker keeps that
+// copy from being served.
+func trashedKey(id uint) string {
+	return fmt.Sprintf("book:%d:trashed", id)
+}
 
-	s.cache.Delete(fmt.Sprintf("book:%d", id), "books:*")
-	s.publishKafkaEvent("book_deleted", map[string]interface{}{"id": id})
+func (s *BookService) isTrashed(id uint) bool {
+	_, err := s.cache.Get(trashedKey(id))
+	return err == nil
+}
+
+func checkVersion(existing *models.Book, version uint) error {
+	if version != 0 && existing.Version != version {
+		return models.ErrVersionConflict
+	}
 	return nil
 }
 
+// ErrInvalidBook wraps every validation failure so handlers can tell bad
+// input apart from storage errors.
+var ErrInvalidBook = errors.New("invalid book")
+
+// ErrInvalidISBN is returned for lookups by a malformed ISBN.
+var ErrInvalidISBN = errors.New("invalid isbn")
+
 func validateBook(book *models.Book) error {
 	if book.Title == "" {
-		return fmt.Errorf("title is required")
+		return fmt.Errorf("%w: title is required", ErrInvalidBook)
 	}
-	if book.Author == "" {
-		return fmt.Errorf("author is required")
+	if strings.TrimSpace(book.Author) == "" {
+		return fmt.Errorf("%w: author is required", ErrInvalidBook)
 	}
 	if book.Year < 0 || book.Year > time.Now().Year()+1 {
-		return fmt.Errorf("invalid year")
+		return fmt.Errorf("%w: invalid year", ErrInvalidBook)
+	}
+	return normalizeISBNs(book)
+}
+
+// normalizeISBNs validates the book's ISBNs and stores them without
+// hyphens. Whichever form is missing is derived from the other where one
+// exists, so every book with an ISBN can be looked up by its ISBN-13.
+func normalizeISBNs(book *models.Book) error {
+	if book.ISBN10 != "" {
+		book.ISBN10 = isbn.Normalize(book.ISBN10)
+		if err := isbn.Validate10(book.ISBN10); err != nil {
+			return fmt.Errorf("%w: isbn10: %v", ErrInvalidBook, err)
+		}
+	}
+	if book.ISBN13 != "" {
+		book.ISBN13 = isbn.Normalize(book.ISBN13)
+		if err := isbn.Validate13(book.ISBN13); err != nil {
+			return fmt.Errorf("%w: isbn13: %v", ErrInvalidBook, err)
+		}
+	}
+
+	switch {
+	case book.ISBN10 != "" && book.ISBN13 == "":
+		book.ISBN13 = isbn.To13(book.ISBN10)
+	case book.ISBN10 == "" && book.ISBN13 != "":
+		book.ISBN10, _ = isbn.To10(book.ISBN13)
+	case book.ISBN10 != "" && book.ISBN13 != "":
+		if isbn.To13(book.ISBN10) != book.ISBN13 {
+			return fmt.Errorf("%w: isbn10 and isbn13 identify different books", ErrInvalidBook)
+		}
+	}
+	return nil
+}
+
+// publishKafkaEvent records the event in the outbox as part of tx. The
+// OutboxRelay delivers it once the transaction has committed.
+func (s *BookService) publishKafkaEvent(tx repositories.BookStore, eventType string, payload interface{}) error {
+	return enqueueEvent(tx, s.tenant, bookEventsTopic, eventType, payload)
+}
+
+// touchBook bumps the version of the tenant's book with id and queues
+// book_updated for it through tx. It is for changes outside the books row
+// that single-book reads still show: editions, and author and tag names.
+func touchBook(tx repositories.BookStore, tenantID string, id uint) error {
+	books := tx.ForTenant(tenantID)
+	book, err := books.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if err := books.Update(book); err != nil {
+		return err
+	}
+	return enqueueEvent(tx, tenantID, bookEventsTopic, "book_updated", book)
+}
+
+// touchBooks calls touchBook for each of books in the book's own tenant.
+func touchBooks(tx repositories.BookStore, books []models.Book) error {
+	for _, book := range books {
+		if err := touchBook(tx, book.TenantID, book.ID); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
-func (s *BookService) publishKafkaEvent(eventType string, payload interface{}) {
+// forgetBooks drops the cached copies of books and every cached listing
+// after a change to something they embed.
+func forgetBooks(c cache.Cache, books []models.Book) {
+	for _, book := range books {
+		c.Delete(fmt.Sprintf("book:%d", book.ID))
+	}
+	c.DeletePrefix("books:")
+}
+
+// enqueueEvent writes an event envelope for topic to the outbox through tx,
+// tagged with the tenant it concerns.
+func enqueueEvent(tx repositories.OutboxStore, tenantID, topic, eventType string, payload interface{}) error {
 	event := map[string]interface{}{
 		"event_type": eventType,
+		"tenant_id":  tenantID,
 		"payload":    payload,
 		"timestamp":  time.Now().UTC(),
 	}
 
-	if eventData, err := json.Marshal(event); err == nil {
-		if err := kafka.PublishEvent("book_events", eventData); err != nil {
-			log.Printf("Failed to publish Kafka event: %v", err)
-		}
+	eventData, err := json.Marshal(event)
+	if err != nil {
+		return err
 	}
-}
\ No newline at end of file
+
+	return tx.EnqueueEvent(&models.OutboxEvent{
+		Topic:     topic,
+		TenantID:  tenantID,
+		EventType: eventType,
+		Message:   string(eventData),
+	})
+}
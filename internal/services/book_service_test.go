@@ -0,0 +1,82 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shani34/book-management-system/internal/models"
+	"github.com/shani34/book-management-system/pkg/patch"
+	"gorm.io/gorm"
+)
+
+func TestPatchBookKeepsServerOwnedFields(t *testing.T) {
+	tests := []struct {
+		name  string
+		apply func(doc, patchDoc []byte) ([]byte, error)
+		patch string
+	}{
+		{
+			name:  "merge patch",
+			apply: patch.MergePatch,
+			patch: `{"title":"Dune Messiah","tenant_id":"b","deleted_at":"2024-01-01T00:00:00Z"}`,
+		},
+		{
+			name:  "json patch",
+			apply: patch.JSONPatch,
+			patch: `[
+				{"op":"replace","path":"/title","value":"Dune Messiah"},
+				{"op":"replace","path":"/tenant_id","value":"b"},
+				{"op":"add","path":"/deleted_at","value":"2024-01-01T00:00:00Z"}
+			]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b, book := newTenantBooks(t)
+
+			patched, err := a.PatchBook(book.ID, 0, func(doc []byte) ([]byte, error) {
+				return tt.apply(doc, []byte(tt.patch))
+			})
+			if err != nil {
+				t.Fatalf("PatchBook: %v", err)
+			}
+			if patched.Title != "Dune Messiah" {
+				t.Errorf("title: got %q, want the patched one", patched.Title)
+			}
+			if patched.TenantID != "a" || patched.DeletedAt.Valid {
+				t.Errorf("patched book: tenant %q, deleted %v; want a, live", patched.TenantID, patched.DeletedAt.Valid)
+			}
+
+			got, err := a.GetBookByID(book.ID)
+			if err != nil || got.Title != "Dune Messiah" {
+				t.Errorf("GetBookByID from a: got %+v, %v", got, err)
+			}
+			if trash, err := a.ListTrash(10, 0); err != nil || len(trash) != 0 {
+				t.Errorf("ListTrash from a: got %d books, %v; want none", len(trash), err)
+			}
+			if _, err := b.GetBookByID(book.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+				t.Errorf("GetBookByID from b: got %v, want not found", err)
+			}
+		})
+	}
+}
+
+func TestUpdateBookKeepsServerOwnedFields(t *testing.T) {
+	a, _, book := newTenantBooks(t)
+
+	update := &models.Book{
+		TenantID:  "b",
+		DeletedAt: gorm.DeletedAt{Valid: true},
+		Title:     "Dune Messiah",
+		Author:    "Frank Herbert",
+	}
+	if err := a.UpdateBook(book.ID, 0, update); err != nil {
+		t.Fatalf("UpdateBook: %v", err)
+	}
+	if update.TenantID != "a" || update.DeletedAt.Valid {
+		t.Errorf("updated book: tenant %q, deleted %v; want a, live", update.TenantID, update.DeletedAt.Valid)
+	}
+	if got, err := a.GetBookByID(book.ID); err != nil || got.Title != "Dune Messiah" {
+		t.Errorf("GetBookByID from a: got %+v, %v", got, err)
+	}
+}
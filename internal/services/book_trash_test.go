@@ -0,0 +1,138 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/shani34/book-management-system/internal/models"
+	"github.com/shani34/book-management-system/internal/repositories"
+	"github.com/shani34/book-management-system/pkg/cache"
+	"gorm.io/gorm"
+)
+
+// newCachedBooks returns tenant a's book service over an LRU, the LRU as
+// that tenant sees it, and a stored book that has been read into the
+// cache. A listing is cached as books:listing.
+func newCachedBooks(t *testing.T) (*BookService, cache.Cache, *models.Book) {
+	t.Helper()
+	lru := cache.NewLRU(100)
+	books := NewBookService(repositories.NewMemoryBookRepository(repositories.NewMemoryDB()), lru).ForTenant("a")
+	book := &models.Book{Title: "Dune", Author: "Frank Herbert", Year: 1965}
+	if err := books.CreateBook(book); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := books.GetBookByID(book.ID); err != nil {
+		t.Fatal(err)
+	}
+	cached := cache.ForTenant(lru, "a")
+	cached.Set("books:listing", "[]", 0)
+	return books, cached, book
+}
+
+// assertCacheEntry checks whether key is in c.
+func assertCacheEntry(t *testing.T, c cache.Cache, key string, want bool) {
+	t.Helper()
+	if _, err := c.Get(key); (err == nil) != want {
+		t.Errorf("cache entry %s: present = %v, want %v", key, err == nil, want)
+	}
+}
+
+func TestTrashAndRestoreBook(t *testing.T) {
+	books, cached, book := newCachedBooks(t)
+	bookKey := fmt.Sprintf("book:%d", book.ID)
+	assertCacheEntry(t, cached, bookKey, true)
+
+	if err := books.DeleteBook(book.ID, book.Version); err != nil {
+		t.Fatal(err)
+	}
+	assertCacheEntry(t, cached, bookKey, false)
+	assertCacheEntry(t, cached, trashedKey(book.ID), true)
+	assertCacheEntry(t, cached, "books:listing", false)
+	if _, err := books.GetBookByID(book.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("GetBookByID of a trashed book: got %v, want not found", err)
+	}
+	if live, err := books.GetAllBooks(models.BookFilter{}, 10, 0); err != nil || len(live) != 0 {
+		t.Errorf("GetAllBooks: got %d books, %v; want none", len(live), err)
+	}
+	if trash, err := books.ListTrash(10, 0); err != nil || len(trash) != 1 || trash[0].ID != book.ID || !trash[0].DeletedAt.Valid {
+		t.Errorf("ListTrash: got %+v, %v; want the book", trash, err)
+	}
+	if err := books.DeleteBook(book.ID, 0); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("second DeleteBook: got %v, want not found", err)
+	}
+
+	cached.Set("books:listing", "[]", 0)
+	restored, err := books.RestoreBook(book.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.DeletedAt.Valid || restored.Version <= book.Version {
+		t.Errorf("restored book: got %+v, want live with a new version", restored)
+	}
+	assertCacheEntry(t, cached, trashedKey(book.ID), false)
+	assertCacheEntry(t, cached, "books:listing", false)
+	if got, err := books.GetBookByID(book.ID); err != nil || got.Title != "Dune" {
+		t.Errorf("GetBookByID after restore: got %+v, %v", got, err)
+	}
+	if trash, err := books.ListTrash(10, 0); err != nil || len(trash) != 0 {
+		t.Errorf("ListTrash after restore: got %d books, %v; want none", len(trash), err)
+	}
+	if _, err := books.RestoreBook(book.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("restoring a live book: got %v, want not found", err)
+	}
+}
+
+func TestPurgeBook(t *testing.T) {
+	books, cached, book := newCachedBooks(t)
+	bookKey := fmt.Sprintf("book:%d", book.ID)
+
+	if err := books.PurgeBook(book.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("purging a live book: got %v, want not found", err)
+	}
+	assertCacheEntry(t, cached, bookKey, true)
+
+	if err := books.DeleteBook(book.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+	// A read that raced the delete cached the live book again.
+	live, _ := json.Marshal(book)
+	cached.Set(bookKey, string(live), 0)
+	cached.Set("books:listing", "[]", 0)
+
+	if err := books.PurgeBook(book.ID); err != nil {
+		t.Fatal(err)
+	}
+	assertCacheEntry(t, cached, bookKey, false)
+	assertCacheEntry(t, cached, trashedKey(book.ID), true)
+	assertCacheEntry(t, cached, "books:listing", false)
+
+	// And one that raced the purge.
+	cached.Set(bookKey, string(live), 0)
+	if _, err := books.GetBookByID(book.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("GetBookByID of a purged book: got %v, want not found", err)
+	}
+	if trash, err := books.ListTrash(10, 0); err != nil || len(trash) != 0 {
+		t.Errorf("ListTrash after purge: got %d books, %v; want none", len(trash), err)
+	}
+	if _, err := books.RestoreBook(book.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("restoring a purged book: got %v, want not found", err)
+	}
+	if err := books.PurgeBook(book.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("second PurgeBook: got %v, want not found", err)
+	}
+}
+
+func TestPurgeBookWithCopies(t *testing.T) {
+	l := newLending(t)
+	if err := l.books.DeleteBook(l.book.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.books.PurgeBook(l.book.ID); !errors.Is(err, models.ErrBookHasCopies) {
+		t.Errorf("PurgeBook: got %v, want ErrBookHasCopies", err)
+	}
+	if trash, err := l.books.ListTrash(10, 0); err != nil || len(trash) != 1 {
+		t.Errorf("ListTrash: got %d books, %v; want the book kept", len(trash), err)
+	}
+}
@@ -0,0 +1,217 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shani34/book-management-system/internal/models"
+	"github.com/shani34/book-management-system/internal/repositories"
+	"github.com/shani34/book-management-system/pkg/cache"
+	"github.com/shani34/book-management-system/pkg/events"
+	"go.uber.org/zap"
+)
+
+const testLoanPeriod = 14 * 24 * time.Hour
+
+type lending struct {
+	books       *BookService
+	circulation *CirculationService
+	members     *MemberService
+	repo        *repositories.MemoryCirculationRepository
+	book        *models.Book
+	copy        *models.Copy
+	jane, joe   *models.Member
+}
+
+// newLending stores one book with one available copy, LIB-1, and two
+// members, Jane and Joe, on a 14 day tier.
+func newLending(t *testing.T) *lending {
+	t.Helper()
+	db := repositories.NewMemoryDB()
+	bookRepo := repositories.NewMemoryBookRepository(db)
+	l := &lending{
+		books:   NewBookService(bookRepo, cache.Noop{}).ForTenant("t"),
+		members: NewMemberService(repositories.NewMemoryMemberRepository(db)).ForTenant("t"),
+		repo:    repositories.NewMemoryCirculationRepository(db),
+		book:    &models.Book{Title: "Dune", Author: "Frank Herbert", Year: 1965},
+		copy:    &models.Copy{Barcode: " LIB-1 "},
+		jane:    &models.Member{Name: "Jane", Email: "jane@example.com"},
+		joe:     &models.Member{Name: "Joe", Email: "joe@example.com"},
+	}
+	l.circulation = NewCirculationService(l.repo, bookRepo, 3*24*time.Hour).ForTenant("t")
+	if err := l.books.CreateBook(l.book); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.circulation.CreateCopy(l.book.ID, l.copy); err != nil {
+		t.Fatal(err)
+	}
+	tier := &models.Tier{Name: "lending", MaxLoans: 5, LoanDays: 14}
+	if err := l.members.CreateTier(tier); err != nil {
+		t.Fatal(err)
+	}
+	for _, member := range []*models.Member{l.jane, l.joe} {
+		member.TierID = tier.ID
+		if err := l.members.CreateMember(member); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return l
+}
+
+func (l *lending) copyStatus(t *testing.T) string {
+	t.Helper()
+	bookCopy, err := l.circulation.GetCopy(l.copy.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bookCopy.Status
+}
+
+func TestCheckoutAndReturn(t *testing.T) {
+	l := newLending(t)
+	if l.copy.Barcode != "LIB-1" || l.copy.Status != models.CopyAvailable {
+		t.Fatalf("created copy: got %+v", l.copy)
+	}
+
+	before := time.Now()
+	loan, err := l.circulation.Checkout(models.CheckoutRequest{Barcode: "LIB-1", MemberID: l.jane.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loan.CopyID != l.copy.ID || loan.BookID != l.book.ID || loan.MemberID != l.jane.ID || loan.ReturnedAt != nil {
+		t.Errorf("loan: got %+v", loan)
+	}
+	if due := loan.DueAt.Sub(loan.CheckedOutAt); due != testLoanPeriod || loan.CheckedOutAt.Before(before) {
+		t.Errorf("loan: checked out %v and due %v later, want the loan period", loan.CheckedOutAt, due)
+	}
+	if status := l.copyStatus(t); status != models.CopyOnLoan {
+		t.Errorf("copy on loan: got status %q", status)
+	}
+
+	// One copy, one loan.
+	if _, err := l.circulation.Checkout(models.CheckoutRequest{CopyID: l.copy.ID, MemberID: l.joe.ID}); !errors.Is(err, models.ErrCopyUnavailable) {
+		t.Errorf("second checkout: got %v, want ErrCopyUnavailable", err)
+	}
+	active, err := l.circulation.GetLoans(models.LoanFilter{CopyID: l.copy.ID, ActiveOnly: true}, 10, 0)
+	if err != nil || len(active) != 1 {
+		t.Errorf("active loans: got %v, %v; want 1", active, err)
+	}
+
+	returned, err := l.circulation.Return(loan.ID)
+	if err != nil || returned.ReturnedAt == nil {
+		t.Fatalf("return: got %+v, %v", returned, err)
+	}
+	if status := l.copyStatus(t); status != models.CopyAvailable {
+		t.Errorf("returned copy: got status %q", status)
+	}
+	if _, err := l.circulation.Return(loan.ID); !errors.Is(err, models.ErrLoanReturned) {
+		t.Errorf("second return: got %v, want ErrLoanReturned", err)
+	}
+
+	again, err := l.circulation.Checkout(models.CheckoutRequest{CopyID: l.copy.ID, MemberID: l.joe.ID})
+	if err != nil || again.ID == loan.ID {
+		t.Errorf("checkout after return: got %+v, %v", again, err)
+	}
+}
+
+func TestCheckoutEvents(t *testing.T) {
+	l := newLending(t)
+	loan, err := l.circulation.Checkout(models.CheckoutRequest{CopyID: l.copy.ID, MemberID: l.jane.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.circulation.Return(loan.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	publisher := events.NewMemoryPublisher()
+	if _, err := NewOutboxRelay(l.repo, publisher, zap.NewNop(), 0).RelayPending(); err != nil {
+		t.Fatal(err)
+	}
+	var loanEvents []string
+	for _, message := range publisher.Messages() {
+		if message.Topic != loanEventsTopic {
+			continue
+		}
+		var event struct {
+			EventType string      `json:"event_type"`
+			Payload   models.Loan `json:"payload"`
+		}
+		if err := json.Unmarshal(message.Value, &event); err != nil || event.Payload.ID != loan.ID {
+			t.Errorf("event %s: %v", message.Value, err)
+		}
+		loanEvents = append(loanEvents, event.EventType)
+	}
+	if len(loanEvents) != 2 || loanEvents[0] != "loan_created" || loanEvents[1] != "loan_returned" {
+		t.Errorf("got loan events %v", loanEvents)
+	}
+}
+
+func TestCheckoutRefusals(t *testing.T) {
+	l := newLending(t)
+	past := time.Now().Add(-time.Hour)
+	for name, request := range map[string]models.CheckoutRequest{
+		"no member":       {CopyID: l.copy.ID},
+		"unknown member":  {CopyID: l.copy.ID, MemberID: 99},
+		"no copy":         {MemberID: l.jane.ID},
+		"id and barcode":  {CopyID: l.copy.ID, Barcode: "LIB-1", MemberID: l.jane.ID},
+		"due in the past": {CopyID: l.copy.ID, MemberID: l.jane.ID, DueAt: &past},
+	} {
+		if _, err := l.circulation.Checkout(request); !errors.Is(err, ErrInvalidLoan) {
+			t.Errorf("%s: got %v, want ErrInvalidLoan", name, err)
+		}
+	}
+
+	if _, err := l.circulation.Checkout(models.CheckoutRequest{Barcode: "LIB-404", MemberID: l.jane.ID}); err == nil {
+		t.Error("unknown barcode: checked out")
+	}
+
+	// Copies off the shelf can't be lent.
+	for _, status := range []string{models.CopyMaintenance, models.CopyLost} {
+		if err := l.circulation.UpdateCopy(l.copy.ID, &models.Copy{Barcode: "LIB-1", Status: status}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := l.circulation.Checkout(models.CheckoutRequest{CopyID: l.copy.ID, MemberID: l.jane.ID}); !errors.Is(err, models.ErrCopyUnavailable) {
+			t.Errorf("%s copy: got %v, want ErrCopyUnavailable", status, err)
+		}
+	}
+	if err := l.circulation.UpdateCopy(l.copy.ID, &models.Copy{Barcode: "LIB-1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Nor can copies of a book in the trash.
+	if err := l.books.DeleteBook(l.book.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.circulation.Checkout(models.CheckoutRequest{CopyID: l.copy.ID, MemberID: l.jane.ID}); !errors.Is(err, models.ErrCopyUnavailable) {
+		t.Errorf("trashed book: got %v, want ErrCopyUnavailable", err)
+	}
+}
+
+func TestCopyOnLoanIsLocked(t *testing.T) {
+	l := newLending(t)
+	loan, err := l.circulation.Checkout(models.CheckoutRequest{CopyID: l.copy.ID, MemberID: l.jane.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := l.circulation.UpdateCopy(l.copy.ID, &models.Copy{Barcode: "LIB-1", Status: models.CopyMaintenance}); !errors.Is(err, models.ErrCopyOnLoan) {
+		t.Errorf("status change on loan: got %v, want ErrCopyOnLoan", err)
+	}
+	// Other fields can change and the copy stays on loan.
+	if err := l.circulation.UpdateCopy(l.copy.ID, &models.Copy{Barcode: "LIB-1", Location: "Shelf 2"}); err != nil {
+		t.Fatal(err)
+	}
+	if status := l.copyStatus(t); status != models.CopyOnLoan {
+		t.Errorf("after edit: got status %q, want on_loan", status)
+	}
+
+	if _, err := l.circulation.Return(loan.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.circulation.DeleteCopy(l.copy.ID); !errors.Is(err, models.ErrCopyHasLoans) {
+		t.Errorf("delete with loan history: got %v, want ErrCopyHasLoans", err)
+	}
+}
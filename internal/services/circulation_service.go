@@ -0,0 +1,543 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shani34/book-management-system/internal/models"
+	"github.com/shani34/book-management-system/internal/repositories"
+	"gorm.io/gorm"
+)
+
+const (
+	loanEventsTopic = "loan_events"
+	holdEventsTopic = "hold_events"
+
+	holdExpiryBatchSize = 100
+)
+
+// CirculationService lends out physical copies of books to members and
+// queues holds for books with no copy on the shelf. pickupWindow is how
+// long a copy set aside for a hold waits to be collected.
+type CirculationService struct {
+	repo         repositories.CirculationStore
+	books        repositories.BookStore
+	pickupWindow time.Duration
+	tenant       string
+}
+
+func NewCirculationService(repo repositories.CirculationStore, books repositories.BookStore, pickupWindow time.Duration) *CirculationService {
+	return &CirculationService{
+		repo:         repo,
+		books:        books,
+		pickupWindow: pickupWindow,
+	}
+}
+
+// ForTenant returns the service for tenantID. Books, copies, loans, holds,
+// members and fines of other tenants are not found, and events are tagged
+// with the tenant.
+func (s *CirculationService) ForTenant(tenantID string) *CirculationService {
+	return &CirculationService{
+		repo:         s.repo.ForTenant(tenantID),
+		books:        s.books.ForTenant(tenantID),
+		pickupWindow: s.pickupWindow,
+		tenant:       tenantID,
+	}
+}
+
+// GetCopies lists the copies of a live book.
+func (s *CirculationService) GetCopies(bookID uint) ([]models.Copy, error) {
+	if _, err := s.books.GetByID(bookID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetCopies(bookID)
+}
+
+func (s *CirculationService) GetCopy(id uint) (*models.Copy, error) {
+	return s.repo.GetCopy(id)
+}
+
+// CreateCopy adds a copy of a live book. An available copy goes to the
+// first waiting hold on the book, if there is one.
+func (s *CirculationService) CreateCopy(bookID uint, bookCopy *models.Copy) error {
+	if _, err := s.books.GetByID(bookID); err != nil {
+		return err
+	}
+	if err := validateCopy(bookCopy); err != nil {
+		return err
+	}
+
+	bookCopy.ID = 0
+	bookCopy.BookID = bookID
+	return s.repo.Transaction(func(tx repositories.CirculationStore) error {
+		if err := tx.CreateCopy(bookCopy); err != nil {
+			return err
+		}
+		if bookCopy.Status != models.CopyAvailable {
+			return nil
+		}
+		return s.shelve(tx, bookCopy, time.Now())
+	})
+}
+
+// UpdateCopy changes a copy's barcode, location or status. The status of a
+// copy on loan or on hold belongs to circulation, so it can't be changed
+// until the copy is returned or the hold closes; leave status empty to
+// keep it. A copy made available goes to the first waiting hold.
+func (s *CirculationService) UpdateCopy(id uint, bookCopy *models.Copy) error {
+	return s.repo.Transaction(func(tx repositories.CirculationStore) error {
+		existing, err := tx.LockCopy(id)
+		if err != nil {
+			return err
+		}
+
+		managed := existing.Status == models.CopyOnLoan || existing.Status == models.CopyOnHold
+		if managed {
+			if bookCopy.Status != "" && bookCopy.Status != existing.Status {
+				if existing.Status == models.CopyOnHold {
+					return models.ErrCopyOnHold
+				}
+				return models.ErrCopyOnLoan
+			}
+			bookCopy.Status = ""
+		}
+		if err := validateCopy(bookCopy); err != nil {
+			return err
+		}
+		if managed {
+			bookCopy.Status = existing.Status
+		}
+
+		bookCopy.ID = existing.ID
+		bookCopy.BookID = existing.BookID
+		bookCopy.CreatedAt = existing.CreatedAt
+		if err := tx.UpdateCopy(bookCopy); err != nil {
+			return err
+		}
+		if existing.Status == models.CopyAvailable || bookCopy.Status != models.CopyAvailable {
+			return nil
+		}
+		return s.shelve(tx, bookCopy, time.Now())
+	})
+}
+
+// DeleteCopy deletes a copy that was never lent out and isn't set aside
+// for a hold.
+func (s *CirculationService) DeleteCopy(id uint) error {
+	return s.repo.Transaction(func(tx repositories.CirculationStore) error {
+		bookCopy, err := tx.LockCopy(id)
+		if err != nil {
+			return err
+		}
+		if bookCopy.Status == models.CopyOnHold {
+			return models.ErrCopyOnHold
+		}
+		return tx.DeleteCopy(id)
+	})
+}
+
+func (s *CirculationService) GetLoans(filter models.LoanFilter, limit, offset int) ([]models.Loan, error) {
+	return s.repo.GetLoans(filter, limit, offset)
+}
+
+func (s *CirculationService) GetLoan(id uint) (*models.Loan, error) {
+	return s.repo.GetLoan(id)
+}
+
+// Checkout lends the copy identified by request.CopyID or request.Barcode
+// to a member in good standing who is below their tier's loan limit.
+// The member and copy rows stay locked from those checks until the loan
+// is committed, so concurrent checkouts can't overshoot the limit or lend
+// one copy twice; the partial unique index on active loans backs that up.
+func (s *CirculationService) Checkout(request models.CheckoutRequest) (*models.Loan, error) {
+	if request.MemberID == 0 {
+		return nil, fmt.Errorf("%w: member_id is required", ErrInvalidLoan)
+	}
+	now := time.Now()
+	if request.DueAt != nil && !request.DueAt.After(now) {
+		return nil, fmt.Errorf("%w: due_at must be in the future", ErrInvalidLoan)
+	}
+
+	requested, err := s.resolveCopy(request)
+	if err != nil {
+		return nil, err
+	}
+	// Copies of a book in the trash stay on the shelf.
+	if _, err := s.books.GetByID(requested.BookID); errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, models.ErrCopyUnavailable
+	} else if err != nil {
+		return nil, err
+	}
+
+	var loan *models.Loan
+	err = s.repo.Transaction(func(tx repositories.CirculationStore) error {
+		member, err := tx.LockMember(request.MemberID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: member %d does not exist", ErrInvalidLoan, request.MemberID)
+		} else if err != nil {
+			return err
+		}
+		if err := checkBorrower(tx, member, now); err != nil {
+			return err
+		}
+
+		bookCopy, err := tx.LockCopy(requested.ID)
+		if err != nil {
+			return err
+		}
+		switch bookCopy.Status {
+		case models.CopyAvailable:
+		case models.CopyOnHold:
+			if err := fulfilHold(tx, bookCopy, member); err != nil {
+				return err
+			}
+		default:
+			return models.ErrCopyUnavailable
+		}
+
+		// A due date can shorten the loan but not stretch it past the tier's
+		// loan period.
+		dueAt := now.Add(member.Tier.LoanPeriod())
+		if request.DueAt != nil {
+			if request.DueAt.After(dueAt) {
+				return fmt.Errorf("%w: due_at is past the %d day loan period of the member's tier",
+					ErrInvalidLoan, member.Tier.LoanDays)
+			}
+			dueAt = *request.DueAt
+		}
+		loan = &models.Loan{
+			CopyID:       bookCopy.ID,
+			BookID:       bookCopy.BookID,
+			MemberID:     member.ID,
+			CheckedOutAt: now,
+			DueAt:        dueAt,
+		}
+		if err := tx.CreateLoan(loan); err != nil {
+			return err
+		}
+
+		bookCopy.Status = models.CopyOnLoan
+		if err := tx.UpdateCopy(bookCopy); err != nil {
+			return err
+		}
+		return enqueueEvent(tx, s.tenant, loanEventsTopic, "loan_created", loan)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return loan, nil
+}
+
+// checkBorrower refuses members who are blocked, expired, already at the
+// loan limit of their tier or owe more in fines than it allows.
+func checkBorrower(tx repositories.CirculationStore, member *models.Member, now time.Time) error {
+	if member.Blocked {
+		return models.ErrMemberBlocked
+	}
+	if member.Expired(now) {
+		return models.ErrMembershipExpired
+	}
+	if member.Tier == nil {
+		return fmt.Errorf("member %d has no tier", member.ID)
+	}
+	active, err := tx.CountActiveLoans(member.ID)
+	if err != nil {
+		return err
+	}
+	if active >= int64(member.Tier.MaxLoans) {
+		return models.ErrLoanLimitReached
+	}
+	summary, err := summarizeFines(tx, member, now)
+	if err != nil {
+		return err
+	}
+	if summary.Blocked {
+		return models.ErrFinesOutstanding
+	}
+	return nil
+}
+
+// Return closes an active loan, assesses a fine if it is late, and puts
+// the copy back on the shelf or sets it aside for the first waiting hold
+// on its book. The copy is locked
+// before the loan and holds, in the same order as Checkout.
+func (s *CirculationService) Return(id uint) (*models.Loan, error) {
+	var loan *models.Loan
+	err := s.repo.Transaction(func(tx repositories.CirculationStore) error {
+		var err error
+		if loan, err = tx.GetLoan(id); err != nil {
+			return err
+		}
+		bookCopy, err := tx.LockCopy(loan.CopyID)
+		if err != nil {
+			return err
+		}
+		if err := tx.ReturnLoan(loan); err != nil {
+			return err
+		}
+		if err := assessFine(tx, loan); err != nil {
+			return err
+		}
+
+		if err := enqueueEvent(tx, s.tenant, loanEventsTopic, "loan_returned", loan); err != nil {
+			return err
+		}
+		if bookCopy.Status != models.CopyOnLoan {
+			return nil
+		}
+		return s.shelve(tx, bookCopy, time.Now())
+	})
+	if err != nil {
+		return nil, err
+	}
+	return loan, nil
+}
+
+// GetHolds lists holds in queue order.
+func (s *CirculationService) GetHolds(filter models.HoldFilter, limit, offset int) ([]models.Hold, error) {
+	if filter.BookID != 0 {
+		if _, err := s.books.GetByID(filter.BookID); err != nil {
+			return nil, err
+		}
+	}
+	return s.repo.GetHolds(filter, limit, offset)
+}
+
+func (s *CirculationService) GetHold(id uint) (*models.Hold, error) {
+	return s.repo.GetHold(id)
+}
+
+// PlaceHold queues a member for a book none of whose copies is on the
+// shelf and that they don't already have on loan. Every copy of the book stays locked until the hold is committed,
+// so a copy can't come back unnoticed while the hold is being placed.
+func (s *CirculationService) PlaceHold(bookID, memberID uint) (*models.Hold, error) {
+	if memberID == 0 {
+		return nil, fmt.Errorf("%w: member_id is required", ErrInvalidHold)
+	}
+	if _, err := s.books.GetByID(bookID); err != nil {
+		return nil, err
+	}
+
+	hold := &models.Hold{BookID: bookID, MemberID: memberID, Status: models.HoldWaiting}
+	err := s.repo.Transaction(func(tx repositories.CirculationStore) error {
+		member, err := tx.LockMember(memberID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: member %d does not exist", ErrInvalidHold, memberID)
+		} else if err != nil {
+			return err
+		}
+		if member.Blocked {
+			return models.ErrMemberBlocked
+		}
+		if member.Expired(time.Now()) {
+			return models.ErrMembershipExpired
+		}
+		borrowing, err := tx.GetLoans(models.LoanFilter{BookID: bookID, MemberID: memberID, ActiveOnly: true}, 1, 0)
+		if err != nil {
+			return err
+		}
+		if len(borrowing) > 0 {
+			return models.ErrAlreadyBorrowing
+		}
+
+		copies, err := tx.LockCopies(bookID)
+		if err != nil {
+			return err
+		}
+		for _, bookCopy := range copies {
+			if bookCopy.Status == models.CopyAvailable {
+				return models.ErrCopiesAvailable
+			}
+		}
+		return tx.CreateHold(hold)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetHold(hold.ID)
+}
+
+// CancelHold closes an active hold. A copy set aside for it passes to the
+// next member in the queue.
+func (s *CirculationService) CancelHold(id uint) (*models.Hold, error) {
+	err := s.repo.Transaction(func(tx repositories.CirculationStore) error {
+		hold, bookCopy, err := lockHold(tx, id)
+		if err != nil {
+			return err
+		}
+		if !hold.Active() {
+			return models.ErrHoldClosed
+		}
+
+		hold.Status = models.HoldCancelled
+		if err := tx.UpdateHold(hold); err != nil {
+			return err
+		}
+		if bookCopy == nil {
+			return nil
+		}
+		return s.shelve(tx, bookCopy, time.Now())
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetHold(id)
+}
+
+// ExpireHolds closes ready holds whose pickup deadline passed before now
+// and passes their copies on, in every tenant. It reports how many holds
+// expired.
+func (s *CirculationService) ExpireHolds(now time.Time) (int, error) {
+	holds, err := s.repo.GetExpiredHolds(now, holdExpiryBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, candidate := range holds {
+		// Each hold is expired within its own tenant.
+		s := s.ForTenant(candidate.TenantID)
+		err := s.repo.Transaction(func(tx repositories.CirculationStore) error {
+			hold, bookCopy, err := lockHold(tx, candidate.ID)
+			if err != nil {
+				return err
+			}
+			// Collected or cancelled since it was listed.
+			if hold.Status != models.HoldReady || hold.PickupBy == nil || !hold.PickupBy.Before(now) {
+				return nil
+			}
+
+			hold.Status = models.HoldExpired
+			if err := tx.UpdateHold(hold); err != nil {
+				return err
+			}
+			if err := enqueueEvent(tx, s.tenant, holdEventsTopic, "hold_expired", hold); err != nil {
+				return err
+			}
+			expired++
+			return s.shelve(tx, bookCopy, now)
+		})
+		if err != nil {
+			return expired, err
+		}
+	}
+	return expired, nil
+}
+
+// shelve puts a copy that is free to lend back into circulation. If its
+// book has waiting holds, the copy is set aside for the first of them and
+// hold_ready is published; otherwise it becomes available. The copy must
+// be locked by tx.
+func (s *CirculationService) shelve(tx repositories.CirculationStore, bookCopy *models.Copy, now time.Time) error {
+	hold, err := tx.NextHold(bookCopy.BookID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		if bookCopy.Status == models.CopyAvailable {
+			return nil
+		}
+		bookCopy.Status = models.CopyAvailable
+		return tx.UpdateCopy(bookCopy)
+	} else if err != nil {
+		return err
+	}
+
+	copyID := bookCopy.ID
+	pickupBy := now.Add(s.pickupWindow)
+	hold.Status = models.HoldReady
+	hold.CopyID = &copyID
+	hold.ReadyAt = &now
+	hold.PickupBy = &pickupBy
+	if err := tx.UpdateHold(hold); err != nil {
+		return err
+	}
+
+	bookCopy.Status = models.CopyOnHold
+	if err := tx.UpdateCopy(bookCopy); err != nil {
+		return err
+	}
+	return enqueueEvent(tx, s.tenant, holdEventsTopic, "hold_ready", hold)
+}
+
+// lockHold locks a hold and, if it is ready, the copy set aside for it.
+// The copy is locked first, in the same order as Return and Checkout.
+func lockHold(tx repositories.CirculationStore, id uint) (*models.Hold, *models.Copy, error) {
+	hold, err := tx.GetHold(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	var bookCopy *models.Copy
+	if hold.Status == models.HoldReady && hold.CopyID != nil {
+		if bookCopy, err = tx.LockCopy(*hold.CopyID); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if hold, err = tx.LockHold(id); err != nil {
+		return nil, nil, err
+	}
+	// The hold became ready between the two reads; its copy is locked
+	// out of order, which at worst makes Postgres abort one side.
+	if hold.Status == models.HoldReady && bookCopy == nil && hold.CopyID != nil {
+		if bookCopy, err = tx.LockCopy(*hold.CopyID); err != nil {
+			return nil, nil, err
+		}
+	}
+	return hold, bookCopy, nil
+}
+
+// fulfilHold lets member check out a copy set aside for their hold and
+// closes the hold.
+func fulfilHold(tx repositories.CirculationStore, bookCopy *models.Copy, member *models.Member) error {
+	hold, err := tx.GetReadyHold(bookCopy.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.ErrCopyOnHold
+	} else if err != nil {
+		return err
+	}
+	if hold.MemberID != member.ID {
+		return models.ErrCopyOnHold
+	}
+	hold.Status = models.HoldFulfilled
+	return tx.UpdateHold(hold)
+}
+
+func (s *CirculationService) resolveCopy(request models.CheckoutRequest) (*models.Copy, error) {
+	barcode := strings.TrimSpace(request.Barcode)
+	switch {
+	case request.CopyID != 0 && barcode != "":
+		return nil, fmt.Errorf("%w: give copy_id or barcode, not both", ErrInvalidLoan)
+	case request.CopyID != 0:
+		return s.repo.GetCopy(request.CopyID)
+	case barcode != "":
+		return s.repo.GetCopyByBarcode(barcode)
+	default:
+		return nil, fmt.Errorf("%w: copy_id or barcode is required", ErrInvalidLoan)
+	}
+}
+
+var (
+	// ErrInvalidCopy, ErrInvalidLoan and ErrInvalidHold wrap validation
+	// failures, like ErrInvalidBook.
+	ErrInvalidCopy = errors.New("invalid copy")
+	ErrInvalidLoan = errors.New("invalid loan")
+	ErrInvalidHold = errors.New("invalid hold")
+)
+
+// validateCopy checks a copy as staff may set it: only the shelf statuses
+// are allowed, and an empty status means available.
+func validateCopy(bookCopy *models.Copy) error {
+	bookCopy.Barcode = strings.TrimSpace(bookCopy.Barcode)
+	if bookCopy.Barcode == "" {
+		return fmt.Errorf("%w: barcode is required", ErrInvalidCopy)
+	}
+	switch bookCopy.Status {
+	case "":
+		bookCopy.Status = models.CopyAvailable
+	case models.CopyAvailable, models.CopyMaintenance, models.CopyLost:
+	default:
+		return fmt.Errorf("%w: status must be one of available, maintenance, lost", ErrInvalidCopy)
+	}
+	return nil
+}
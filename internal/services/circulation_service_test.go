@@ -0,0 +1,133 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shani34/book-management-system/internal/models"
+	"github.com/shani34/book-management-system/internal/repositories"
+	"github.com/shani34/book-management-system/pkg/cache"
+	"gorm.io/gorm"
+)
+
+func TestTenantCirculationIsIsolated(t *testing.T) {
+	db := repositories.NewMemoryDB()
+	books := repositories.NewMemoryBookRepository(db)
+	circulation := NewCirculationService(repositories.NewMemoryCirculationRepository(db), books, 48*time.Hour)
+	members := NewMemberService(repositories.NewMemoryMemberRepository(db))
+	fines := NewFineService(repositories.NewMemoryCirculationRepository(db))
+
+	book := &models.Book{Title: "Dune", Author: "Frank Herbert"}
+	if err := NewBookService(books, cache.Noop{}).ForTenant("a").CreateBook(book); err != nil {
+		t.Fatalf("CreateBook: %v", err)
+	}
+	member := &models.Member{Name: "Ann", Email: "ann@example.com", TierID: 1}
+	if err := members.ForTenant("a").CreateMember(member); err != nil {
+		t.Fatalf("CreateMember: %v", err)
+	}
+	bookCopy := &models.Copy{Barcode: "LIB-1"}
+	if err := circulation.ForTenant("a").CreateCopy(book.ID, bookCopy); err != nil {
+		t.Fatalf("CreateCopy: %v", err)
+	}
+	loan, err := circulation.ForTenant("a").Checkout(models.CheckoutRequest{CopyID: bookCopy.ID, MemberID: member.ID})
+	if err != nil {
+		t.Fatalf("Checkout: %v", err)
+	}
+
+	b := circulation.ForTenant("b")
+	if _, err := b.GetCopy(bookCopy.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("GetCopy from b: got %v, want not found", err)
+	}
+	if _, err := b.GetLoan(loan.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("GetLoan from b: got %v, want not found", err)
+	}
+	if loans, err := b.GetLoans(models.LoanFilter{}, 10, 0); err != nil || len(loans) != 0 {
+		t.Errorf("GetLoans from b: got %d loans, %v; want none", len(loans), err)
+	}
+	if _, err := b.Return(loan.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Return from b: got %v, want not found", err)
+	}
+	if _, err := b.PlaceHold(book.ID, member.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("PlaceHold from b: got %v, want not found", err)
+	}
+	if _, err := members.ForTenant("b").GetMemberByID(member.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("GetMemberByID from b: got %v, want not found", err)
+	}
+	if _, err := fines.ForTenant("b").GetFineSummary(member.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("GetFineSummary from b: got %v, want not found", err)
+	}
+
+	// Each tenant has its own tiers, seeded with the defaults.
+	if _, err := members.ForTenant("b").GetTier(member.TierID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("GetTier of a's tier from b: got %v, want not found", err)
+	}
+	if err := members.ForTenant("b").UpdateTier(member.TierID, &models.Tier{Name: "free", MaxLoans: 1, LoanDays: 1}); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("UpdateTier of a's tier from b: got %v, want not found", err)
+	}
+	tiers, err := members.ForTenant("b").GetTiers()
+	if err != nil || len(tiers) != len(models.DefaultTiers()) || tiers[0].TenantID != "b" || tiers[0].ID == member.TierID {
+		t.Fatalf("GetTiers from b: got %+v, %v; want b's own default tiers", tiers, err)
+	}
+	if err := members.ForTenant("b").CreateMember(&models.Member{Name: "Bob", Email: "bob@example.com", TierID: member.TierID}); !errors.Is(err, ErrInvalidMember) {
+		t.Errorf("CreateMember in a's tier from b: got %v, want ErrInvalidMember", err)
+	}
+
+	// Barcodes, emails and tier names are unique per tenant only.
+	other := &models.Member{Name: "Ann", Email: "ann@example.com", TierID: tiers[0].ID}
+	if err := members.ForTenant("b").CreateMember(other); err != nil {
+		t.Errorf("CreateMember with a's email in b: %v", err)
+	}
+	if got, err := members.ForTenant("a").GetTier(member.TierID); err != nil || got.Name != tiers[0].Name {
+		t.Errorf("a's tier after b's writes: got %+v, %v", got, err)
+	}
+	bBook := &models.Book{Title: "Dune", Author: "Frank Herbert"}
+	if err := NewBookService(books, cache.Noop{}).ForTenant("b").CreateBook(bBook); err != nil {
+		t.Fatalf("CreateBook: %v", err)
+	}
+	if err := b.CreateCopy(bBook.ID, &models.Copy{Barcode: "LIB-1"}); err != nil {
+		t.Errorf("CreateCopy with a's barcode in b: %v", err)
+	}
+	if err := b.CreateCopy(book.ID, &models.Copy{Barcode: "LIB-2"}); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("CreateCopy of a's book from b: got %v, want not found", err)
+	}
+}
+
+func TestCheckoutDueAtWithinLoanPeriod(t *testing.T) {
+	db := repositories.NewMemoryDB()
+	books := repositories.NewMemoryBookRepository(db)
+	circulation := NewCirculationService(repositories.NewMemoryCirculationRepository(db), books, 48*time.Hour).ForTenant("a")
+
+	book := &models.Book{Title: "Dune", Author: "Frank Herbert"}
+	if err := NewBookService(books, cache.Noop{}).ForTenant("a").CreateBook(book); err != nil {
+		t.Fatalf("CreateBook: %v", err)
+	}
+	// The standard tier lends for 14 days.
+	member := &models.Member{Name: "Ann", Email: "ann@example.com", TierID: 1}
+	if err := NewMemberService(repositories.NewMemoryMemberRepository(db)).ForTenant("a").CreateMember(member); err != nil {
+		t.Fatalf("CreateMember: %v", err)
+	}
+	bookCopy := &models.Copy{Barcode: "LIB-1"}
+	if err := circulation.CreateCopy(book.ID, bookCopy); err != nil {
+		t.Fatalf("CreateCopy: %v", err)
+	}
+
+	tooLate := time.Now().Add(15 * 24 * time.Hour)
+	request := models.CheckoutRequest{CopyID: bookCopy.ID, MemberID: member.ID, DueAt: &tooLate}
+	if _, err := circulation.Checkout(request); !errors.Is(err, ErrInvalidLoan) {
+		t.Fatalf("Checkout due past the loan period: got %v, want ErrInvalidLoan", err)
+	}
+	if got, err := circulation.GetCopy(bookCopy.ID); err != nil || got.Status != models.CopyAvailable {
+		t.Fatalf("copy after refused checkout: got %+v, %v; want it available", got, err)
+	}
+
+	sooner := time.Now().Add(7 * 24 * time.Hour)
+	request.DueAt = &sooner
+	loan, err := circulation.Checkout(request)
+	if err != nil {
+		t.Fatalf("Checkout due within the loan period: %v", err)
+	}
+	if !loan.DueAt.Equal(sooner) {
+		t.Errorf("due at %v, want %v", loan.DueAt, sooner)
+	}
+}
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/shani34/book-management-system/internal/models"
+	"github.com/shani34/book-management-system/internal/repositories"
+	"github.com/shani34/book-management-system/pkg/cache"
+	"github.com/shani34/book-management-system/pkg/isbn"
+)
+
+// EditionService manages the editions of a book. Single-book reads embed
+// the editions, so every change bumps the parent's version, publishes
+// book_updated for it and drops its cache entries.
+type EditionService struct {
+	repo   repositories.EditionStore
+	books  repositories.BookStore
+	cache  cache.Cache
+	tenant string
+}
+
+func NewEditionService(repo repositories.EditionStore, books repositories.BookStore, cache cache.Cache) *EditionService {
+	return &EditionService{
+		repo:  repo,
+		books: books,
+		cache: cache,
+	}
+}
+
+// ForTenant returns the service for tenantID: only that tenant's books,
+// editions and cache entries can be reached through it.
+func (s *EditionService) ForTenant(tenantID string) *EditionService {
+	return &EditionService{
+		repo:   s.repo.ForTenant(tenantID),
+		books:  s.books.ForTenant(tenantID),
+		cache:  cache.ForTenant(s.cache, tenantID),
+		tenant: tenantID,
+	}
+}
+
+// GetEditions lists the editions of a live book. A book in the trash is
+// reported as missing, like everywhere else.
+func (s *EditionService) GetEditions(bookID uint) ([]models.Edition, error) {
+	if _, err := s.books.GetByID(bookID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByBook(bookID)
+}
+
+func (s *EditionService) GetEdition(bookID, id uint) (*models.Edition, error) {
+	if _, err := s.books.GetByID(bookID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(bookID, id)
+}
+
+func (s *EditionService) CreateEdition(bookID uint, edition *models.Edition) error {
+	return s.changeEditions(bookID, func(editions repositories.EditionStore) error {
+		if err := validateEdition(edition); err != nil {
+			return err
+		}
+		edition.ID = 0
+		edition.BookID = bookID
+		return editions.Create(edition)
+	})
+}
+
+func (s *EditionService) UpdateEdition(bookID, id uint, edition *models.Edition) error {
+	return s.changeEditions(bookID, func(editions repositories.EditionStore) error {
+		existing, err := editions.GetByID(bookID, id)
+		if err != nil {
+			return err
+		}
+		if err := validateEdition(edition); err != nil {
+			return err
+		}
+		edition.ID = existing.ID
+		edition.BookID = existing.BookID
+		edition.CreatedAt = existing.CreatedAt
+		return editions.Update(edition)
+	})
+}
+
+func (s *EditionService) DeleteEdition(bookID, id uint) error {
+	return s.changeEditions(bookID, func(editions repositories.EditionStore) error {
+		return editions.Delete(bookID, id)
+	})
+}
+
+// changeEditions runs change against the editions store inside a
+// transaction that also touches the live book with bookID, so the edition
+// change, the new book version and book_updated commit together.
+func (s *EditionService) changeEditions(bookID uint, change func(editions repositories.EditionStore) error) error {
+	err := s.books.Transaction(func(tx repositories.BookStore) error {
+		if _, err := tx.GetByID(bookID); err != nil {
+			return err
+		}
+		if err := change(tx.Editions()); err != nil {
+			return err
+		}
+		return touchBook(tx, s.tenant, bookID)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.cache.Delete(fmt.Sprintf("book:%d", bookID))
+	s.cache.DeletePrefix("books:")
+	return nil
+}
+
+// ErrInvalidEdition wraps edition validation failures.
+var ErrInvalidEdition = errors.New("invalid edition")
+
+func validateEdition(edition *models.Edition) error {
+	edition.Publisher = strings.TrimSpace(edition.Publisher)
+	edition.Language = strings.TrimSpace(edition.Language)
+	edition.Format = strings.ToLower(strings.TrimSpace(edition.Format))
+	if !models.EditionFormats[edition.Format] {
+		return fmt.Errorf("%w: format must be one of hardcover, paperback, ebook, audiobook", ErrInvalidEdition)
+	}
+
+	if edition.ISBN != "" {
+		normalized, err := isbn.Parse(edition.ISBN)
+		if err != nil {
+			return fmt.Errorf("%w: isbn: %v", ErrInvalidEdition, err)
+		}
+		if len(normalized) == 10 {
+			normalized = isbn.To13(normalized)
+		}
+		edition.ISBN = normalized
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shani34/book-management-system/internal/models"
+	"github.com/shani34/book-management-system/internal/repositories"
+	"github.com/shani34/book-management-system/pkg/cache"
+)
+
+func TestEditionChangesTouchTheBook(t *testing.T) {
+	db := repositories.NewMemoryDB()
+	repo := repositories.NewMemoryBookRepository(db)
+	bookCache := cache.NewLRU(100)
+	books := NewBookService(repo, bookCache).ForTenant("a")
+	editions := NewEditionService(repositories.NewMemoryEditionRepository(db), repo, bookCache).ForTenant("a")
+
+	book := &models.Book{Title: "Dune", Author: "Frank Herbert"}
+	if err := books.CreateBook(book); err != nil {
+		t.Fatalf("CreateBook: %v", err)
+	}
+	relayBookEvents(t, repo)
+	// Warm the cache so a stale entry would show.
+	if _, err := books.GetBookByID(book.ID); err != nil {
+		t.Fatalf("GetBookByID: %v", err)
+	}
+
+	edition := &models.Edition{Format: "paperback", Publisher: "Ace"}
+	if err := editions.CreateEdition(book.ID, edition); err != nil {
+		t.Fatalf("CreateEdition: %v", err)
+	}
+	assertTouched(t, books, repo, book.ID, 2, func(got *models.Book) bool {
+		return len(got.Editions) == 1 && got.Editions[0].Publisher == "Ace"
+	})
+
+	update := &models.Edition{Format: "hardcover", Publisher: "Chilton"}
+	if err := editions.UpdateEdition(book.ID, edition.ID, update); err != nil {
+		t.Fatalf("UpdateEdition: %v", err)
+	}
+	assertTouched(t, books, repo, book.ID, 3, func(got *models.Book) bool {
+		return len(got.Editions) == 1 && got.Editions[0].Publisher == "Chilton"
+	})
+
+	if err := editions.DeleteEdition(book.ID, edition.ID); err != nil {
+		t.Fatalf("DeleteEdition: %v", err)
+	}
+	assertTouched(t, books, repo, book.ID, 4, func(got *models.Book) bool {
+		return len(got.Editions) == 0
+	})
+
+	// A refused change leaves the book alone.
+	if err := editions.CreateEdition(book.ID, &models.Edition{Format: "scroll"}); !errors.Is(err, ErrInvalidEdition) {
+		t.Fatalf("CreateEdition with a bad format: got %v, want ErrInvalidEdition", err)
+	}
+	if got, err := books.GetBookByID(book.ID); err != nil || got.Version != 4 {
+		t.Errorf("book after refused edition: got %+v, %v; want version 4", got, err)
+	}
+	if relayed := relayBookEvents(t, repo); len(relayed) != 0 {
+		t.Errorf("refused edition published %d events", len(relayed))
+	}
+}
+
+func TestEditionISBNsAreUniquePerTenant(t *testing.T) {
+	db := repositories.NewMemoryDB()
+	repo := repositories.NewMemoryBookRepository(db)
+	books := NewBookService(repo, cache.Noop{})
+	editions := NewEditionService(repositories.NewMemoryEditionRepository(db), repo, cache.Noop{})
+
+	created := map[string]*models.Edition{}
+	for _, tenant := range []string{"a", "b"} {
+		book := &models.Book{Title: "Refactoring", Author: "Martin Fowler"}
+		if err := books.ForTenant(tenant).CreateBook(book); err != nil {
+			t.Fatalf("CreateBook: %v", err)
+		}
+		edition := &models.Edition{Format: "hardcover", ISBN: "978-0-13-475759-9"}
+		if err := editions.ForTenant(tenant).CreateEdition(book.ID, edition); err != nil {
+			t.Fatalf("CreateEdition in %s: %v", tenant, err)
+		}
+		if edition.TenantID != tenant {
+			t.Errorf("edition of %s's book: got tenant %q", tenant, edition.TenantID)
+		}
+		created[tenant] = edition
+	}
+
+	a := editions.ForTenant("a")
+	second := &models.Edition{Format: "ebook", ISBN: "9780134757599"}
+	if err := a.CreateEdition(created["a"].BookID, second); !errors.Is(err, models.ErrDuplicateISBN) {
+		t.Errorf("second edition with the ISBN in a: got %v, want ErrDuplicateISBN", err)
+	}
+	if _, err := a.GetEdition(created["b"].BookID, created["b"].ID); err == nil {
+		t.Error("a read b's edition")
+	}
+}
+
+// assertTouched checks that the book with id is at version, both fresh
+// and through the cache, and that exactly one book_updated went out for it
+// carrying that version. ok checks the book as read and as published.
+func assertTouched(t *testing.T, books *BookService, repo repositories.BookStore, id, version uint, ok func(*models.Book) bool) {
+	t.Helper()
+	got, err := books.GetBookByID(id)
+	if err != nil {
+		t.Fatalf("GetBookByID: %v", err)
+	}
+	if got.Version != version || !ok(got) {
+		t.Errorf("book: got version %d, %+v; want version %d", got.Version, got, version)
+	}
+
+	relayed := relayBookEvents(t, repo)
+	if len(relayed) != 1 {
+		t.Fatalf("got %d book events, want 1 book_updated", len(relayed))
+	}
+	event := relayed[0]
+	if event.EventType != "book_updated" || event.Payload.ID != id || event.Payload.Version != version || !ok(&event.Payload) {
+		t.Errorf("event: got %s of book %d at version %d, %+v; want book_updated at version %d",
+			event.EventType, event.Payload.ID, event.Payload.Version, event.Payload, version)
+	}
+}
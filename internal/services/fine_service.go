@@ -0,0 +1,185 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shani34/book-management-system/internal/models"
+	"github.com/shani34/book-management-system/internal/repositories"
+)
+
+// FineService reports, collects and waives overdue fines. Fines are
+// assessed by CirculationService when a late loan is returned.
+type FineService struct {
+	repo repositories.CirculationStore
+}
+
+func NewFineService(repo repositories.CirculationStore) *FineService {
+	return &FineService{repo: repo}
+}
+
+// ForTenant returns the service for tenantID's members and fines.
+func (s *FineService) ForTenant(tenantID string) *FineService {
+	return &FineService{repo: s.repo.ForTenant(tenantID)}
+}
+
+func (s *FineService) GetMemberFines(memberID uint, openOnly bool, limit, offset int) ([]models.Fine, error) {
+	if _, err := s.repo.GetMember(memberID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetFines(models.FineFilter{MemberID: memberID, OpenOnly: openOnly}, limit, offset)
+}
+
+// GetFineSummary reports what the member owes now, and whether that stops
+// them borrowing.
+func (s *FineService) GetFineSummary(memberID uint) (*models.FineSummary, error) {
+	member, err := s.repo.GetMember(memberID)
+	if err != nil {
+		return nil, err
+	}
+	return summarizeFines(s.repo, member, time.Now())
+}
+
+func (s *FineService) GetLedger(memberID uint, limit, offset int) ([]models.LedgerEntry, error) {
+	if _, err := s.repo.GetMember(memberID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetLedger(memberID, limit, offset)
+}
+
+func (s *FineService) GetFine(id uint) (*models.Fine, error) {
+	return s.repo.GetFine(id)
+}
+
+// PayFine records a payment of amount towards the fine. Partial payments
+// are allowed; paying more than the balance is not.
+func (s *FineService) PayFine(id uint, amount int64, note string) (*models.Fine, error) {
+	if amount <= 0 {
+		return nil, fmt.Errorf("%w: amount must be positive", ErrInvalidAmount)
+	}
+	return s.settle(id, models.LedgerPayment, amount, note)
+}
+
+// WaiveFine writes off amount of the fine, or all of what is left when
+// amount is 0.
+func (s *FineService) WaiveFine(id uint, amount int64, note string) (*models.Fine, error) {
+	if amount < 0 {
+		return nil, fmt.Errorf("%w: amount must not be negative", ErrInvalidAmount)
+	}
+	return s.settle(id, models.LedgerWaiver, amount, note)
+}
+
+// settle reduces the fine's balance by amount and records it in the
+// ledger, in one transaction with the fine locked.
+func (s *FineService) settle(id uint, kind string, amount int64, note string) (*models.Fine, error) {
+	var fine *models.Fine
+	err := s.repo.Transaction(func(tx repositories.CirculationStore) error {
+		var err error
+		if fine, err = tx.LockFine(id); err != nil {
+			return err
+		}
+		if fine.Balance == 0 {
+			return models.ErrFineSettled
+		}
+		if amount == 0 {
+			amount = fine.Balance
+		}
+		if amount > fine.Balance {
+			return fmt.Errorf("%w: amount exceeds the balance of %d", ErrInvalidAmount, fine.Balance)
+		}
+
+		if kind == models.LedgerPayment {
+			fine.Paid += amount
+		} else {
+			fine.Waived += amount
+		}
+		fine.Balance -= amount
+		if err := tx.UpdateFine(fine); err != nil {
+			return err
+		}
+		return tx.AddLedgerEntry(&models.LedgerEntry{
+			FineID:       fine.ID,
+			MemberID:     fine.MemberID,
+			Kind:         kind,
+			Amount:       -amount,
+			BalanceAfter: fine.Balance,
+			Note:         strings.TrimSpace(note),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fine, nil
+}
+
+// ErrInvalidAmount wraps rejected payment and waiver amounts.
+var ErrInvalidAmount = errors.New("invalid amount")
+
+// assessFine charges the member for a loan returned late, using the fine
+// policy of their tier at the time of return.
+func assessFine(tx repositories.CirculationStore, loan *models.Loan) error {
+	if loan.MemberID == 0 || loan.ReturnedAt == nil {
+		return nil
+	}
+	member, err := tx.GetMember(loan.MemberID)
+	if err != nil {
+		return err
+	}
+	if member.Tier == nil {
+		return fmt.Errorf("member %d has no tier", member.ID)
+	}
+	daysLate, amount := member.Tier.Fine(loan.DueAt, *loan.ReturnedAt)
+	if amount == 0 {
+		return nil
+	}
+
+	fine := &models.Fine{
+		LoanID:   loan.ID,
+		MemberID: member.ID,
+		DaysLate: daysLate,
+		Amount:   amount,
+		Balance:  amount,
+	}
+	if err := tx.CreateFine(fine); err != nil {
+		return err
+	}
+	return tx.AddLedgerEntry(&models.LedgerEntry{
+		FineID:       fine.ID,
+		MemberID:     member.ID,
+		Kind:         models.LedgerAssessed,
+		Amount:       amount,
+		BalanceAfter: fine.Balance,
+		Note:         fmt.Sprintf("late return of loan %d", loan.ID),
+	})
+}
+
+// summarizeFines adds the member's assessed balance to what is accruing
+// on their overdue loans and compares it to the threshold of their tier.
+func summarizeFines(store repositories.CirculationStore, member *models.Member, now time.Time) (*models.FineSummary, error) {
+	if member.Tier == nil {
+		return nil, fmt.Errorf("member %d has no tier", member.ID)
+	}
+	outstanding, err := store.GetFineBalance(member.ID)
+	if err != nil {
+		return nil, err
+	}
+	loans, err := store.GetLoans(models.LoanFilter{MemberID: member.ID, ActiveOnly: true}, -1, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &models.FineSummary{
+		MemberID:    member.ID,
+		Outstanding: outstanding,
+		Threshold:   member.Tier.FineThreshold,
+	}
+	for _, loan := range loans {
+		_, amount := member.Tier.Fine(loan.DueAt, now)
+		summary.Accruing += amount
+	}
+	summary.Total = summary.Outstanding + summary.Accruing
+	summary.Blocked = summary.Total > summary.Threshold
+	return summary, nil
+}
@@ -0,0 +1,254 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shani34/book-management-system/internal/models"
+)
+
+// fineTier charges 25 a day after one day of grace, up to 1000, and stops
+// members owing more than 500 from borrowing.
+var fineTier = models.Tier{Name: "lending", MaxLoans: 5, LoanDays: 14, FinePerDay: 25, FineGraceDays: 1, FineCap: 1000, FineThreshold: 500}
+
+// newFines is newLending with fineTier as the members' tier.
+func newFines(t *testing.T) (*lending, *FineService) {
+	t.Helper()
+	l := newLending(t)
+	tier := fineTier
+	if err := l.members.UpdateTier(l.jane.TierID, &tier); err != nil {
+		t.Fatal(err)
+	}
+	return l, NewFineService(l.repo).ForTenant("t")
+}
+
+// overdueLoan lends l's copy to member on a loan that fell due overdue
+// ago. Checkout refuses due dates in the past, so it goes to the store.
+func (l *lending) overdueLoan(t *testing.T, member *models.Member, overdue time.Duration) *models.Loan {
+	t.Helper()
+	now := time.Now()
+	loan := &models.Loan{
+		CopyID:       l.copy.ID,
+		BookID:       l.book.ID,
+		MemberID:     member.ID,
+		CheckedOutAt: now.Add(-testLoanPeriod - overdue),
+		DueAt:        now.Add(-overdue),
+	}
+	if err := l.repo.ForTenant("t").CreateLoan(loan); err != nil {
+		t.Fatal(err)
+	}
+	return loan
+}
+
+// assessedFine brings back a loan of Jane's six days late and returns the
+// fine of 125 assessed for it.
+func (l *lending) assessedFine(t *testing.T, fines *FineService) *models.Fine {
+	t.Helper()
+	loan := l.overdueLoan(t, l.jane, 5*24*time.Hour+time.Hour)
+	if _, err := l.circulation.Return(loan.ID); err != nil {
+		t.Fatal(err)
+	}
+	assessed, err := fines.GetMemberFines(l.jane.ID, true, 10, 0)
+	if err != nil || len(assessed) != 1 {
+		t.Fatalf("fines after late return: got %+v, %v; want one", assessed, err)
+	}
+	return &assessed[0]
+}
+
+// ledgerEntry is the part of a models.LedgerEntry the tests compare.
+type ledgerEntry struct {
+	kind                 string
+	amount, balanceAfter int64
+}
+
+func assertLedger(t *testing.T, fines *FineService, memberID uint, want ...ledgerEntry) {
+	t.Helper()
+	entries, err := fines.GetLedger(memberID, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make([]ledgerEntry, len(entries))
+	for i, entry := range entries {
+		got[i] = ledgerEntry{entry.Kind, entry.Amount, entry.BalanceAfter}
+	}
+	if len(got) != len(want) {
+		t.Fatalf("ledger: got %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("ledger entry %d: got %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestFineAccruesAndIsAssessedOnReturn(t *testing.T) {
+	l, fines := newFines(t)
+	loan := l.overdueLoan(t, l.jane, 5*24*time.Hour+time.Hour)
+
+	// Six started days late, less one day of grace.
+	summary, err := fines.GetFineSummary(l.jane.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (models.FineSummary{MemberID: l.jane.ID, Accruing: 125, Total: 125, Threshold: 500}); *summary != want {
+		t.Errorf("summary while overdue: got %+v, want %+v", *summary, want)
+	}
+	assertLedger(t, fines, l.jane.ID)
+
+	if _, err := l.circulation.Return(loan.ID); err != nil {
+		t.Fatal(err)
+	}
+	assessed, err := fines.GetMemberFines(l.jane.ID, false, 10, 0)
+	if err != nil || len(assessed) != 1 {
+		t.Fatalf("fines after return: got %+v, %v; want one", assessed, err)
+	}
+	fine := assessed[0]
+	if fine.LoanID != loan.ID || fine.DaysLate != 6 || fine.Amount != 125 || fine.Balance != 125 || fine.Paid != 0 || fine.Waived != 0 {
+		t.Errorf("assessed fine: got %+v", fine)
+	}
+	assertLedger(t, fines, l.jane.ID, ledgerEntry{models.LedgerAssessed, 125, 125})
+
+	summary, err = fines.GetFineSummary(l.jane.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (models.FineSummary{MemberID: l.jane.ID, Outstanding: 125, Total: 125, Threshold: 500}); *summary != want {
+		t.Errorf("summary after return: got %+v, want %+v", *summary, want)
+	}
+}
+
+func TestNoFineWithinGrace(t *testing.T) {
+	l, fines := newFines(t)
+	loan := l.overdueLoan(t, l.jane, time.Hour)
+	if _, err := l.circulation.Return(loan.ID); err != nil {
+		t.Fatal(err)
+	}
+	if assessed, err := fines.GetMemberFines(l.jane.ID, false, 10, 0); err != nil || len(assessed) != 0 {
+		t.Errorf("fines after a return within grace: got %+v, %v; want none", assessed, err)
+	}
+	assertLedger(t, fines, l.jane.ID)
+}
+
+func TestAccruingFinesBlockCheckout(t *testing.T) {
+	l, fines := newFines(t)
+	// 60 days late is capped at 1000, over the threshold of 500.
+	l.overdueLoan(t, l.jane, 60*24*time.Hour)
+
+	summary, err := fines.GetFineSummary(l.jane.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Accruing != 1000 || !summary.Blocked {
+		t.Errorf("summary: got %+v, want 1000 accruing and blocked", *summary)
+	}
+	other := &models.Copy{Barcode: "LIB-2"}
+	if err := l.circulation.CreateCopy(l.book.ID, other); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.circulation.Checkout(models.CheckoutRequest{CopyID: other.ID, MemberID: l.jane.ID}); !errors.Is(err, models.ErrFinesOutstanding) {
+		t.Errorf("checkout: got %v, want ErrFinesOutstanding", err)
+	}
+}
+
+func TestSettlingFines(t *testing.T) {
+	tests := []struct {
+		name   string
+		settle func(fines *FineService, id uint) (*models.Fine, error)
+		err    error
+		paid   int64
+		waived int64
+		ledger []ledgerEntry
+	}{
+		{
+			name:   "partial payment",
+			settle: func(fines *FineService, id uint) (*models.Fine, error) { return fines.PayFine(id, 50, " desk ") },
+			paid:   50,
+			ledger: []ledgerEntry{{models.LedgerPayment, -50, 75}},
+		},
+		{
+			name:   "full payment",
+			settle: func(fines *FineService, id uint) (*models.Fine, error) { return fines.PayFine(id, 125, "") },
+			paid:   125,
+			ledger: []ledgerEntry{{models.LedgerPayment, -125, 0}},
+		},
+		{
+			name:   "overpayment",
+			settle: func(fines *FineService, id uint) (*models.Fine, error) { return fines.PayFine(id, 126, "") },
+			err:    ErrInvalidAmount,
+		},
+		{
+			name:   "payment of nothing",
+			settle: func(fines *FineService, id uint) (*models.Fine, error) { return fines.PayFine(id, 0, "") },
+			err:    ErrInvalidAmount,
+		},
+		{
+			name:   "partial waiver",
+			settle: func(fines *FineService, id uint) (*models.Fine, error) { return fines.WaiveFine(id, 25, "") },
+			waived: 25,
+			ledger: []ledgerEntry{{models.LedgerWaiver, -25, 100}},
+		},
+		{
+			name:   "waiver of the rest",
+			settle: func(fines *FineService, id uint) (*models.Fine, error) { return fines.WaiveFine(id, 0, "") },
+			waived: 125,
+			ledger: []ledgerEntry{{models.LedgerWaiver, -125, 0}},
+		},
+		{
+			name:   "waiver over the balance",
+			settle: func(fines *FineService, id uint) (*models.Fine, error) { return fines.WaiveFine(id, 200, "") },
+			err:    ErrInvalidAmount,
+		},
+		{
+			name:   "negative waiver",
+			settle: func(fines *FineService, id uint) (*models.Fine, error) { return fines.WaiveFine(id, -1, "") },
+			err:    ErrInvalidAmount,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, fines := newFines(t)
+			fine := l.assessedFine(t, fines)
+
+			settled, err := tt.settle(fines, fine.ID)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("got %v, want %v", err, tt.err)
+			}
+			if err == nil && (settled.Paid != tt.paid || settled.Waived != tt.waived || settled.Balance != 125-tt.paid-tt.waived) {
+				t.Errorf("settled fine: got %+v, want %d paid and %d waived", settled, tt.paid, tt.waived)
+			}
+			stored, err := fines.GetFine(fine.ID)
+			if err != nil || stored.Paid != tt.paid || stored.Waived != tt.waived || stored.Balance != 125-tt.paid-tt.waived {
+				t.Errorf("stored fine: got %+v, %v; want %d paid and %d waived", stored, err, tt.paid, tt.waived)
+			}
+			assertLedger(t, fines, l.jane.ID, append([]ledgerEntry{{models.LedgerAssessed, 125, 125}}, tt.ledger...)...)
+		})
+	}
+}
+
+func TestSettledFineRefusesMore(t *testing.T) {
+	l, fines := newFines(t)
+	fine := l.assessedFine(t, fines)
+
+	if _, err := fines.PayFine(fine.ID, 100, ""); err != nil {
+		t.Fatal(err)
+	}
+	settled, err := fines.WaiveFine(fine.ID, 0, "")
+	if err != nil || settled.Balance != 0 || settled.Paid != 100 || settled.Waived != 25 {
+		t.Fatalf("waiving the rest: got %+v, %v", settled, err)
+	}
+	if _, err := fines.PayFine(fine.ID, 1, ""); !errors.Is(err, models.ErrFineSettled) {
+		t.Errorf("paying a settled fine: got %v, want ErrFineSettled", err)
+	}
+	if _, err := fines.WaiveFine(fine.ID, 0, ""); !errors.Is(err, models.ErrFineSettled) {
+		t.Errorf("waiving a settled fine: got %v, want ErrFineSettled", err)
+	}
+	assertLedger(t, fines, l.jane.ID,
+		ledgerEntry{models.LedgerAssessed, 125, 125},
+		ledgerEntry{models.LedgerPayment, -100, 25},
+		ledgerEntry{models.LedgerWaiver, -25, 0},
+	)
+	if open, err := fines.GetMemberFines(l.jane.ID, true, 10, 0); err != nil || len(open) != 0 {
+		t.Errorf("open fines: got %+v, %v; want none", open, err)
+	}
+}
@@ -0,0 +1,52 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// HoldExpirer periodically expires holds that were not collected in time,
+// passing their copies on to the next member in the queue.
+type HoldExpirer struct {
+	service  *CirculationService
+	logger   *zap.Logger
+	interval time.Duration
+}
+
+func NewHoldExpirer(service *CirculationService, logger *zap.Logger, interval time.Duration) *HoldExpirer {
+	return &HoldExpirer{
+		service:  service,
+		logger:   logger.Named("services.HoldExpirer"),
+		interval: interval,
+	}
+}
+
+// Run expires overdue holds every interval until ctx is cancelled.
+func (e *HoldExpirer) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		// Drain full batches straight away instead of waiting a tick each.
+		for {
+			n, err := e.service.ExpireHolds(time.Now())
+			if err != nil {
+				e.logger.Error("Failed to expire holds", zap.Error(err))
+			}
+			if n > 0 {
+				e.logger.Info("Expired uncollected holds", zap.Int("count", n))
+			}
+			if err != nil || n < holdExpiryBatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shani34/book-management-system/internal/models"
+)
+
+// queue lends l's only copy to Jane and has Joe and then Ann hold the book.
+func (l *lending) queue(t *testing.T) (loan *models.Loan, joeHold, annHold *models.Hold, ann *models.Member) {
+	t.Helper()
+	loan, err := l.circulation.Checkout(models.CheckoutRequest{CopyID: l.copy.ID, MemberID: l.jane.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ann = &models.Member{Name: "Ann", Email: "ann@example.com", TierID: l.jane.TierID}
+	if err := l.members.CreateMember(ann); err != nil {
+		t.Fatal(err)
+	}
+	if joeHold, err = l.circulation.PlaceHold(l.book.ID, l.joe.ID); err != nil {
+		t.Fatal(err)
+	}
+	if annHold, err = l.circulation.PlaceHold(l.book.ID, ann.ID); err != nil {
+		t.Fatal(err)
+	}
+	return loan, joeHold, annHold, ann
+}
+
+func (l *lending) hold(t *testing.T, id uint) *models.Hold {
+	t.Helper()
+	hold, err := l.circulation.GetHold(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hold
+}
+
+// assertReady checks that hold has l's copy set aside for it until
+// pickupBy.
+func (l *lending) assertReady(t *testing.T, hold *models.Hold, pickupBy time.Time) {
+	t.Helper()
+	if hold.Status != models.HoldReady || hold.CopyID == nil || *hold.CopyID != l.copy.ID || hold.Position != 0 {
+		t.Errorf("hold %d: got %+v, want ready with copy %d", hold.ID, hold, l.copy.ID)
+	}
+	if hold.PickupBy == nil || hold.PickupBy.Before(pickupBy) || hold.PickupBy.After(pickupBy.Add(time.Minute)) {
+		t.Errorf("hold %d: pickup by %v, want %v", hold.ID, hold.PickupBy, pickupBy)
+	}
+	if status := l.copyStatus(t); status != models.CopyOnHold {
+		t.Errorf("copy: got status %q, want on hold", status)
+	}
+}
+
+func TestPlaceHoldRefusals(t *testing.T) {
+	l := newLending(t)
+
+	if _, err := l.circulation.PlaceHold(l.book.ID, l.joe.ID); !errors.Is(err, models.ErrCopiesAvailable) {
+		t.Errorf("hold with the copy on the shelf: got %v, want ErrCopiesAvailable", err)
+	}
+	if _, err := l.circulation.Checkout(models.CheckoutRequest{CopyID: l.copy.ID, MemberID: l.jane.ID}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.circulation.PlaceHold(l.book.ID, l.jane.ID); !errors.Is(err, models.ErrAlreadyBorrowing) {
+		t.Errorf("hold by the borrower: got %v, want ErrAlreadyBorrowing", err)
+	}
+	if _, err := l.circulation.PlaceHold(l.book.ID, l.joe.ID); err != nil {
+		t.Fatalf("hold by another member: %v", err)
+	}
+	if _, err := l.circulation.PlaceHold(l.book.ID, l.joe.ID); !errors.Is(err, models.ErrDuplicateHold) {
+		t.Errorf("second hold: got %v, want ErrDuplicateHold", err)
+	}
+	if _, err := l.circulation.PlaceHold(l.book.ID, 999); !errors.Is(err, ErrInvalidHold) {
+		t.Errorf("hold by an unknown member: got %v, want ErrInvalidHold", err)
+	}
+
+	holds, err := l.circulation.GetHolds(models.HoldFilter{BookID: l.book.ID}, 10, 0)
+	if err != nil || len(holds) != 1 || holds[0].MemberID != l.joe.ID {
+		t.Errorf("holds: got %+v, %v; want only Joe's", holds, err)
+	}
+}
+
+func TestHoldQueueIsServedInOrder(t *testing.T) {
+	l := newLending(t)
+	loan, joeHold, annHold, ann := l.queue(t)
+
+	holds, err := l.circulation.GetHolds(models.HoldFilter{BookID: l.book.ID}, 10, 0)
+	if err != nil || len(holds) != 2 {
+		t.Fatalf("holds: got %+v, %v; want two", holds, err)
+	}
+	for i, want := range []*models.Hold{joeHold, annHold} {
+		if holds[i].ID != want.ID || holds[i].Status != models.HoldWaiting || holds[i].Position != i+1 {
+			t.Errorf("hold %d in the queue: got %+v, want hold %d waiting at position %d", i+1, holds[i], want.ID, i+1)
+		}
+	}
+	if annHold.Position != 2 {
+		t.Errorf("PlaceHold: got position %d, want 2", annHold.Position)
+	}
+
+	// The returned copy goes to the first in line, and the rest move up.
+	returnedAt := time.Now()
+	if _, err := l.circulation.Return(loan.ID); err != nil {
+		t.Fatal(err)
+	}
+	l.assertReady(t, l.hold(t, joeHold.ID), returnedAt.Add(3*24*time.Hour))
+	if hold := l.hold(t, annHold.ID); hold.Status != models.HoldWaiting || hold.Position != 1 {
+		t.Errorf("Ann's hold: got %+v, want waiting at position 1", hold)
+	}
+
+	// Only Joe can take the copy set aside for him.
+	if _, err := l.circulation.Checkout(models.CheckoutRequest{CopyID: l.copy.ID, MemberID: ann.ID}); !errors.Is(err, models.ErrCopyOnHold) {
+		t.Errorf("checkout by Ann: got %v, want ErrCopyOnHold", err)
+	}
+	if _, err := l.circulation.Checkout(models.CheckoutRequest{CopyID: l.copy.ID, MemberID: l.joe.ID}); err != nil {
+		t.Fatalf("checkout by Joe: %v", err)
+	}
+	if hold := l.hold(t, joeHold.ID); hold.Status != models.HoldFulfilled {
+		t.Errorf("Joe's hold after checkout: got %q, want fulfilled", hold.Status)
+	}
+	if status := l.copyStatus(t); status != models.CopyOnLoan {
+		t.Errorf("copy: got status %q, want on loan", status)
+	}
+}
+
+func TestExpiredHoldPassesCopyOn(t *testing.T) {
+	l := newLending(t)
+	loan, joeHold, annHold, _ := l.queue(t)
+	if _, err := l.circulation.Return(loan.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// Nothing is overdue within the pickup window.
+	if n, err := l.circulation.ExpireHolds(time.Now().Add(time.Hour)); err != nil || n != 0 {
+		t.Fatalf("ExpireHolds within the window: got %d, %v; want 0", n, err)
+	}
+
+	later := time.Now().Add(4 * 24 * time.Hour)
+	if n, err := l.circulation.ExpireHolds(later); err != nil || n != 1 {
+		t.Fatalf("ExpireHolds: got %d, %v; want 1", n, err)
+	}
+	if hold := l.hold(t, joeHold.ID); hold.Status != models.HoldExpired {
+		t.Errorf("Joe's hold: got %q, want expired", hold.Status)
+	}
+	l.assertReady(t, l.hold(t, annHold.ID), later.Add(3*24*time.Hour))
+
+	// With nobody left in line the copy goes back on the shelf.
+	if n, err := l.circulation.ExpireHolds(later.Add(4 * 24 * time.Hour)); err != nil || n != 1 {
+		t.Fatalf("second ExpireHolds: got %d, %v; want 1", n, err)
+	}
+	if hold := l.hold(t, annHold.ID); hold.Status != models.HoldExpired {
+		t.Errorf("Ann's hold: got %q, want expired", hold.Status)
+	}
+	if status := l.copyStatus(t); status != models.CopyAvailable {
+		t.Errorf("copy: got status %q, want available", status)
+	}
+}
+
+func TestCancelledReadyHoldPassesCopyOn(t *testing.T) {
+	l := newLending(t)
+	loan, joeHold, annHold, _ := l.queue(t)
+	if _, err := l.circulation.Return(loan.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	cancelledAt := time.Now()
+	if hold, err := l.circulation.CancelHold(joeHold.ID); err != nil || hold.Status != models.HoldCancelled {
+		t.Fatalf("CancelHold: got %+v, %v", hold, err)
+	}
+	l.assertReady(t, l.hold(t, annHold.ID), cancelledAt.Add(3*24*time.Hour))
+	if _, err := l.circulation.CancelHold(joeHold.ID); !errors.Is(err, models.ErrHoldClosed) {
+		t.Errorf("second CancelHold: got %v, want ErrHoldClosed", err)
+	}
+}
@@ -0,0 +1,171 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+
+	"github.com/shani34/book-management-system/internal/models"
+	"github.com/shani34/book-management-system/internal/repositories"
+	"gorm.io/gorm"
+)
+
+type MemberService struct {
+	repo repositories.MemberStore
+}
+
+func NewMemberService(repo repositories.MemberStore) *MemberService {
+	return &MemberService{repo: repo}
+}
+
+// ForTenant returns the service for tenantID's members and tiers.
+func (s *MemberService) ForTenant(tenantID string) *MemberService {
+	return &MemberService{repo: s.repo.ForTenant(tenantID)}
+}
+
+func (s *MemberService) GetAllMembers(limit, offset int) ([]models.Member, error) {
+	return s.repo.GetAll(limit, offset)
+}
+
+func (s *MemberService) GetMemberByID(id uint) (*models.Member, error) {
+	return s.repo.GetByID(id)
+}
+
+func (s *MemberService) CreateMember(member *models.Member) error {
+	if err := s.validateMember(member); err != nil {
+		return err
+	}
+	member.ID = 0
+	if err := s.repo.Create(member); err != nil {
+		return err
+	}
+	return s.fillTier(member)
+}
+
+// UpdateMember replaces a member's details, including their tier, blocked
+// flag and expiry date. Loans already made keep their due dates.
+func (s *MemberService) UpdateMember(id uint, member *models.Member) error {
+	existing, err := s.repo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if err := s.validateMember(member); err != nil {
+		return err
+	}
+
+	member.ID = existing.ID
+	member.CreatedAt = existing.CreatedAt
+	if err := s.repo.Update(member); err != nil {
+		return err
+	}
+	return s.fillTier(member)
+}
+
+// DeleteMember deletes a member who never borrowed anything.
+func (s *MemberService) DeleteMember(id uint) error {
+	return s.repo.Delete(id)
+}
+
+func (s *MemberService) GetTiers() ([]models.Tier, error) {
+	if err := s.seedTiers(); err != nil {
+		return nil, err
+	}
+	return s.repo.GetTiers()
+}
+
+func (s *MemberService) GetTier(id uint) (*models.Tier, error) {
+	if err := s.seedTiers(); err != nil {
+		return nil, err
+	}
+	return s.repo.GetTier(id)
+}
+
+func (s *MemberService) CreateTier(tier *models.Tier) error {
+	if err := validateTier(tier); err != nil {
+		return err
+	}
+	if err := s.seedTiers(); err != nil {
+		return err
+	}
+	tier.ID = 0
+	return s.repo.CreateTier(tier)
+}
+
+// UpdateTier changes a tier's limits. They apply to the next checkout of
+// every member in the tier; loans already made are unaffected.
+func (s *MemberService) UpdateTier(id uint, tier *models.Tier) error {
+	existing, err := s.repo.GetTier(id)
+	if err != nil {
+		return err
+	}
+	if err := validateTier(tier); err != nil {
+		return err
+	}
+
+	tier.ID = existing.ID
+	tier.CreatedAt = existing.CreatedAt
+	return s.repo.UpdateTier(tier)
+}
+
+// seedTiers gives a tenant the default tiers before its tiers are first
+// read or added to, so every tenant starts out able to enrol members.
+func (s *MemberService) seedTiers() error {
+	return s.repo.SeedTiers(models.DefaultTiers())
+}
+
+func (s *MemberService) fillTier(member *models.Member) error {
+	tier, err := s.repo.GetTier(member.TierID)
+	if err != nil {
+		return err
+	}
+	member.Tier = tier
+	return nil
+}
+
+var (
+	// ErrInvalidMember and ErrInvalidTier wrap validation failures, like
+	// ErrInvalidBook.
+	ErrInvalidMember = errors.New("invalid member")
+	ErrInvalidTier   = errors.New("invalid tier")
+)
+
+func (s *MemberService) validateMember(member *models.Member) error {
+	member.Name = strings.TrimSpace(member.Name)
+	if member.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidMember)
+	}
+	address, err := mail.ParseAddress(strings.TrimSpace(member.Email))
+	if err != nil || address.Name != "" {
+		return fmt.Errorf("%w: email must be a valid address", ErrInvalidMember)
+	}
+	member.Email = strings.ToLower(address.Address)
+
+	if member.TierID == 0 {
+		return fmt.Errorf("%w: tier_id is required", ErrInvalidMember)
+	}
+	if _, err := s.GetTier(member.TierID); errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("%w: tier %d does not exist", ErrInvalidMember, member.TierID)
+	} else if err != nil {
+		return err
+	}
+	member.Tier = nil
+	return nil
+}
+
+func validateTier(tier *models.Tier) error {
+	tier.Name = strings.TrimSpace(tier.Name)
+	if tier.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidTier)
+	}
+	if tier.MaxLoans < 0 {
+		return fmt.Errorf("%w: max_loans must not be negative", ErrInvalidTier)
+	}
+	if tier.LoanDays < 1 {
+		return fmt.Errorf("%w: loan_days must be at least 1", ErrInvalidTier)
+	}
+	if tier.FinePerDay < 0 || tier.FineGraceDays < 0 || tier.FineCap < 0 || tier.FineThreshold < 0 {
+		return fmt.Errorf("%w: fine_per_day, fine_grace_days, fine_cap and fine_threshold must not be negative", ErrInvalidTier)
+	}
+	return nil
+}
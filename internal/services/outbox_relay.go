@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/shani34/book-management-system/internal/repositories"
+	"github.com/shani34/book-management-system/pkg/events"
+	"go.uber.org/zap"
+)
+
+const (
+	outboxBatchSize  = 100
+	outboxMaxBackoff = 5 * time.Minute
+	// outboxLease is how long claimed events are left to one relay before
+	// another may claim them, in case the first died while publishing.
+	outboxLease = time.Minute
+)
+
+// OutboxRelay delivers outbox events to the publisher, a batch at a time.
+// Events are marked sent only after the batch is published, so delivery is
+// at-least-once: a crash between the two, or a batch that fails part way,
+// means the events are published again on a later pass.
+type OutboxRelay struct {
+	store     repositories.OutboxStore
+	publisher events.EventPublisher
+	logger    *zap.Logger
+	interval  time.Duration
+}
+
+func NewOutboxRelay(store repositories.OutboxStore, publisher events.EventPublisher, logger *zap.Logger, interval time.Duration) *OutboxRelay {
+	return &OutboxRelay{
+		store:     store,
+		publisher: publisher,
+		logger:    logger.Named("services.OutboxRelay"),
+		interval:  interval,
+	}
+}
+
+// Run relays pending events every interval until ctx is cancelled.
+func (r *OutboxRelay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		// Drain full batches straight away instead of waiting a tick each.
+		for {
+			n, err := r.RelayPending()
+			if err != nil {
+				r.logger.Error("Failed to relay outbox events", zap.Error(err))
+			}
+			if err != nil || n < outboxBatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RelayPending makes a single delivery pass and reports how many events
+// were published. The batch is claimed, published and marked in separate
+// steps, so no transaction is held open while the publisher is waited on.
+func (r *OutboxRelay) RelayPending() (int, error) {
+	pending, err := r.store.ClaimEvents(outboxBatchSize, outboxLease)
+	if err != nil || len(pending) == 0 {
+		return 0, err
+	}
+
+	messages := make([]events.Message, len(pending))
+	for i, event := range pending {
+		messages[i] = events.Message{Topic: event.Topic, Value: []byte(event.Message)}
+	}
+	publishErr := r.publisher.PublishBatch(messages)
+
+	now := time.Now()
+	for i := range pending {
+		event := &pending[i]
+		event.Attempts++
+		if publishErr != nil {
+			event.LastError = publishErr.Error()
+			event.NextAttemptAt = now.Add(outboxBackoff(event.Attempts))
+			continue
+		}
+		event.SentAt = &now
+		event.LastError = ""
+	}
+	if err := r.store.SaveEvents(pending); err != nil {
+		return 0, err
+	}
+
+	if publishErr != nil {
+		r.logger.Warn("Failed to publish outbox events",
+			zap.Uint("first_event_id", pending[0].ID),
+			zap.Int("events", len(pending)),
+			zap.Int("attempts", pending[0].Attempts),
+			zap.Error(publishErr),
+		)
+		return 0, publishErr
+	}
+	return len(pending), nil
+}
+
+// outboxBackoff doubles the wait after every failed attempt, starting at one
+// second and capped at outboxMaxBackoff.
+func outboxBackoff(attempts int) time.Duration {
+	backoff := time.Second
+	for i := 1; i < attempts && backoff < outboxMaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > outboxMaxBackoff {
+		backoff = outboxMaxBackoff
+	}
+	return backoff
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/shani34/book-management-system/internal/models"
+	"github.com/shani34/book-management-system/internal/repositories"
+	"github.com/shani34/book-management-system/pkg/cache"
+	"github.com/shani34/book-management-system/pkg/events"
+	"go.uber.org/zap"
+)
+
+// failingPublisher refuses every batch it is given while err is set.
+type failingPublisher struct {
+	events.MemoryPublisher
+	err     error
+	batches int
+}
+
+func (p *failingPublisher) PublishBatch(messages []events.Message) error {
+	p.batches++
+	if p.err != nil {
+		return p.err
+	}
+	return p.MemoryPublisher.PublishBatch(messages)
+}
+
+// bookEvent is the envelope of a book_events message.
+type bookEvent struct {
+	EventType string      `json:"event_type"`
+	TenantID  string      `json:"tenant_id"`
+	Payload   models.Book `json:"payload"`
+}
+
+// relayBookEvents delivers everything pending in repo's outbox and returns
+// the book events among it, in order.
+func relayBookEvents(t *testing.T, repo repositories.BookStore) []bookEvent {
+	t.Helper()
+	publisher := events.NewMemoryPublisher()
+	if _, err := NewOutboxRelay(repo, publisher, zap.NewNop(), 0).RelayPending(); err != nil {
+		t.Fatalf("RelayPending: %v", err)
+	}
+	var relayed []bookEvent
+	for _, message := range publisher.Messages() {
+		if message.Topic != bookEventsTopic {
+			continue
+		}
+		var event bookEvent
+		if err := json.Unmarshal(message.Value, &event); err != nil {
+			t.Fatalf("decoding %s: %v", message.Value, err)
+		}
+		relayed = append(relayed, event)
+	}
+	return relayed
+}
+
+func TestOutboxRelayPublishesBatches(t *testing.T) {
+	repo := repositories.NewMemoryBookRepository(repositories.NewMemoryDB())
+	books := NewBookService(repo, cache.Noop{}).ForTenant("a")
+	for _, title := range []string{"Dune", "Emma", "Gilead"} {
+		if err := books.CreateBook(&models.Book{Title: title, Author: "Someone"}); err != nil {
+			t.Fatalf("CreateBook: %v", err)
+		}
+	}
+
+	publisher := &failingPublisher{err: errors.New("broker down")}
+	relay := NewOutboxRelay(repo, publisher, zap.NewNop(), 0)
+
+	if n, err := relay.RelayPending(); err == nil || n != 0 {
+		t.Fatalf("RelayPending with failing publisher: got %d, %v; want 0 and an error", n, err)
+	}
+	// The failed events back off instead of being retried straight away.
+	if n, err := relay.RelayPending(); err != nil || n != 0 {
+		t.Fatalf("RelayPending during backoff: got %d, %v; want nothing", n, err)
+	}
+	if publisher.batches != 1 {
+		t.Errorf("publisher got %d batches, want 1", publisher.batches)
+	}
+	if got := len(publisher.Messages()); got != 0 {
+		t.Errorf("published %d messages while failing, want 0", got)
+	}
+}
+
+func TestOutboxRelayMarksSent(t *testing.T) {
+	repo := repositories.NewMemoryBookRepository(repositories.NewMemoryDB())
+	books := NewBookService(repo, cache.Noop{}).ForTenant("a")
+	for _, title := range []string{"Dune", "Emma", "Gilead"} {
+		if err := books.CreateBook(&models.Book{Title: title, Author: "Someone"}); err != nil {
+			t.Fatalf("CreateBook: %v", err)
+		}
+	}
+
+	publisher := &failingPublisher{}
+	relay := NewOutboxRelay(repo, publisher, zap.NewNop(), 0)
+
+	if n, err := relay.RelayPending(); err != nil || n != 3 {
+		t.Fatalf("RelayPending: got %d, %v; want 3", n, err)
+	}
+	if publisher.batches != 1 {
+		t.Errorf("publisher got %d batches, want the 3 events in 1", publisher.batches)
+	}
+	if n, err := relay.RelayPending(); err != nil || n != 0 {
+		t.Errorf("second RelayPending: got %d, %v; want nothing left", n, err)
+	}
+	if got := len(publisher.Messages()); got != 3 {
+		t.Errorf("published %d messages, want 3", got)
+	}
+}
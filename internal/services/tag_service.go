@@ -0,0 +1,102 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/shani34/book-management-system/internal/models"
+	"github.com/shani34/book-management-system/internal/repositories"
+	"github.com/shani34/book-management-system/pkg/cache"
+)
+
+// TagService manages one tenant's tag vocabulary; get it for a request's
+// tenant with ForTenant. Attaching tags to books goes through BookService
+// so it is versioned and evented like any book change; renames go through
+// books for the same reason.
+type TagService struct {
+	repo  repositories.TagStore
+	books repositories.BookStore
+	cache cache.Cache
+}
+
+func NewTagService(repo repositories.TagStore, books repositories.BookStore, cache cache.Cache) *TagService {
+	return &TagService{
+		repo:  repo,
+		books: books,
+		cache: cache,
+	}
+}
+
+// ForTenant returns the service for tenantID's tags and books.
+func (s *TagService) ForTenant(tenantID string) *TagService {
+	return &TagService{
+		repo:  s.repo.ForTenant(tenantID),
+		books: s.books.ForTenant(tenantID),
+		cache: cache.ForTenant(s.cache, tenantID),
+	}
+}
+
+func (s *TagService) GetAllTags(limit, offset int) ([]models.Tag, error) {
+	return s.repo.GetAll(limit, offset)
+}
+
+func (s *TagService) GetTagByID(id uint) (*models.Tag, error) {
+	return s.repo.GetByID(id)
+}
+
+func (s *TagService) CreateTag(tag *models.Tag) error {
+	if err := validateTag(tag); err != nil {
+		return err
+	}
+	return s.repo.Create(tag)
+}
+
+func (s *TagService) UpdateTag(id uint, tag *models.Tag) error {
+	existing, err := s.repo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if err := validateTag(tag); err != nil {
+		return err
+	}
+
+	// Every book carrying the tag gets a new version and a book_updated
+	// event in the same transaction as the rename.
+	tag.ID = existing.ID
+	var tagged []models.Book
+	err = s.books.Transaction(func(tx repositories.BookStore) error {
+		var err error
+		if tagged, err = tx.LockBooksByTag(tag.ID); err != nil {
+			return err
+		}
+		if err := tx.Tags().Update(tag); err != nil {
+			return err
+		}
+		return touchBooks(tx, tagged)
+	})
+	if err != nil {
+		return err
+	}
+
+	// Cached books and filtered listings embed tag names.
+	forgetBooks(s.cache, tagged)
+	return nil
+}
+
+// DeleteTag deletes a tag no book carries any more. It is refused while
+// any book, even one in the trash, still has the tag, so a delete never
+// changes a book; detaching the tag is what versions and events them.
+func (s *TagService) DeleteTag(id uint) error {
+	return s.repo.Delete(id)
+}
+
+// ErrInvalidTag wraps tag validation failures.
+var ErrInvalidTag = errors.New("invalid tag")
+
+func validateTag(tag *models.Tag) error {
+	tag.Name = models.NormalizeTagName(tag.Name)
+	if tag.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidTag)
+	}
+	return nil
+}
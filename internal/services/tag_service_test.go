@@ -0,0 +1,162 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shani34/book-management-system/internal/models"
+	"github.com/shani34/book-management-system/internal/repositories"
+	"github.com/shani34/book-management-system/pkg/cache"
+	"gorm.io/gorm"
+)
+
+func TestTagRenameTouchesTaggedBooks(t *testing.T) {
+	db := repositories.NewMemoryDB()
+	repo := repositories.NewMemoryBookRepository(db)
+	bookCache := cache.NewLRU(100)
+	books := NewBookService(repo, bookCache)
+	tags := NewTagService(repositories.NewMemoryTagRepository(db), repo, bookCache)
+
+	// Both tenants have a tag of the same name, each on one book.
+	tagged := map[string]*models.Book{}
+	tagIDs := map[string]uint{}
+	for tenant, title := range map[string]string{"a": "Dune", "b": "Hyperion"} {
+		tag := &models.Tag{Name: "Science Fiction"}
+		if err := tags.ForTenant(tenant).CreateTag(tag); err != nil {
+			t.Fatalf("CreateTag: %v", err)
+		}
+		book := &models.Book{Title: title, Author: "Someone"}
+		if err := books.ForTenant(tenant).CreateBook(book); err != nil {
+			t.Fatalf("CreateBook: %v", err)
+		}
+		if _, err := books.ForTenant(tenant).AttachTag(book.ID, tag.ID); err != nil {
+			t.Fatalf("AttachTag: %v", err)
+		}
+		tagged[tenant], tagIDs[tenant] = book, tag.ID
+	}
+	untagged := &models.Book{Title: "Emma", Author: "Jane Austen"}
+	if err := books.ForTenant("a").CreateBook(untagged); err != nil {
+		t.Fatalf("CreateBook: %v", err)
+	}
+	relayBookEvents(t, repo)
+
+	// Warm the cache so a stale entry would show.
+	if _, err := books.ForTenant("a").GetBookByID(tagged["a"].ID); err != nil {
+		t.Fatalf("GetBookByID: %v", err)
+	}
+	if err := tags.ForTenant("a").UpdateTag(tagIDs["a"], &models.Tag{Name: "sf"}); err != nil {
+		t.Fatalf("UpdateTag: %v", err)
+	}
+
+	// Created at 1, tagged at 2, renamed at 3.
+	for tenant, want := range map[string]struct {
+		version uint
+		name    string
+	}{"a": {3, "sf"}, "b": {2, "science fiction"}} {
+		got, err := books.ForTenant(tenant).GetBookByID(tagged[tenant].ID)
+		if err != nil {
+			t.Fatalf("GetBookByID: %v", err)
+		}
+		if got.Version != want.version || len(got.Tags) != 1 || got.Tags[0].Name != want.name {
+			t.Errorf("%s's %q after a's rename: got version %d, tags %+v; want version %d and %q",
+				tenant, got.Title, got.Version, got.Tags, want.version, want.name)
+		}
+	}
+	if got, err := books.ForTenant("a").GetBookByID(untagged.ID); err != nil || got.Version != 1 {
+		t.Errorf("untagged book after rename: got %+v, %v; want version 1", got, err)
+	}
+
+	relayed := relayBookEvents(t, repo)
+	if len(relayed) != 1 {
+		t.Fatalf("got %d book events, want 1", len(relayed))
+	}
+	book := relayed[0].Payload
+	if relayed[0].EventType != "book_updated" || relayed[0].TenantID != "a" || book.Version != 3 ||
+		len(book.Tags) != 1 || book.Tags[0].Name != "sf" {
+		t.Errorf("event: got %s for %q of tenant %q at version %d, tags %+v",
+			relayed[0].EventType, book.Title, relayed[0].TenantID, book.Version, book.Tags)
+	}
+}
+
+func TestTagsAreIsolated(t *testing.T) {
+	db := repositories.NewMemoryDB()
+	repo := repositories.NewMemoryBookRepository(db)
+	service := NewTagService(repositories.NewMemoryTagRepository(db), repo, cache.Noop{})
+	a, b := service.ForTenant("a"), service.ForTenant("b")
+
+	tag := &models.Tag{Name: "classics"}
+	if err := a.CreateTag(tag); err != nil {
+		t.Fatalf("CreateTag: %v", err)
+	}
+	if _, err := b.GetTagByID(tag.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("GetTagByID from b: got %v, want not found", err)
+	}
+	if err := b.UpdateTag(tag.ID, &models.Tag{Name: "old"}); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("UpdateTag from b: got %v, want not found", err)
+	}
+	if err := b.DeleteTag(tag.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("DeleteTag from b: got %v, want not found", err)
+	}
+	if all, err := b.GetAllTags(10, 0); err != nil || len(all) != 0 {
+		t.Errorf("GetAllTags from b: got %+v, %v; want none", all, err)
+	}
+
+	books := NewBookService(repo, cache.Noop{}).ForTenant("b")
+	book := &models.Book{Title: "Emma", Author: "Jane Austen"}
+	if err := books.CreateBook(book); err != nil {
+		t.Fatalf("CreateBook: %v", err)
+	}
+	if _, err := books.AttachTag(book.ID, tag.ID); !errors.Is(err, ErrTagNotFound) {
+		t.Errorf("b attaching a's tag: got %v, want ErrTagNotFound", err)
+	}
+}
+
+func TestTagDeleteNeverChangesABook(t *testing.T) {
+	db := repositories.NewMemoryDB()
+	repo := repositories.NewMemoryBookRepository(db)
+	books := NewBookService(repo, cache.Noop{}).ForTenant("a")
+	tags := NewTagService(repositories.NewMemoryTagRepository(db), repo, cache.Noop{}).ForTenant("a")
+
+	tag := &models.Tag{Name: "classics"}
+	if err := tags.CreateTag(tag); err != nil {
+		t.Fatalf("CreateTag: %v", err)
+	}
+	book := &models.Book{Title: "Emma", Author: "Jane Austen"}
+	if err := books.CreateBook(book); err != nil {
+		t.Fatalf("CreateBook: %v", err)
+	}
+	if _, err := books.AttachTag(book.ID, tag.ID); err != nil {
+		t.Fatalf("AttachTag: %v", err)
+	}
+	if err := books.DeleteBook(book.ID, 0); err != nil {
+		t.Fatalf("DeleteBook: %v", err)
+	}
+	relayBookEvents(t, repo)
+
+	// Even a book in the trash keeps the tag in use.
+	if err := tags.DeleteTag(tag.ID); !errors.Is(err, models.ErrTagInUse) {
+		t.Fatalf("DeleteTag of a tag in use: got %v, want ErrTagInUse", err)
+	}
+	if relayed := relayBookEvents(t, repo); len(relayed) != 0 {
+		t.Errorf("refused delete published %d events", len(relayed))
+	}
+
+	if _, err := books.RestoreBook(book.ID); err != nil {
+		t.Fatalf("RestoreBook: %v", err)
+	}
+	detached, err := books.DetachTag(book.ID, tag.ID)
+	if err != nil {
+		t.Fatalf("DetachTag: %v", err)
+	}
+	relayBookEvents(t, repo)
+
+	if err := tags.DeleteTag(tag.ID); err != nil {
+		t.Fatalf("DeleteTag once unused: %v", err)
+	}
+	if got, err := books.GetBookByID(book.ID); err != nil || got.Version != detached.Version {
+		t.Errorf("book after delete: got %+v, %v; want version %d", got, err, detached.Version)
+	}
+	if relayed := relayBookEvents(t, repo); len(relayed) != 0 {
+		t.Errorf("delete of an unused tag published %d events", len(relayed))
+	}
+}
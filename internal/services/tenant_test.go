@@ -0,0 +1,147 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shani34/book-management-system/internal/models"
+	"github.com/shani34/book-management-system/internal/repositories"
+	"github.com/shani34/book-management-system/pkg/cache"
+	"gorm.io/gorm"
+)
+
+// newTenantBooks returns book services for tenants a and b sharing one
+// memory database, with a book created in a.
+func newTenantBooks(t *testing.T) (a, b *BookService, book *models.Book) {
+	t.Helper()
+	service := NewBookService(repositories.NewMemoryBookRepository(repositories.NewMemoryDB()), cache.Noop{})
+	a, b = service.ForTenant("a"), service.ForTenant("b")
+	book = &models.Book{Title: "Dune", Author: "Frank Herbert", Year: 1965, ISBN13: "9780441013593"}
+	if err := a.CreateBook(book); err != nil {
+		t.Fatalf("CreateBook: %v", err)
+	}
+	return a, b, book
+}
+
+func TestTenantBooksAreIsolated(t *testing.T) {
+	a, b, book := newTenantBooks(t)
+
+	if _, err := b.GetBookByID(book.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("GetBookByID from b: got %v, want not found", err)
+	}
+	if _, err := b.GetBookByISBN(book.ISBN13); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("GetBookByISBN from b: got %v, want not found", err)
+	}
+	if books, err := b.GetAllBooks(models.BookFilter{}, 10, 0); err != nil || len(books) != 0 {
+		t.Errorf("GetAllBooks from b: got %d books, %v; want none", len(books), err)
+	}
+	if books, err := a.GetAllBooks(models.BookFilter{}, 10, 0); err != nil || len(books) != 1 {
+		t.Errorf("GetAllBooks from a: got %d books, %v; want 1", len(books), err)
+	}
+
+	exported := 0
+	err := b.ExportBooks(models.BookFilter{}, func(*models.Book) error {
+		exported++
+		return nil
+	})
+	if err != nil || exported != 0 {
+		t.Errorf("ExportBooks from b: got %d books, %v; want none", exported, err)
+	}
+
+	update := &models.Book{Title: "Dune Messiah", Author: "Frank Herbert", Year: 1969}
+	if err := b.UpdateBook(book.ID, 0, update); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("UpdateBook from b: got %v, want not found", err)
+	}
+	if err := b.DeleteBook(book.ID, 0); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("DeleteBook from b: got %v, want not found", err)
+	}
+	if got, err := a.GetBookByID(book.ID); err != nil || got.Title != "Dune" {
+		t.Errorf("GetBookByID from a after b's writes: got %+v, %v", got, err)
+	}
+}
+
+func TestTenantCursorStaysInTenant(t *testing.T) {
+	a, b, _ := newTenantBooks(t)
+	for _, title := range []string{"Emma", "Fahrenheit 451"} {
+		if err := a.CreateBook(&models.Book{Title: title, Author: "Someone"}); err != nil {
+			t.Fatalf("CreateBook: %v", err)
+		}
+	}
+	if err := b.CreateBook(&models.Book{Title: "Gilead", Author: "Marilynne Robinson"}); err != nil {
+		t.Fatalf("CreateBook: %v", err)
+	}
+
+	page, err := a.GetBooksPage(models.BookFilter{}, "", 1)
+	if err != nil {
+		t.Fatalf("GetBooksPage: %v", err)
+	}
+	if page.Total != 3 || page.NextCursor == "" {
+		t.Fatalf("first page from a: total %d, next %q; want 3 and a cursor", page.Total, page.NextCursor)
+	}
+
+	// a's cursor used by b still only walks b's books.
+	page, err = b.GetBooksPage(models.BookFilter{}, page.NextCursor, 10)
+	if err != nil {
+		t.Fatalf("GetBooksPage from b: %v", err)
+	}
+	if page.Total != 1 {
+		t.Errorf("total from b: got %d, want 1", page.Total)
+	}
+	for _, book := range page.Items {
+		if book.TenantID != "b" {
+			t.Errorf("page from b has %q of tenant %q", book.Title, book.TenantID)
+		}
+	}
+}
+
+func TestTenantTrashIsIsolated(t *testing.T) {
+	a, b, book := newTenantBooks(t)
+	if err := a.DeleteBook(book.ID, 0); err != nil {
+		t.Fatalf("DeleteBook: %v", err)
+	}
+
+	if trash, err := b.ListTrash(10, 0); err != nil || len(trash) != 0 {
+		t.Errorf("ListTrash from b: got %d books, %v; want none", len(trash), err)
+	}
+	if _, err := b.RestoreBook(book.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("RestoreBook from b: got %v, want not found", err)
+	}
+	if err := b.PurgeBook(book.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("PurgeBook from b: got %v, want not found", err)
+	}
+	if trash, err := a.ListTrash(10, 0); err != nil || len(trash) != 1 {
+		t.Errorf("ListTrash from a: got %d books, %v; want 1", len(trash), err)
+	}
+}
+
+func TestCreateBookIgnoresServerOwnedFields(t *testing.T) {
+	a, b, book := newTenantBooks(t)
+
+	// A client naming a's book ID, another tenant and a deletion time must
+	// still get a new, live book of its own tenant.
+	forged := &models.Book{
+		ID:        book.ID,
+		TenantID:  "a",
+		Version:   7,
+		DeletedAt: gorm.DeletedAt{Time: time.Now(), Valid: true},
+		Title:     "Forged",
+		Author:    "Mallory",
+	}
+	if err := b.CreateBook(forged); err != nil {
+		t.Fatalf("CreateBook: %v", err)
+	}
+	if forged.ID == book.ID {
+		t.Errorf("created book reused ID %d", book.ID)
+	}
+	if forged.TenantID != "b" || forged.Version != 1 || forged.DeletedAt.Valid {
+		t.Errorf("created book: tenant %q, version %d, deleted %v; want b, 1, live",
+			forged.TenantID, forged.Version, forged.DeletedAt.Valid)
+	}
+	if got, err := b.GetBookByID(forged.ID); err != nil || got.Title != "Forged" {
+		t.Errorf("GetBookByID from b: got %+v, %v", got, err)
+	}
+	if got, err := a.GetBookByID(book.ID); err != nil || got.Title != "Dune" {
+		t.Errorf("a's book after b's create: got %+v, %v", got, err)
+	}
+}
@@ -0,0 +1,63 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/shani34/book-management-system/config"
+	"github.com/shani34/book-management-system/pkg/redis"
+)
+
+// ErrMiss is returned by Get when the key is absent or expired.
+var ErrMiss = errors.New("cache: miss")
+
+// Cache is the key/value store used to memoise read paths. Values are
+// returned as strings, matching what the Redis client hands back.
+type Cache interface {
+	Get(key string) (string, error)
+	Set(key string, value interface{}, expiration time.Duration) error
+	Delete(keys ...string) error
+	DeletePrefix(prefix string) error
+}
+
+var (
+	_ Cache = (*redis.RedisClient)(nil)
+	_ Cache = (*LRU)(nil)
+	_ Cache = Noop{}
+	_ Cache = (*Fallback)(nil)
+)
+
+// InitCache builds the cache selected by CACHE_DRIVER. When Redis is chosen
+// but cannot be reached, the in-process LRU is used instead so the service
+// keeps working with a per-replica cache.
+func InitCache() Cache {
+	cfg := config.Get().Cache
+
+	switch cfg.Driver {
+	case "none":
+		return Noop{}
+	case "memory":
+		return NewLRU(cfg.Size)
+	default:
+		lru := NewLRU(cfg.Size)
+		client, err := redis.InitRedis()
+		if err != nil {
+			log.Printf("Redis unavailable, falling back to in-process cache: %v", err)
+			return lru
+		}
+		return NewFallback(client, lru)
+	}
+}
+
+func stringValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,105 @@
+package cache
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// primaryRetry is how long Fallback leaves a failed primary alone before
+// trying it again.
+const primaryRetry = 5 * time.Second
+
+// Fallback serves from primary and switches to secondary for any call where
+// primary fails with something other than a miss, e.g. Redis going away
+// after startup. A failure opens a breaker: for the next primaryRetry every
+// call goes straight to secondary, so an outage costs one slow call per
+// interval rather than one per request, and then primary is tried again.
+//
+// Invalidations are applied to both while primary is up, so that neither
+// side can serve an entry the other has already dropped. Entries written to
+// primary before an outage can still outlive invalidations issued during it,
+// bounded by their TTL.
+type Fallback struct {
+	primary   Cache
+	secondary Cache
+	retry     time.Duration
+	now       func() time.Time
+
+	mu        sync.Mutex
+	openUntil time.Time
+}
+
+func NewFallback(primary, secondary Cache) *Fallback {
+	return &Fallback{primary: primary, secondary: secondary, retry: primaryRetry, now: time.Now}
+}
+
+func (f *Fallback) Get(key string) (string, error) {
+	if !f.primaryUp() {
+		return f.secondary.Get(key)
+	}
+	value, err := f.primary.Get(key)
+	if err == nil {
+		return value, nil
+	}
+	if isMiss(err) {
+		return "", ErrMiss
+	}
+	f.trip("get", err)
+	return f.secondary.Get(key)
+}
+
+func (f *Fallback) Set(key string, value interface{}, expiration time.Duration) error {
+	if !f.primaryUp() {
+		return f.secondary.Set(key, value, expiration)
+	}
+	if err := f.primary.Set(key, value, expiration); err != nil {
+		f.trip("set", err)
+		return f.secondary.Set(key, value, expiration)
+	}
+	return nil
+}
+
+func (f *Fallback) Delete(keys ...string) error {
+	secondaryErr := f.secondary.Delete(keys...)
+	if f.primaryUp() {
+		if err := f.primary.Delete(keys...); err != nil {
+			f.trip("delete", err)
+		}
+	}
+	return secondaryErr
+}
+
+func (f *Fallback) DeletePrefix(prefix string) error {
+	secondaryErr := f.secondary.DeletePrefix(prefix)
+	if f.primaryUp() {
+		if err := f.primary.DeletePrefix(prefix); err != nil {
+			f.trip("prefix delete", err)
+		}
+	}
+	return secondaryErr
+}
+
+// primaryUp reports whether primary should be tried: it hasn't failed in
+// the last retry interval.
+func (f *Fallback) primaryUp() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return !f.now().Before(f.openUntil)
+}
+
+// trip records that primary failed and leaves it alone until the retry
+// interval is over.
+func (f *Fallback) trip(op string, err error) {
+	f.mu.Lock()
+	f.openUntil = f.now().Add(f.retry)
+	f.mu.Unlock()
+	log.Printf("Primary cache %s failed, using fallback for %s: %v", op, f.retry, err)
+}
+
+func isMiss(err error) bool {
+	return errors.Is(err, ErrMiss) || errors.Is(err, goredis.Nil)
+}
@@ -0,0 +1,155 @@
+package cache
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// flakyCache is an LRU that fails every call while down, like a Redis that
+// has gone away.
+type flakyCache struct {
+	*LRU
+	down  bool
+	calls int
+}
+
+var errDown = errors.New("connection refused")
+
+func (f *flakyCache) Get(key string) (string, error) {
+	f.calls++
+	if f.down {
+		return "", errDown
+	}
+	return f.LRU.Get(key)
+}
+
+func (f *flakyCache) Set(key string, value interface{}, expiration time.Duration) error {
+	f.calls++
+	if f.down {
+		return errDown
+	}
+	return f.LRU.Set(key, value, expiration)
+}
+
+func (f *flakyCache) Delete(keys ...string) error {
+	f.calls++
+	if f.down {
+		return errDown
+	}
+	return f.LRU.Delete(keys...)
+}
+
+func (f *flakyCache) DeletePrefix(prefix string) error {
+	f.calls++
+	if f.down {
+		return errDown
+	}
+	return f.LRU.DeletePrefix(prefix)
+}
+
+func newTestFallback() (*Fallback, *flakyCache, *LRU, *clock) {
+	secondary, clock := newTestLRU(10)
+	primary := &flakyCache{LRU: NewLRU(10)}
+	primary.LRU.now = clock.Now
+	f := NewFallback(primary, secondary)
+	f.now = clock.Now
+	return f, primary, secondary, clock
+}
+
+func TestFallbackServesFromPrimary(t *testing.T) {
+	f, primary, secondary, _ := newTestFallback()
+	secondary.Set("k", "stale", 0)
+
+	f.Set("k", "v", 0)
+	assertCached(t, f, "k", "v")
+	assertCached(t, secondary, "k", "stale")
+
+	// A miss on a healthy primary is a miss, not a reason to fall back.
+	assertCached(t, f, "other", "")
+	secondary.Set("other", "stale", 0)
+	assertCached(t, f, "other", "")
+	if primary.calls != 4 {
+		t.Errorf("primary called %d times, want 4", primary.calls)
+	}
+}
+
+func TestFallbackSwitchesOverAndRecovers(t *testing.T) {
+	tests := []struct {
+		name string
+		fail func(f *Fallback) error
+	}{
+		{"get", func(f *Fallback) error { _, err := f.Get("k"); return err }},
+		{"set", func(f *Fallback) error { return f.Set("k", "v", 0) }},
+		{"delete", func(f *Fallback) error { return f.Delete("k") }},
+		{"prefix delete", func(f *Fallback) error { return f.DeletePrefix("k") }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, primary, secondary, clock := newTestFallback()
+			primary.down = true
+			tt.fail(f)
+			if primary.calls != 1 {
+				t.Fatalf("primary called %d times, want 1", primary.calls)
+			}
+
+			// While the breaker is open, the secondary serves without
+			// primary being tried.
+			if err := f.Set("k", "fallback", 0); err != nil {
+				t.Fatalf("Set during outage: %v", err)
+			}
+			assertCached(t, f, "k", "fallback")
+			assertCached(t, secondary, "k", "fallback")
+			if primary.calls != 1 {
+				t.Errorf("primary called %d times during the cooldown, want 1", primary.calls)
+			}
+
+			// Once the interval is over, a recovered primary is used again.
+			primary.down = false
+			clock.Advance(primaryRetry)
+			primary.LRU.Set("k", "primary", 0)
+			assertCached(t, f, "k", "primary")
+			if primary.calls != 2 {
+				t.Errorf("primary called %d times after recovery, want 2", primary.calls)
+			}
+		})
+	}
+}
+
+func TestFallbackStaysOpenWhilePrimaryIsDown(t *testing.T) {
+	f, primary, secondary, clock := newTestFallback()
+	primary.down = true
+	secondary.Set("k", "fallback", 0)
+
+	for i := 0; i < 3; i++ {
+		assertCached(t, f, "k", "fallback")
+		clock.Advance(primaryRetry / 2)
+	}
+	// Reads at 0 and 5s reach primary; the one at 2.5s is in the cooldown.
+	if primary.calls != 2 {
+		t.Errorf("primary called %d times, want once per retry interval", primary.calls)
+	}
+}
+
+func TestFallbackInvalidatesBoth(t *testing.T) {
+	tests := []struct {
+		name       string
+		invalidate func(f *Fallback) error
+	}{
+		{"delete", func(f *Fallback) error { return f.Delete("books:1") }},
+		{"prefix delete", func(f *Fallback) error { return f.DeletePrefix("books:") }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, primary, secondary, _ := newTestFallback()
+			primary.LRU.Set("books:1", "v", 0)
+			secondary.Set("books:1", "v", 0)
+
+			if err := tt.invalidate(f); err != nil {
+				t.Fatalf("invalidate: %v", err)
+			}
+			assertCached(t, primary.LRU, "books:1", "")
+			assertCached(t, secondary, "books:1", "")
+		})
+	}
+}
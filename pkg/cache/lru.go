@@ -0,0 +1,111 @@
+package cache
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LRU is a bounded in-process cache. Once it holds size entries, the least
+// recently used one is evicted to make room; expired entries are dropped
+// lazily when they are next read.
+type LRU struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List
+	entries map[string]*list.Element
+	now     func() time.Time
+}
+
+type lruEntry struct {
+	key       string
+	value     string
+	expiresAt time.Time
+}
+
+func NewLRU(size int) *LRU {
+	if size <= 0 {
+		size = 1024
+	}
+	return &LRU{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+		now:     time.Now,
+	}
+}
+
+func (c *LRU) Get(key string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return "", ErrMiss
+	}
+	entry := elem.Value.(*lruEntry)
+	if !entry.expiresAt.IsZero() && c.now().After(entry.expiresAt) {
+		c.remove(elem)
+		return "", ErrMiss
+	}
+	c.order.MoveToFront(elem)
+	return entry.value, nil
+}
+
+func (c *LRU) Set(key string, value interface{}, expiration time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var expiresAt time.Time
+	if expiration > 0 {
+		expiresAt = c.now().Add(expiration)
+	}
+
+	if elem, ok := c.entries[key]; ok {
+		entry := elem.Value.(*lruEntry)
+		entry.value = stringValue(value)
+		entry.expiresAt = expiresAt
+		c.order.MoveToFront(elem)
+		return nil
+	}
+
+	c.entries[key] = c.order.PushFront(&lruEntry{
+		key:       key,
+		value:     stringValue(value),
+		expiresAt: expiresAt,
+	})
+	for c.order.Len() > c.size {
+		c.remove(c.order.Back())
+	}
+	return nil
+}
+
+func (c *LRU) Delete(keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range keys {
+		if elem, ok := c.entries[key]; ok {
+			c.remove(elem)
+		}
+	}
+	return nil
+}
+
+func (c *LRU) DeletePrefix(prefix string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, elem := range c.entries {
+		if strings.HasPrefix(key, prefix) {
+			c.remove(elem)
+		}
+	}
+	return nil
+}
+
+func (c *LRU) remove(elem *list.Element) {
+	c.order.Remove(elem)
+	delete(c.entries, elem.Value.(*lruEntry).key)
+}
@@ -0,0 +1,114 @@
+package cache
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// clock is a fake time source that only moves when told to.
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time { return c.now }
+
+func (c *clock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func newTestLRU(size int) (*LRU, *clock) {
+	c := &clock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	l := NewLRU(size)
+	l.now = c.Now
+	return l, c
+}
+
+// assertCached checks that key holds want, or is a miss when want is "".
+func assertCached(t *testing.T, c Cache, key, want string) {
+	t.Helper()
+	got, err := c.Get(key)
+	if want == "" {
+		if !errors.Is(err, ErrMiss) {
+			t.Errorf("Get(%q) = %q, %v; want a miss", key, got, err)
+		}
+		return
+	}
+	if err != nil || got != want {
+		t.Errorf("Get(%q) = %q, %v; want %q", key, got, err, want)
+	}
+}
+
+func TestLRUExpiry(t *testing.T) {
+	tests := []struct {
+		name    string
+		ttl     time.Duration
+		elapsed time.Duration
+		want    string
+	}{
+		{"no expiration", 0, 24 * time.Hour, "v"},
+		{"before expiry", time.Minute, 59 * time.Second, "v"},
+		{"at expiry", time.Minute, time.Minute, "v"},
+		{"after expiry", time.Minute, time.Minute + time.Second, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, clock := newTestLRU(10)
+			l.Set("k", "v", tt.ttl)
+			clock.Advance(tt.elapsed)
+			assertCached(t, l, "k", tt.want)
+		})
+	}
+}
+
+func TestLRUSetRefreshesExpiry(t *testing.T) {
+	l, clock := newTestLRU(10)
+	l.Set("k", "old", time.Minute)
+	clock.Advance(50 * time.Second)
+	l.Set("k", "new", time.Minute)
+	clock.Advance(50 * time.Second)
+	assertCached(t, l, "k", "new")
+
+	clock.Advance(time.Minute)
+	assertCached(t, l, "k", "")
+	if l.order.Len() != 0 || len(l.entries) != 0 {
+		t.Errorf("expired entry kept after read: %d in order, %d in entries", l.order.Len(), len(l.entries))
+	}
+}
+
+func TestLRUEvictionOrder(t *testing.T) {
+	tests := []struct {
+		name    string
+		run     func(l *LRU)
+		evicted string
+		kept    []string
+	}{
+		{"oldest write", func(l *LRU) {}, "a", []string{"b", "c"}},
+		{"read keeps an entry", func(l *LRU) { l.Get("a") }, "b", []string{"a", "c"}},
+		{"rewrite keeps an entry", func(l *LRU) { l.Set("a", "a", 0) }, "b", []string{"a", "c"}},
+		{"delete makes room", func(l *LRU) { l.Delete("b") }, "b", []string{"a", "c"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, _ := newTestLRU(2)
+			l.Set("a", "a", 0)
+			l.Set("b", "b", 0)
+			tt.run(l)
+			l.Set("c", "c", 0)
+
+			assertCached(t, l, tt.evicted, "")
+			for _, key := range tt.kept {
+				assertCached(t, l, key, key)
+			}
+		})
+	}
+}
+
+func TestLRUDeletePrefix(t *testing.T) {
+	l, _ := newTestLRU(10)
+	for _, key := range []string{"books:1", "books:2", "book:1"} {
+		l.Set(key, key, 0)
+	}
+	l.DeletePrefix("books:")
+	assertCached(t, l, "books:1", "")
+	assertCached(t, l, "books:2", "")
+	assertCached(t, l, "book:1", "book:1")
+}
@@ -0,0 +1,45 @@
+package cache
+
+import "time"
+
+// TenantPrefix starts the keys of every tenant's namespace. Deleting it
+// drops the cached entries of all tenants at once.
+const TenantPrefix = "tenant:"
+
+// Namespace prefixes every key passed to c with prefix, so callers sharing
+// one backend can't read or invalidate each other's entries.
+type Namespace struct {
+	cache  Cache
+	prefix string
+}
+
+var _ Cache = (*Namespace)(nil)
+
+func NewNamespace(c Cache, prefix string) *Namespace {
+	return &Namespace{cache: c, prefix: prefix}
+}
+
+// ForTenant returns the namespace of tenantID's entries.
+func ForTenant(c Cache, tenantID string) *Namespace {
+	return NewNamespace(c, TenantPrefix+tenantID+":")
+}
+
+func (n *Namespace) Get(key string) (string, error) {
+	return n.cache.Get(n.prefix + key)
+}
+
+func (n *Namespace) Set(key string, value interface{}, expiration time.Duration) error {
+	return n.cache.Set(n.prefix+key, value, expiration)
+}
+
+func (n *Namespace) Delete(keys ...string) error {
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = n.prefix + key
+	}
+	return n.cache.Delete(prefixed...)
+}
+
+func (n *Namespace) DeletePrefix(prefix string) error {
+	return n.cache.DeletePrefix(n.prefix + prefix)
+}
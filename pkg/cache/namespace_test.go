@@ -0,0 +1,72 @@
+package cache
+
+import "testing"
+
+func TestNamespacePrefixesKeys(t *testing.T) {
+	backend, _ := newTestLRU(10)
+	a := ForTenant(backend, "a")
+	b := ForTenant(backend, "b")
+
+	a.Set("book:1", "from a", 0)
+	b.Set("book:1", "from b", 0)
+
+	assertCached(t, backend, "tenant:a:book:1", "from a")
+	assertCached(t, backend, "tenant:b:book:1", "from b")
+	assertCached(t, backend, "book:1", "")
+	assertCached(t, a, "book:1", "from a")
+	assertCached(t, b, "book:1", "from b")
+
+	a.Delete("book:1")
+	assertCached(t, a, "book:1", "")
+	assertCached(t, b, "book:1", "from b")
+}
+
+func TestNamespaceDeletePrefix(t *testing.T) {
+	tests := []struct {
+		name    string
+		cache   func(backend Cache) Cache
+		prefix  string
+		dropped []string
+		kept    []string
+	}{
+		{
+			name:    "own listings",
+			cache:   func(backend Cache) Cache { return ForTenant(backend, "a") },
+			prefix:  "books:",
+			dropped: []string{"tenant:a:books:1"},
+			kept:    []string{"tenant:a:book:1", "tenant:b:books:1", "books:1"},
+		},
+		{
+			name:    "whole namespace",
+			cache:   func(backend Cache) Cache { return ForTenant(backend, "a") },
+			prefix:  "",
+			dropped: []string{"tenant:a:books:1", "tenant:a:book:1"},
+			kept:    []string{"tenant:b:books:1", "books:1"},
+		},
+		{
+			name:    "every tenant",
+			cache:   func(backend Cache) Cache { return backend },
+			prefix:  TenantPrefix,
+			dropped: []string{"tenant:a:books:1", "tenant:a:book:1", "tenant:b:books:1"},
+			kept:    []string{"books:1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend, _ := newTestLRU(10)
+			for _, key := range []string{"tenant:a:books:1", "tenant:a:book:1", "tenant:b:books:1", "books:1"} {
+				backend.Set(key, key, 0)
+			}
+
+			if err := tt.cache(backend).DeletePrefix(tt.prefix); err != nil {
+				t.Fatalf("DeletePrefix(%q): %v", tt.prefix, err)
+			}
+			for _, key := range tt.dropped {
+				assertCached(t, backend, key, "")
+			}
+			for _, key := range tt.kept {
+				assertCached(t, backend, key, key)
+			}
+		})
+	}
+}
@@ -0,0 +1,14 @@
+package cache
+
+import "time"
+
+// Noop disables caching: every Get misses and writes are discarded.
+type Noop struct{}
+
+func (Noop) Get(key string) (string, error) { return "", ErrMiss }
+
+func (Noop) Set(key string, value interface{}, expiration time.Duration) error { return nil }
+
+func (Noop) Delete(keys ...string) error { return nil }
+
+func (Noop) DeletePrefix(prefix string) error { return nil }
@@ -25,15 +25,115 @@ func InitDB() (*gorm.DB, error) {
 	)
 
 	var err error
-	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{TranslateError: true})
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
 	// Auto migrate models
-	if err = DB.AutoMigrate(&models.Book{}); err != nil {
+	if err = DB.AutoMigrate(&models.Book{}, &models.Author{}, &models.Tag{}, &models.Edition{}, &models.Copy{}, &models.Tier{}, &models.Member{}, &models.Loan{}, &models.Hold{}, &models.Fine{}, &models.LedgerEntry{}, &models.APIKey{}, &models.OutboxEvent{}); err != nil {
 		return nil, fmt.Errorf("failed to auto-migrate database: %w", err)
 	}
 
+	if err = migrateSearch(DB); err != nil {
+		return nil, fmt.Errorf("failed to migrate search index: %w", err)
+	}
+
+	if err = migrateISBN(DB); err != nil {
+		return nil, fmt.Errorf("failed to migrate isbn indexes: %w", err)
+	}
+
+	if err = migrateLoans(DB); err != nil {
+		return nil, fmt.Errorf("failed to migrate loan constraints: %w", err)
+	}
+
+	if err = migrateAuthors(DB); err != nil {
+		return nil, fmt.Errorf("failed to migrate authors: %w", err)
+	}
+
 	return DB, nil
+}
+
+// migrateLoans allows at most one active loan per copy and one active hold
+// per member and book. Checkouts also lock the copy row; the indexes catch
+// anything that slips past that.
+func migrateLoans(db *gorm.DB) error {
+	statements := []string{
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_loans_active_copy
+			ON loans (copy_id) WHERE returned_at IS NULL`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_holds_active_member
+			ON holds (book_id, member_id) WHERE status IN ('waiting', 'ready')`,
+	}
+	for _, statement := range statements {
+		if err := db.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateAuthors turns the free-text author of every book that isn't linked
+// to an Author yet into an Author row of the book's tenant and links the
+// two. Books already linked are left alone, so re-linking done through the
+// API survives restarts.
+func migrateAuthors(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		statements := []string{
+			`INSERT INTO authors (tenant_id, name, created_at, updated_at)
+			SELECT DISTINCT b.tenant_id, btrim(b.author), now(), now()
+			FROM books b
+			WHERE btrim(b.author) <> ''
+				AND NOT EXISTS (SELECT 1 FROM book_authors ba WHERE ba.book_id = b.id)
+			ON CONFLICT (tenant_id, name) DO NOTHING`,
+			`INSERT INTO book_authors (book_id, author_id)
+			SELECT b.id, a.id
+			FROM books b
+			JOIN authors a ON a.tenant_id = b.tenant_id AND a.name = btrim(b.author)
+			WHERE NOT EXISTS (SELECT 1 FROM book_authors ba WHERE ba.book_id = b.id)
+			ON CONFLICT DO NOTHING`,
+		}
+		for _, statement := range statements {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// migrateSearch adds the full-text search column over title (weight A) and
+// author (weight B) and its GIN index. AutoMigrate can't express generated
+// columns, so this runs as plain SQL and is safe to repeat.
+func migrateSearch(db *gorm.DB) error {
+	statements := []string{
+		`ALTER TABLE books ADD COLUMN IF NOT EXISTS search_vector tsvector
+			GENERATED ALWAYS AS (
+				setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+				setweight(to_tsvector('english', coalesce(author, '')), 'B')
+			) STORED`,
+		`CREATE INDEX IF NOT EXISTS idx_books_search_vector ON books USING GIN (search_vector)`,
+	}
+	for _, statement := range statements {
+		if err := db.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateISBN makes ISBNs unique among each tenant's books and among each
+// tenant's editions. Trashed books keep theirs, so a restore can never
+// collide.
+func migrateISBN(db *gorm.DB) error {
+	statements := []string{
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_books_tenant_isbn10 ON books (tenant_id, isbn10) WHERE isbn10 <> ''`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_books_tenant_isbn13 ON books (tenant_id, isbn13) WHERE isbn13 <> ''`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_editions_tenant_isbn ON editions (tenant_id, isbn) WHERE isbn <> ''`,
+	}
+	for _, statement := range statements {
+		if err := db.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
 }
\ No newline at end of file
@@ -0,0 +1,46 @@
+package events
+
+import (
+	"fmt"
+
+	"github.com/shani34/book-management-system/config"
+	"github.com/shani34/book-management-system/pkg/kafka"
+)
+
+// Message is one serialized event and the topic it is published under.
+type Message = kafka.Message
+
+// EventPublisher delivers serialized events to a sink. PublishBatch
+// delivers several at once, in order; if it fails, any of them may or may
+// not have been delivered.
+type EventPublisher interface {
+	Publish(topic string, message []byte) error
+	PublishBatch(messages []Message) error
+	Close() error
+}
+
+var (
+	_ EventPublisher = (*kafka.Publisher)(nil)
+	_ EventPublisher = (*FilePublisher)(nil)
+	_ EventPublisher = (*MemoryPublisher)(nil)
+	_ EventPublisher = Noop{}
+)
+
+// InitPublisher builds the sink selected by EVENTS_SINK: "kafka", "file",
+// "memory" or "none".
+func InitPublisher() (EventPublisher, error) {
+	cfg := config.Get().Events
+
+	switch cfg.Sink {
+	case "kafka":
+		return kafka.NewPublisher()
+	case "file":
+		return NewFilePublisher(cfg.File)
+	case "memory":
+		return NewMemoryPublisher(), nil
+	case "none":
+		return Noop{}, nil
+	default:
+		return nil, fmt.Errorf("unknown events sink %q", cfg.Sink)
+	}
+}
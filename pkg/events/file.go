@@ -0,0 +1,64 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// FilePublisher appends each event to a file as one JSON object per line
+// (NDJSON), tagged with the topic it was published under.
+type FilePublisher struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+type fileRecord struct {
+	Topic   string          `json:"topic"`
+	Message json.RawMessage `json:"message"`
+}
+
+func NewFilePublisher(path string) (*FilePublisher, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open events file: %w", err)
+	}
+	return &FilePublisher{file: file}, nil
+}
+
+func (p *FilePublisher) Publish(topic string, message []byte) error {
+	record := fileRecord{Topic: topic, Message: message}
+	if !json.Valid(message) {
+		quoted, err := json.Marshal(string(message))
+		if err != nil {
+			return err
+		}
+		record.Message = quoted
+	}
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, err = p.file.Write(append(line, '\n'))
+	return err
+}
+
+func (p *FilePublisher) PublishBatch(messages []Message) error {
+	for _, message := range messages {
+		if err := p.Publish(message.Topic, message.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *FilePublisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.file.Close()
+}
@@ -0,0 +1,47 @@
+package events
+
+import "sync"
+
+// MemoryPublisher records published events so tests can assert on them.
+type MemoryPublisher struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+func NewMemoryPublisher() *MemoryPublisher {
+	return &MemoryPublisher{}
+}
+
+func (p *MemoryPublisher) Publish(topic string, message []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.messages = append(p.messages, Message{
+		Topic: topic,
+		Value: append([]byte(nil), message...),
+	})
+	return nil
+}
+
+func (p *MemoryPublisher) PublishBatch(messages []Message) error {
+	for _, message := range messages {
+		if err := p.Publish(message.Topic, message.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Messages returns a copy of everything published so far.
+func (p *MemoryPublisher) Messages() []Message {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]Message(nil), p.messages...)
+}
+
+func (p *MemoryPublisher) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.messages = nil
+}
+
+func (p *MemoryPublisher) Close() error { return nil }
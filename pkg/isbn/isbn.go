@@ -0,0 +1,128 @@
+// Package isbn validates and converts ISBN-10 and ISBN-13 identifiers.
+package isbn
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	// ErrInvalidLength is returned for input that is neither 10 nor 13
+	// characters once separators are removed.
+	ErrInvalidLength = errors.New("isbn must have 10 or 13 digits")
+	// ErrInvalidCharacter is returned for anything but digits, separators
+	// and a final X in an ISBN-10.
+	ErrInvalidCharacter = errors.New("isbn contains an invalid character")
+	// ErrInvalidChecksum is returned when the check digit does not match.
+	ErrInvalidChecksum = errors.New("isbn check digit does not match")
+)
+
+// Normalize strips hyphens and spaces and upper-cases a trailing x. It does
+// not validate the result.
+func Normalize(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '-' || r == ' ':
+		case r == 'x':
+			b.WriteRune('X')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// Parse normalizes s and checks that it is a valid ISBN-10 or ISBN-13.
+func Parse(s string) (string, error) {
+	n := Normalize(s)
+	switch len(n) {
+	case 10:
+		return n, Validate10(n)
+	case 13:
+		return n, Validate13(n)
+	default:
+		return n, ErrInvalidLength
+	}
+}
+
+// Validate10 checks a normalized ISBN-10: the digits weighted 10 down to 1
+// must sum to a multiple of 11, with X standing for 10 in the last place.
+func Validate10(s string) error {
+	if len(s) != 10 {
+		return ErrInvalidLength
+	}
+	sum := 0
+	for i := 0; i < 10; i++ {
+		var d int
+		switch c := s[i]; {
+		case c >= '0' && c <= '9':
+			d = int(c - '0')
+		case c == 'X' && i == 9:
+			d = 10
+		default:
+			return ErrInvalidCharacter
+		}
+		sum += d * (10 - i)
+	}
+	if sum%11 != 0 {
+		return ErrInvalidChecksum
+	}
+	return nil
+}
+
+// Validate13 checks a normalized ISBN-13: the digits weighted alternately
+// 1 and 3 must sum to a multiple of 10.
+func Validate13(s string) error {
+	if len(s) != 13 {
+		return ErrInvalidLength
+	}
+	sum := 0
+	for i := 0; i < 13; i++ {
+		c := s[i]
+		if c < '0' || c > '9' {
+			return ErrInvalidCharacter
+		}
+		d := int(c - '0')
+		if i%2 == 1 {
+			d *= 3
+		}
+		sum += d
+	}
+	if sum%10 != 0 {
+		return ErrInvalidChecksum
+	}
+	return nil
+}
+
+// To13 converts a valid ISBN-10 to its 978-prefixed ISBN-13.
+func To13(isbn10 string) string {
+	body := "978" + isbn10[:9]
+	sum := 0
+	for i := 0; i < 12; i++ {
+		d := int(body[i] - '0')
+		if i%2 == 1 {
+			d *= 3
+		}
+		sum += d
+	}
+	return body + string(rune('0'+(10-sum%10)%10))
+}
+
+// To10 converts a valid ISBN-13 to ISBN-10. Only 978-prefixed ISBNs have
+// an ISBN-10 form; ok is false for the rest.
+func To10(isbn13 string) (string, bool) {
+	if !strings.HasPrefix(isbn13, "978") {
+		return "", false
+	}
+	body := isbn13[3:12]
+	sum := 0
+	for i := 0; i < 9; i++ {
+		sum += int(body[i]-'0') * (10 - i)
+	}
+	check := (11 - sum%11) % 11
+	if check == 10 {
+		return body + "X", true
+	}
+	return body + string(rune('0'+check)), true
+}
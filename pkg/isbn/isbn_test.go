@@ -0,0 +1,128 @@
+package isbn
+
+import (
+	"errors"
+	"testing"
+)
+
+// pairs are ISBN-10s with their ISBN-13 forms.
+var pairs = []struct{ isbn10, isbn13 string }{
+	{"0306406152", "9780306406157"},
+	{"0441013597", "9780441013593"},
+	{"080442957X", "9780804429573"},
+	{"031242440X", "9780312424404"},
+	{"0000000000", "9780000000002"},
+}
+
+func TestNormalize(t *testing.T) {
+	tests := map[string]string{
+		"978-0-306-40615-7":   "9780306406157",
+		"978 0 306 40615 7":   "9780306406157",
+		" 0-8044-2957-x ":     "080442957X",
+		"0 8044-2957 X":       "080442957X",
+		"--":                  "",
+		"ISBN 978-0306406157": "ISBN9780306406157",
+	}
+	for in, want := range tests {
+		if got := Normalize(in); got != want {
+			t.Errorf("Normalize(%q): got %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		err  error
+	}{
+		{"978-0-306-40615-7", "9780306406157", nil},
+		{"0-306-40615-2", "0306406152", nil},
+		{"0-8044-2957-X", "080442957X", nil},
+		{"0-8044-2957-x", "080442957X", nil},
+		{"979-10-90636-07-1", "9791090636071", nil},
+		{"978-0-306-40615-8", "9780306406158", ErrInvalidChecksum},
+		{"0-306-40615-3", "0306406153", ErrInvalidChecksum},
+		{"0-8044-2957-0", "0804429570", ErrInvalidChecksum},
+		{"X-306-40615-2", "X306406152", ErrInvalidCharacter},
+		{"03064X6152", "03064X6152", ErrInvalidCharacter},
+		{"978-0-306-40615-X", "978030640615X", ErrInvalidCharacter},
+		{"978030640615a", "978030640615a", ErrInvalidCharacter},
+		{"030640615", "030640615", ErrInvalidLength},
+		{"97803064061577", "97803064061577", ErrInvalidLength},
+		{"", "", ErrInvalidLength},
+		{"978.0.306.40615.7", "978.0.306.40615.7", ErrInvalidLength},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		if got != tt.want || !errors.Is(err, tt.err) {
+			t.Errorf("Parse(%q): got %q, %v; want %q, %v", tt.in, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestValidateLength(t *testing.T) {
+	if err := Validate10("9780306406157"); !errors.Is(err, ErrInvalidLength) {
+		t.Errorf("Validate10 of an ISBN-13: got %v, want ErrInvalidLength", err)
+	}
+	if err := Validate13("0306406152"); !errors.Is(err, ErrInvalidLength) {
+		t.Errorf("Validate13 of an ISBN-10: got %v, want ErrInvalidLength", err)
+	}
+}
+
+// TestCheckDigits checks that every other check digit is refused.
+func TestCheckDigits(t *testing.T) {
+	for _, pair := range pairs {
+		for _, c := range "0123456789X" {
+			isbn10 := pair.isbn10[:9] + string(c)
+			if err := Validate10(isbn10); (isbn10 == pair.isbn10) != (err == nil) {
+				t.Errorf("Validate10(%q): %v", isbn10, err)
+			}
+			if c == 'X' {
+				continue
+			}
+			isbn13 := pair.isbn13[:12] + string(c)
+			if err := Validate13(isbn13); (isbn13 == pair.isbn13) != (err == nil) {
+				t.Errorf("Validate13(%q): %v", isbn13, err)
+			}
+		}
+	}
+}
+
+func TestConvert(t *testing.T) {
+	for _, pair := range pairs {
+		if got := To13(pair.isbn10); got != pair.isbn13 {
+			t.Errorf("To13(%q): got %q, want %q", pair.isbn10, got, pair.isbn13)
+		}
+		if got, ok := To10(pair.isbn13); !ok || got != pair.isbn10 {
+			t.Errorf("To10(%q): got %q, %v; want %q", pair.isbn13, got, ok, pair.isbn10)
+		}
+	}
+	// 979 ISBNs were never issued as ISBN-10s.
+	if got, ok := To10("9791090636071"); ok {
+		t.Errorf("To10 of a 979 ISBN: got %q, want none", got)
+	}
+}
+
+// TestConvertRoundTrip converts every ISBN-10 sharing a prefix both ways.
+func TestConvertRoundTrip(t *testing.T) {
+	for i := 0; i < 1000; i++ {
+		body := "306406" + string(rune('0'+i/100)) + string(rune('0'+i/10%10)) + string(rune('0'+i%10))
+		var isbn10 string
+		for _, c := range "0123456789X" {
+			if Validate10(body+string(c)) == nil {
+				isbn10 = body + string(c)
+			}
+		}
+		if isbn10 == "" {
+			t.Fatalf("no check digit for %q", body)
+		}
+		isbn13 := To13(isbn10)
+		if err := Validate13(isbn13); err != nil {
+			t.Fatalf("To13(%q) = %q: %v", isbn10, isbn13, err)
+		}
+		if back, ok := To10(isbn13); !ok || back != isbn10 {
+			t.Fatalf("To10(To13(%q)): got %q, %v", isbn10, back, ok)
+		}
+	}
+}
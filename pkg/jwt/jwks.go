@@ -0,0 +1,89 @@
+package jwt
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+)
+
+// jwk is the subset of RFC 7517 needed for RSA signing keys.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+type jwks struct {
+	Keys []jwk `json:"keys"`
+}
+
+// LoadJWKS reads the RSA public keys from a JWKS file, by key ID.
+func LoadJWKS(path string) (map[string]*rsa.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseJWKS(data)
+}
+
+// ParseJWKS decodes the RSA signing keys of a JWKS document. Keys of other
+// types, or meant for encryption, are skipped.
+func ParseJWKS(data []byte) (map[string]*rsa.PublicKey, error) {
+	var set jwks
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("invalid JWKS: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey)
+	for _, key := range set.Keys {
+		if key.Kty != "RSA" || (key.Use != "" && key.Use != "sig") || (key.Alg != "" && key.Alg != RS256) {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(key.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWKS key %q: bad modulus", key.Kid)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(key.E)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			return nil, fmt.Errorf("invalid JWKS key %q: bad exponent", key.Kid)
+		}
+		if _, ok := keys[key.Kid]; ok {
+			return nil, fmt.Errorf("invalid JWKS: duplicate key ID %q", key.Kid)
+		}
+		keys[key.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	return keys, nil
+}
+
+// MarshalJWKS encodes public keys, by key ID, as a JWKS document.
+func MarshalJWKS(keys map[string]*rsa.PublicKey) ([]byte, error) {
+	kids := make([]string, 0, len(keys))
+	for kid := range keys {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+
+	set := jwks{Keys: []jwk{}}
+	for _, kid := range kids {
+		key := keys[kid]
+		set.Keys = append(set.Keys, jwk{
+			Kty: "RSA",
+			Kid: kid,
+			Use: "sig",
+			Alg: RS256,
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		})
+	}
+	return json.MarshalIndent(set, "", "  ")
+}
@@ -0,0 +1,258 @@
+// Package jwt verifies and signs compact JSON Web Tokens using only the
+// standard library. It supports HS256 with a shared secret and RS256 with
+// public keys from a JWKS document.
+package jwt
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+)
+
+var (
+	ErrMalformed            = errors.New("malformed token")
+	ErrUnsupportedAlgorithm = errors.New("unsupported signing algorithm")
+	ErrUnknownKey           = errors.New("unknown signing key")
+	ErrInvalidSignature     = errors.New("invalid signature")
+	ErrExpired              = errors.New("token has expired")
+	ErrNotYetValid          = errors.New("token is not valid yet")
+	ErrInvalidClaims        = errors.New("invalid claims")
+)
+
+// Claims is the decoded payload of a token. Numeric claims decode as
+// float64, as encoding/json does.
+type Claims map[string]any
+
+// Subject returns the "sub" claim, or "" if it is missing.
+func (c Claims) Subject() string {
+	sub, _ := c["sub"].(string)
+	return sub
+}
+
+// Time returns a NumericDate claim such as "exp" as a time.
+func (c Claims) Time(name string) (time.Time, bool) {
+	switch v := c[name].(type) {
+	case float64:
+		return time.Unix(int64(v), 0), true
+	case json.Number:
+		n, err := v.Int64()
+		return time.Unix(n, 0), err == nil
+	default:
+		return time.Time{}, false
+	}
+}
+
+// Audience returns the "aud" claim, which may be a string or a list.
+func (c Claims) Audience() []string {
+	return c.Strings("aud")
+}
+
+// Strings returns a claim that may be a single string or a list of them,
+// such as "aud" or "roles". Non-string list items are skipped.
+func (c Claims) Strings(name string) []string {
+	switch v := c[name].(type) {
+	case string:
+		return []string{v}
+	case []any:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	default:
+		return nil
+	}
+}
+
+type header struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ,omitempty"`
+	Kid string `json:"kid,omitempty"`
+}
+
+// VerifierConfig configures a Verifier. Secret enables HS256 and Keys
+// (by key ID) enable RS256; with neither, every token is rejected. Issuer
+// and Audience, when set, must match the token. Leeway allows for clock
+// skew when checking exp and nbf.
+type VerifierConfig struct {
+	Secret   []byte
+	Keys     map[string]*rsa.PublicKey
+	Issuer   string
+	Audience string
+	Leeway   time.Duration
+}
+
+type Verifier struct {
+	config VerifierConfig
+	now    func() time.Time
+}
+
+func NewVerifier(config VerifierConfig) *Verifier {
+	return &Verifier{config: config, now: time.Now}
+}
+
+// Verify checks the token's signature and its exp, nbf, iss and aud claims,
+// and returns its claims. Tokens must carry exp.
+func (v *Verifier) Verify(token string) (Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrMalformed
+	}
+
+	var h header
+	if err := decodeSegment(parts[0], &h); err != nil {
+		return nil, err
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	signed := parts[0] + "." + parts[1]
+
+	switch h.Alg {
+	case HS256:
+		if len(v.config.Secret) == 0 {
+			return nil, ErrUnsupportedAlgorithm
+		}
+		if !hmac.Equal(signature, hmacSHA256(v.config.Secret, signed)) {
+			return nil, ErrInvalidSignature
+		}
+	case RS256:
+		key, err := v.rsaKey(h.Kid)
+		if err != nil {
+			return nil, err
+		}
+		digest := sha256.Sum256([]byte(signed))
+		if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+			return nil, ErrInvalidSignature
+		}
+	default:
+		return nil, ErrUnsupportedAlgorithm
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, err
+	}
+	if err := v.checkClaims(claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// rsaKey finds the key a token names. A token without a kid may use the
+// only key there is.
+func (v *Verifier) rsaKey(kid string) (*rsa.PublicKey, error) {
+	if key, ok := v.config.Keys[kid]; ok {
+		return key, nil
+	}
+	if kid == "" && len(v.config.Keys) == 1 {
+		for _, key := range v.config.Keys {
+			return key, nil
+		}
+	}
+	if len(v.config.Keys) == 0 {
+		return nil, ErrUnsupportedAlgorithm
+	}
+	return nil, ErrUnknownKey
+}
+
+func (v *Verifier) checkClaims(claims Claims) error {
+	now := v.now()
+	exp, ok := claims.Time("exp")
+	if !ok {
+		return fmt.Errorf("%w: exp is required", ErrInvalidClaims)
+	}
+	if !now.Before(exp.Add(v.config.Leeway)) {
+		return ErrExpired
+	}
+	if nbf, ok := claims.Time("nbf"); ok && now.Add(v.config.Leeway).Before(nbf) {
+		return ErrNotYetValid
+	}
+	if v.config.Issuer != "" {
+		if iss, _ := claims["iss"].(string); iss != v.config.Issuer {
+			return fmt.Errorf("%w: unexpected issuer", ErrInvalidClaims)
+		}
+	}
+	if v.config.Audience != "" && !contains(claims.Audience(), v.config.Audience) {
+		return fmt.Errorf("%w: unexpected audience", ErrInvalidClaims)
+	}
+	return nil
+}
+
+// Sign encodes claims as a token. key is the shared secret ([]byte) for
+// HS256 or an *rsa.PrivateKey for RS256; kid is put in the header if set.
+func Sign(claims Claims, alg string, key any, kid string) (string, error) {
+	h, err := json.Marshal(header{Alg: alg, Typ: "JWT", Kid: kid})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	signed := base64.RawURLEncoding.EncodeToString(h) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	var signature []byte
+	switch alg {
+	case HS256:
+		secret, ok := key.([]byte)
+		if !ok || len(secret) == 0 {
+			return "", fmt.Errorf("%w: HS256 needs a []byte secret", ErrUnknownKey)
+		}
+		signature = hmacSHA256(secret, signed)
+	case RS256:
+		private, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return "", fmt.Errorf("%w: RS256 needs an *rsa.PrivateKey", ErrUnknownKey)
+		}
+		digest := sha256.Sum256([]byte(signed))
+		if signature, err = rsa.SignPKCS1v15(rand.Reader, private, crypto.SHA256, digest[:]); err != nil {
+			return "", err
+		}
+	default:
+		return "", ErrUnsupportedAlgorithm
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+func hmacSHA256(secret []byte, signed string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(signed))
+	return mac.Sum(nil)
+}
+
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return ErrMalformed
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return ErrMalformed
+	}
+	return nil
+}
+
+func contains(values []string, want string) bool {
+	for _, v := range values {
+		if v == want {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,283 @@
+package jwt
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	secret = []byte("s3cret")
+	now    = time.Unix(1_700_000_000, 0)
+)
+
+// newVerifier returns a verifier for config whose clock reads now.
+func newVerifier(config VerifierConfig) *Verifier {
+	v := NewVerifier(config)
+	v.now = func() time.Time { return now }
+	return v
+}
+
+func newRSAKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func sign(t *testing.T, claims Claims, alg string, key any, kid string) string {
+	t.Helper()
+	token, err := Sign(claims, alg, key, kid)
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	return token
+}
+
+// unsigned builds a token with the given raw header and claims and an
+// arbitrary signature segment.
+func unsigned(header, claims, signature string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(header)) + "." +
+		base64.RawURLEncoding.EncodeToString([]byte(claims)) + "." + signature
+}
+
+func valid() Claims {
+	return Claims{"sub": "jane", "exp": now.Add(time.Hour).Unix()}
+}
+
+func TestVerifyHS256(t *testing.T) {
+	claims, err := newVerifier(VerifierConfig{Secret: secret}).Verify(sign(t, valid(), HS256, secret, ""))
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if claims.Subject() != "jane" {
+		t.Errorf("subject: got %q, want jane", claims.Subject())
+	}
+}
+
+func TestVerifyRS256(t *testing.T) {
+	key, other := newRSAKey(t), newRSAKey(t)
+	keys := map[string]*rsa.PublicKey{"k1": &key.PublicKey, "k2": &other.PublicKey}
+
+	if _, err := newVerifier(VerifierConfig{Keys: keys}).Verify(sign(t, valid(), RS256, key, "k1")); err != nil {
+		t.Errorf("Verify with the right kid: %v", err)
+	}
+	tests := []struct {
+		name string
+		keys map[string]*rsa.PublicKey
+		kid  string
+		want error
+	}{
+		{"wrong kid", keys, "k2", ErrInvalidSignature},
+		{"unknown kid", keys, "k3", ErrUnknownKey},
+		{"no kid with several keys", keys, "", ErrUnknownKey},
+		{"no kid with one key", map[string]*rsa.PublicKey{"k1": &key.PublicKey}, "", nil},
+		{"no keys", nil, "k1", ErrUnsupportedAlgorithm},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newVerifier(VerifierConfig{Keys: tt.keys, Secret: secret}).Verify(sign(t, valid(), RS256, key, tt.kid))
+			if !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsAlgNone(t *testing.T) {
+	v := newVerifier(VerifierConfig{Secret: secret})
+	claims := `{"sub":"jane","exp":` + itoa(now.Add(time.Hour).Unix()) + `}`
+	for _, alg := range []string{"none", "None", "NONE", "", "HS512", "RS512", "ES256"} {
+		for _, signature := range []string{"", "c2ln"} {
+			token := unsigned(`{"alg":"`+alg+`","typ":"JWT"}`, claims, signature)
+			if _, err := v.Verify(token); !errors.Is(err, ErrUnsupportedAlgorithm) {
+				t.Errorf("alg %q, signature %q: got %v, want ErrUnsupportedAlgorithm", alg, signature, err)
+			}
+		}
+	}
+	if _, err := Sign(valid(), "none", nil, ""); !errors.Is(err, ErrUnsupportedAlgorithm) {
+		t.Errorf("Sign with alg none: got %v, want ErrUnsupportedAlgorithm", err)
+	}
+}
+
+// TestVerifyRejectsAlgorithmConfusion signs HS256 tokens with the RSA
+// public key, which an attacker knows, as the HMAC secret.
+func TestVerifyRejectsAlgorithmConfusion(t *testing.T) {
+	key := newRSAKey(t)
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := map[string]*rsa.PublicKey{"k1": &key.PublicKey}
+
+	for name, public := range map[string][]byte{
+		"der": der,
+		"pem": pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}),
+	} {
+		forged := sign(t, valid(), HS256, public, "k1")
+		if _, err := newVerifier(VerifierConfig{Keys: keys}).Verify(forged); !errors.Is(err, ErrUnsupportedAlgorithm) {
+			t.Errorf("%s key as secret, RS256 only: got %v, want ErrUnsupportedAlgorithm", name, err)
+		}
+		if _, err := newVerifier(VerifierConfig{Keys: keys, Secret: secret}).Verify(forged); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("%s key as secret, both algorithms: got %v, want ErrInvalidSignature", name, err)
+		}
+	}
+
+	// The other way round: an RS256 header over an HMAC signature.
+	hs := sign(t, valid(), HS256, secret, "")
+	parts := strings.Split(hs, ".")
+	rs := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RS256","typ":"JWT","kid":"k1"}`)) + "." + parts[1] + "." + parts[2]
+	if _, err := newVerifier(VerifierConfig{Keys: keys, Secret: secret}).Verify(rs); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("HMAC signature under an RS256 header: got %v, want ErrInvalidSignature", err)
+	}
+}
+
+func TestVerifyTimes(t *testing.T) {
+	leeway := 30 * time.Second
+	tests := []struct {
+		name   string
+		claims Claims
+		leeway time.Duration
+		want   error
+	}{
+		{"no exp", Claims{"sub": "jane"}, 0, ErrInvalidClaims},
+		{"exp not a number", Claims{"exp": "tomorrow"}, 0, ErrInvalidClaims},
+		{"expired", Claims{"exp": now.Add(-time.Second).Unix()}, 0, ErrExpired},
+		{"expiring now", Claims{"exp": now.Unix()}, 0, ErrExpired},
+		{"expired within leeway", Claims{"exp": now.Add(-29 * time.Second).Unix()}, leeway, nil},
+		{"expired past leeway", Claims{"exp": now.Add(-30 * time.Second).Unix()}, leeway, ErrExpired},
+		{"nbf in the future", Claims{"exp": now.Add(time.Hour).Unix(), "nbf": now.Add(time.Second).Unix()}, 0, ErrNotYetValid},
+		{"nbf now", Claims{"exp": now.Add(time.Hour).Unix(), "nbf": now.Unix()}, 0, nil},
+		{"nbf within leeway", Claims{"exp": now.Add(time.Hour).Unix(), "nbf": now.Add(30 * time.Second).Unix()}, leeway, nil},
+		{"nbf past leeway", Claims{"exp": now.Add(time.Hour).Unix(), "nbf": now.Add(31 * time.Second).Unix()}, leeway, ErrNotYetValid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := newVerifier(VerifierConfig{Secret: secret, Leeway: tt.leeway})
+			if _, err := v.Verify(sign(t, tt.claims, HS256, secret, "")); !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyIssuerAndAudience(t *testing.T) {
+	v := newVerifier(VerifierConfig{Secret: secret, Issuer: "https://issuer", Audience: "bms"})
+	tests := []struct {
+		name string
+		iss  any
+		aud  any
+		want error
+	}{
+		{"match", "https://issuer", "bms", nil},
+		{"audience list", "https://issuer", []string{"other", "bms"}, nil},
+		{"wrong issuer", "https://evil", "bms", ErrInvalidClaims},
+		{"no issuer", nil, "bms", ErrInvalidClaims},
+		{"wrong audience", "https://issuer", []string{"other"}, ErrInvalidClaims},
+		{"no audience", "https://issuer", nil, ErrInvalidClaims},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := valid()
+			if tt.iss != nil {
+				claims["iss"] = tt.iss
+			}
+			if tt.aud != nil {
+				claims["aud"] = tt.aud
+			}
+			if _, err := v.Verify(sign(t, claims, HS256, secret, "")); !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsTampering(t *testing.T) {
+	key := newRSAKey(t)
+	keys := map[string]*rsa.PublicKey{"k1": &key.PublicKey}
+	v := newVerifier(VerifierConfig{Secret: secret, Keys: keys})
+
+	for alg, token := range map[string]string{
+		HS256: sign(t, valid(), HS256, secret, ""),
+		RS256: sign(t, valid(), RS256, key, "k1"),
+	} {
+		parts := strings.Split(token, ".")
+
+		admin := valid()
+		admin["role"] = "admin"
+		forged := sign(t, admin, HS256, []byte("guess"), "")
+		payload := parts[0] + "." + strings.Split(forged, ".")[1] + "." + parts[2]
+		if _, err := v.Verify(payload); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("%s with a swapped payload: got %v, want ErrInvalidSignature", alg, err)
+		}
+
+		signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+		signature[len(signature)-1] ^= 1
+		flipped := parts[0] + "." + parts[1] + "." + base64.RawURLEncoding.EncodeToString(signature)
+		if _, err := v.Verify(flipped); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("%s with a flipped signature bit: got %v, want ErrInvalidSignature", alg, err)
+		}
+
+		if _, err := v.Verify(parts[0] + "." + parts[1] + "."); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("%s with the signature stripped: got %v, want ErrInvalidSignature", alg, err)
+		}
+	}
+
+	if _, err := newVerifier(VerifierConfig{Secret: []byte("other")}).Verify(sign(t, valid(), HS256, secret, "")); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("HS256 under another secret: got %v, want ErrInvalidSignature", err)
+	}
+}
+
+func TestVerifyMalformed(t *testing.T) {
+	v := newVerifier(VerifierConfig{Secret: secret})
+	token := sign(t, valid(), HS256, secret, "")
+	parts := strings.Split(token, ".")
+	for name, malformed := range map[string]string{
+		"empty":             "",
+		"two segments":      parts[0] + "." + parts[1],
+		"four segments":     token + ".x",
+		"header not base64": "!!." + parts[1] + "." + parts[2],
+		"header not JSON":   base64.RawURLEncoding.EncodeToString([]byte("alg")) + "." + parts[1] + "." + parts[2],
+		"padded signature":  parts[0] + "." + parts[1] + "." + parts[2] + "=",
+	} {
+		if _, err := v.Verify(malformed); !errors.Is(err, ErrMalformed) {
+			t.Errorf("%s: got %v, want ErrMalformed", name, err)
+		}
+	}
+}
+
+func TestJWKSRoundTrip(t *testing.T) {
+	key := newRSAKey(t)
+	data, err := MarshalJWKS(map[string]*rsa.PublicKey{"k1": &key.PublicKey})
+	if err != nil {
+		t.Fatalf("MarshalJWKS: %v", err)
+	}
+	keys, err := ParseJWKS(data)
+	if err != nil {
+		t.Fatalf("ParseJWKS: %v", err)
+	}
+	if _, err := newVerifier(VerifierConfig{Keys: keys}).Verify(sign(t, valid(), RS256, key, "k1")); err != nil {
+		t.Errorf("Verify with parsed keys: %v", err)
+	}
+
+	skipped, err := ParseJWKS([]byte(`{"keys":[{"kty":"EC","kid":"e"},{"kty":"RSA","kid":"enc","use":"enc","n":"AQ","e":"AQAB"}]}`))
+	if err != nil || len(skipped) != 0 {
+		t.Errorf("ParseJWKS of non-signing keys: got %v, %v; want none", skipped, err)
+	}
+	if _, err := ParseJWKS([]byte(`{"keys":[{"kty":"RSA","kid":"a","n":"AQ","e":"AQAB"},{"kty":"RSA","kid":"a","n":"AQ","e":"AQAB"}]}`)); err == nil {
+		t.Error("ParseJWKS with a duplicate kid succeeded")
+	}
+}
+
+func itoa(n int64) string {
+	return strconv.FormatInt(n, 10)
+}
@@ -1,54 +1,95 @@
 package kafka
 
 import (
-    "context"
-    "crypto/tls"
-    "crypto/x509"
-    "io/ioutil"
-    "log"
-    "time"
-
-    "github.com/segmentio/kafka-go"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/shani34/book-management-system/config"
 )
-var Producer *kafka.Writer
-func InitKafkaProducer() {
-    TOPIC_NAME := "TOPIC_NAME"
-
-    keypair, err := tls.LoadX509KeyPair("service.cert", "service.key")
-    if err != nil {
-        log.Printf("Failed to load access key and/or access certificate: %s", err)
-    }
-
-    caCert, err := ioutil.ReadFile("ca.pem")
-    if err != nil {
-        log.Printf("Failed to read CA certificate file: %s", err)
-    }
-
-    caCertPool := x509.NewCertPool()
-    ok := caCertPool.AppendCertsFromPEM(caCert)
-    if !ok {
-        log.Printf("Failed to parse CA certificate file: %s", err)
-    }
-
-    dialer := &kafka.Dialer{
-        Timeout:   10 * time.Second,
-        DualStack: true,
-        TLS: &tls.Config{
-            Certificates: []tls.Certificate{keypair},
-            RootCAs:      caCertPool,
-			InsecureSkipVerify: true,
-        },
-    }
-
-    // init producer
-    Producer = kafka.NewWriter(kafka.WriterConfig{
-        Brokers: []string{"kafka-245868dd-shani-kafka-test-007.c.aivencloud.com:24869"},
-        Topic:   TOPIC_NAME,
-        Dialer:  dialer,
-    })
 
+// batchTimeout is how long the writer waits for a batch to fill before
+// sending it. The relay already hands over whole batches, so waiting longer
+// only adds latency; kafka-go's default is a full second.
+const batchTimeout = 10 * time.Millisecond
+
+// Message is one event to publish under Topic.
+type Message struct {
+	Topic string
+	Value []byte
+}
+
+// Publisher writes events to Kafka. The writer is created without a default
+// topic so every message goes to the topic it is published under.
+type Publisher struct {
+	writer *kafka.Writer
+}
+
+func NewPublisher() (*Publisher, error) {
+	cfg := config.Get().Kafka
+
+	dialer := &kafka.Dialer{
+		Timeout:   10 * time.Second,
+		DualStack: true,
+	}
+	if cfg.TLS {
+		tlsConfig, err := loadTLSConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		dialer.TLS = tlsConfig
+	}
+
+	return &Publisher{
+		writer: kafka.NewWriter(kafka.WriterConfig{
+			Brokers:      cfg.Brokers,
+			Dialer:       dialer,
+			BatchTimeout: batchTimeout,
+		}),
+	}, nil
+}
+
+func (p *Publisher) Publish(topic string, message []byte) error {
+	return p.writer.WriteMessages(context.Background(), kafka.Message{Topic: topic, Value: message})
+}
+
+// PublishBatch writes messages in one call, which kafka-go sends as one
+// request per partition instead of one round trip per message.
+func (p *Publisher) PublishBatch(messages []Message) error {
+	batch := make([]kafka.Message, len(messages))
+	for i, message := range messages {
+		batch[i] = kafka.Message{Topic: message.Topic, Value: message.Value}
+	}
+	return p.writer.WriteMessages(context.Background(), batch...)
+}
+
+func (p *Publisher) Close() error {
+	return p.writer.Close()
 }
 
-func PublishEvent(topic string,  message []byte)error{
-	return Producer.WriteMessages(context.Background(), kafka.Message{Value: []byte(message)})
-}
\ No newline at end of file
+func loadTLSConfig(cfg config.KafkaConfig) (*tls.Config, error) {
+	keypair, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load access key and/or access certificate: %w", err)
+	}
+
+	caCert, err := os.ReadFile(cfg.CAFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA certificate file: %w", err)
+	}
+
+	caCertPool := x509.NewCertPool()
+	if !caCertPool.AppendCertsFromPEM(caCert) {
+		return nil, fmt.Errorf("failed to parse CA certificate file %s", cfg.CAFile)
+	}
+
+	return &tls.Config{
+		Certificates:       []tls.Certificate{keypair},
+		RootCAs:            caCertPool,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}, nil
+}
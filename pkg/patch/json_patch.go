@@ -0,0 +1,276 @@
+package patch
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// operation is one JSON Patch step. Value is a RawMessage rather than a
+// pointer so that "value": null is told apart from a missing value.
+type operation struct {
+	Op    string          `json:"op"`
+	Path  *string         `json:"path"`
+	From  *string         `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// JSONPatch applies an RFC 6902 patch to doc. Operations are applied in
+// order and the patch is all-or-nothing: on error doc is left untouched.
+func JSONPatch(doc, patch []byte) ([]byte, error) {
+	var target interface{}
+	if err := json.Unmarshal(doc, &target); err != nil {
+		return nil, err
+	}
+	var ops []operation
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+
+	for i, op := range ops {
+		var err error
+		if target, err = op.apply(target); err != nil {
+			return nil, fmt.Errorf("operation %d (%s): %w", i, op.Op, err)
+		}
+	}
+	return json.Marshal(target)
+}
+
+func (op operation) apply(doc interface{}) (interface{}, error) {
+	if op.Path == nil {
+		return nil, fmt.Errorf("%w: missing path", ErrInvalidPatch)
+	}
+	path, err := parsePointer(*op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case "add", "replace", "test":
+		if op.Value == nil {
+			return nil, fmt.Errorf("%w: missing value", ErrInvalidPatch)
+		}
+		var value interface{}
+		if err := json.Unmarshal(op.Value, &value); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+		}
+		switch op.Op {
+		case "add":
+			return add(doc, path, value)
+		case "replace":
+			return replace(doc, path, value)
+		default:
+			current, err := get(doc, path)
+			if err != nil {
+				return nil, err
+			}
+			if !reflect.DeepEqual(current, value) {
+				return nil, fmt.Errorf("%w: value at %q differs", ErrTestFailed, *op.Path)
+			}
+			return doc, nil
+		}
+	case "remove":
+		return remove(doc, path)
+	case "move", "copy":
+		if op.From == nil {
+			return nil, fmt.Errorf("%w: missing from", ErrInvalidPatch)
+		}
+		from, err := parsePointer(*op.From)
+		if err != nil {
+			return nil, err
+		}
+		value, err := get(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		if op.Op == "copy" {
+			return add(doc, path, deepCopy(value))
+		}
+		if isProperPrefix(from, path) {
+			return nil, fmt.Errorf("%w: cannot move a value into itself", ErrInvalidPatch)
+		}
+		if doc, err = remove(doc, from); err != nil {
+			return nil, err
+		}
+		return add(doc, path, value)
+	default:
+		return nil, fmt.Errorf("%w: unknown op %q", ErrInvalidPatch, op.Op)
+	}
+}
+
+// parsePointer splits an RFC 6901 JSON Pointer into unescaped tokens.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("%w: pointer %q must start with /", ErrInvalidPatch, pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		// ~ only starts the escapes ~0 and ~1.
+		if strings.Count(token, "~") != strings.Count(token, "~0")+strings.Count(token, "~1") {
+			return nil, fmt.Errorf("%w: pointer %q has an invalid ~ escape", ErrInvalidPatch, pointer)
+		}
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+func isProperPrefix(prefix, path []string) bool {
+	if len(prefix) >= len(path) {
+		return false
+	}
+	for i := range prefix {
+		if prefix[i] != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func get(doc interface{}, path []string) (interface{}, error) {
+	for _, token := range path {
+		switch node := doc.(type) {
+		case map[string]interface{}:
+			value, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("%w: member %q not found", ErrInvalidPatch, token)
+			}
+			doc = value
+		case []interface{}:
+			i, err := arrayIndex(token, len(node)-1)
+			if err != nil {
+				return nil, err
+			}
+			doc = node[i]
+		default:
+			return nil, fmt.Errorf("%w: cannot descend into %q", ErrInvalidPatch, token)
+		}
+	}
+	return doc, nil
+}
+
+func add(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	return update(doc, path, func(container interface{}, token string) (interface{}, error) {
+		switch node := container.(type) {
+		case map[string]interface{}:
+			node[token] = value
+			return node, nil
+		case []interface{}:
+			if token == "-" {
+				return append(node, value), nil
+			}
+			i, err := arrayIndex(token, len(node))
+			if err != nil {
+				return nil, err
+			}
+			node = append(node, nil)
+			copy(node[i+1:], node[i:])
+			node[i] = value
+			return node, nil
+		default:
+			return nil, fmt.Errorf("%w: cannot add to %q", ErrInvalidPatch, token)
+		}
+	})
+}
+
+func replace(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	return update(doc, path, func(container interface{}, token string) (interface{}, error) {
+		switch node := container.(type) {
+		case map[string]interface{}:
+			if _, ok := node[token]; !ok {
+				return nil, fmt.Errorf("%w: member %q not found", ErrInvalidPatch, token)
+			}
+			node[token] = value
+			return node, nil
+		case []interface{}:
+			i, err := arrayIndex(token, len(node)-1)
+			if err != nil {
+				return nil, err
+			}
+			node[i] = value
+			return node, nil
+		default:
+			return nil, fmt.Errorf("%w: cannot replace %q", ErrInvalidPatch, token)
+		}
+	})
+}
+
+func remove(doc interface{}, path []string) (interface{}, error) {
+	if len(path) == 0 {
+		return nil, fmt.Errorf("%w: cannot remove the whole document", ErrInvalidPatch)
+	}
+	return update(doc, path, func(container interface{}, token string) (interface{}, error) {
+		switch node := container.(type) {
+		case map[string]interface{}:
+			if _, ok := node[token]; !ok {
+				return nil, fmt.Errorf("%w: member %q not found", ErrInvalidPatch, token)
+			}
+			delete(node, token)
+			return node, nil
+		case []interface{}:
+			i, err := arrayIndex(token, len(node)-1)
+			if err != nil {
+				return nil, err
+			}
+			return append(node[:i], node[i+1:]...), nil
+		default:
+			return nil, fmt.Errorf("%w: cannot remove %q", ErrInvalidPatch, token)
+		}
+	})
+}
+
+// update walks to the container holding the last token of path, lets fn
+// change it, and stores the result back into its parent. Arrays can be
+// reallocated by fn, which is why the result has to be written back.
+func update(doc interface{}, path []string, fn func(container interface{}, token string) (interface{}, error)) (interface{}, error) {
+	if len(path) == 1 {
+		return fn(doc, path[0])
+	}
+
+	child, err := get(doc, path[:1])
+	if err != nil {
+		return nil, err
+	}
+	child, err = update(child, path[1:], fn)
+	if err != nil {
+		return nil, err
+	}
+
+	switch node := doc.(type) {
+	case map[string]interface{}:
+		node[path[0]] = child
+	case []interface{}:
+		i, _ := arrayIndex(path[0], len(node)-1)
+		node[i] = child
+	}
+	return doc, nil
+}
+
+// arrayIndex parses an array index token, allowing values up to max.
+func arrayIndex(token string, max int) (int, error) {
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("%w: invalid array index %q", ErrInvalidPatch, token)
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || i > max {
+		return 0, fmt.Errorf("%w: array index %q out of range", ErrInvalidPatch, token)
+	}
+	return i, nil
+}
+
+func deepCopy(value interface{}) interface{} {
+	data, _ := json.Marshal(value)
+	var c interface{}
+	_ = json.Unmarshal(data, &c)
+	return c
+}
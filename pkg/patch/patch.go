@@ -0,0 +1,56 @@
+// Package patch applies JSON Merge Patch (RFC 7396) and JSON Patch
+// (RFC 6902) documents to JSON values.
+package patch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+const (
+	MergePatchContentType = "application/merge-patch+json"
+	JSONPatchContentType  = "application/json-patch+json"
+)
+
+var (
+	// ErrInvalidPatch is returned for malformed patches and for operations
+	// that cannot be applied to the document, e.g. a missing path.
+	ErrInvalidPatch = errors.New("invalid patch")
+	// ErrTestFailed is returned when a JSON Patch "test" operation does not
+	// match the document.
+	ErrTestFailed = errors.New("patch test failed")
+)
+
+// MergePatch applies an RFC 7396 merge patch to doc: object members in
+// patch replace those in doc, null members delete them, and any non-object
+// patch replaces doc entirely.
+func MergePatch(doc, patch []byte) ([]byte, error) {
+	var target, p interface{}
+	if err := json.Unmarshal(doc, &target); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(patch, &p); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+	return json.Marshal(mergeValue(target, p))
+}
+
+func mergeValue(target, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]interface{})
+	if !ok {
+		t = make(map[string]interface{})
+	}
+	for key, value := range p {
+		if value == nil {
+			delete(t, key)
+		} else {
+			t[key] = mergeValue(t[key], value)
+		}
+	}
+	return t
+}
@@ -0,0 +1,269 @@
+package patch
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// equalJSON reports whether a and b hold the same JSON value.
+func equalJSON(t *testing.T, a, b []byte) bool {
+	t.Helper()
+	var x, y interface{}
+	if err := json.Unmarshal(a, &x); err != nil {
+		t.Fatalf("decoding %s: %v", a, err)
+	}
+	if err := json.Unmarshal(b, &y); err != nil {
+		t.Fatalf("decoding %s: %v", b, err)
+	}
+	return reflect.DeepEqual(x, y)
+}
+
+// TestJSONPatchRFCExamples runs the examples of RFC 6902, appendix A.
+func TestJSONPatchRFCExamples(t *testing.T) {
+	tests := []struct {
+		name  string
+		doc   string
+		patch string
+		want  string
+		err   error
+	}{
+		{
+			name:  "A.1 adding an object member",
+			doc:   `{"foo":"bar"}`,
+			patch: `[{"op":"add","path":"/baz","value":"qux"}]`,
+			want:  `{"baz":"qux","foo":"bar"}`,
+		},
+		{
+			name:  "A.2 adding an array element",
+			doc:   `{"foo":["bar","baz"]}`,
+			patch: `[{"op":"add","path":"/foo/1","value":"qux"}]`,
+			want:  `{"foo":["bar","qux","baz"]}`,
+		},
+		{
+			name:  "A.3 removing an object member",
+			doc:   `{"baz":"qux","foo":"bar"}`,
+			patch: `[{"op":"remove","path":"/baz"}]`,
+			want:  `{"foo":"bar"}`,
+		},
+		{
+			name:  "A.4 removing an array element",
+			doc:   `{"foo":["bar","qux","baz"]}`,
+			patch: `[{"op":"remove","path":"/foo/1"}]`,
+			want:  `{"foo":["bar","baz"]}`,
+		},
+		{
+			name:  "A.5 replacing a value",
+			doc:   `{"baz":"qux","foo":"bar"}`,
+			patch: `[{"op":"replace","path":"/baz","value":"boo"}]`,
+			want:  `{"baz":"boo","foo":"bar"}`,
+		},
+		{
+			name:  "A.6 moving a value",
+			doc:   `{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`,
+			patch: `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`,
+			want:  `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`,
+		},
+		{
+			name:  "A.7 moving an array element",
+			doc:   `{"foo":["all","grass","cows","eat"]}`,
+			patch: `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`,
+			want:  `{"foo":["all","cows","eat","grass"]}`,
+		},
+		{
+			name:  "A.8 testing a value: success",
+			doc:   `{"baz":"qux","foo":["a",2,"c"]}`,
+			patch: `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2}]`,
+			want:  `{"baz":"qux","foo":["a",2,"c"]}`,
+		},
+		{
+			name:  "A.9 testing a value: error",
+			doc:   `{"baz":"qux"}`,
+			patch: `[{"op":"test","path":"/baz","value":"bar"}]`,
+			err:   ErrTestFailed,
+		},
+		{
+			name:  "A.10 adding a nested member object",
+			doc:   `{"foo":"bar"}`,
+			patch: `[{"op":"add","path":"/child","value":{"grandchild":{}}}]`,
+			want:  `{"foo":"bar","child":{"grandchild":{}}}`,
+		},
+		{
+			name:  "A.11 ignoring unrecognized elements",
+			doc:   `{"foo":"bar"}`,
+			patch: `[{"op":"add","path":"/baz","value":"qux","xyz":123}]`,
+			want:  `{"foo":"bar","baz":"qux"}`,
+		},
+		{
+			name:  "A.12 adding to a nonexistent target",
+			doc:   `{"foo":"bar"}`,
+			patch: `[{"op":"add","path":"/baz/bat","value":"qux"}]`,
+			err:   ErrInvalidPatch,
+		},
+		{
+			name:  "A.14 ~ escape ordering",
+			doc:   `{"/":9,"~1":10}`,
+			patch: `[{"op":"test","path":"/~01","value":10}]`,
+			want:  `{"/":9,"~1":10}`,
+		},
+		{
+			name:  "A.15 comparing strings and numbers",
+			doc:   `{"/":9,"~1":10}`,
+			patch: `[{"op":"test","path":"/~01","value":"10"}]`,
+			err:   ErrTestFailed,
+		},
+		{
+			name:  "A.16 adding an array value",
+			doc:   `{"foo":["bar"]}`,
+			patch: `[{"op":"add","path":"/foo/-","value":["abc","def"]}]`,
+			want:  `{"foo":["bar",["abc","def"]]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := JSONPatch([]byte(tt.doc), []byte(tt.patch))
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("got %s, %v; want %v", got, err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("JSONPatch: %v", err)
+			}
+			if !equalJSON(t, got, []byte(tt.want)) {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONPatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		doc   string
+		patch string
+		want  string
+		err   error
+	}{
+		// Arrays.
+		{"add at the front", `[1,2]`, `[{"op":"add","path":"/0","value":0}]`, `[0,1,2]`, nil},
+		{"add at the end by index", `[1,2]`, `[{"op":"add","path":"/2","value":3}]`, `[1,2,3]`, nil},
+		{"add past the end", `[1,2]`, `[{"op":"add","path":"/3","value":3}]`, ``, ErrInvalidPatch},
+		{"add with -", `[]`, `[{"op":"add","path":"/-","value":1}]`, `[1]`, nil},
+		{"add with - nested", `{"a":{"b":[1]}}`, `[{"op":"add","path":"/a/b/-","value":2}]`, `{"a":{"b":[1,2]}}`, nil},
+		{"remove first", `[1,2,3]`, `[{"op":"remove","path":"/0"}]`, `[2,3]`, nil},
+		{"remove last", `[1,2,3]`, `[{"op":"remove","path":"/2"}]`, `[1,2]`, nil},
+		{"remove past the end", `[1,2,3]`, `[{"op":"remove","path":"/3"}]`, ``, ErrInvalidPatch},
+		{"remove with -", `[1,2,3]`, `[{"op":"remove","path":"/-"}]`, ``, ErrInvalidPatch},
+		{"replace with -", `[1]`, `[{"op":"replace","path":"/-","value":2}]`, ``, ErrInvalidPatch},
+		{"index with a leading zero", `[1,2]`, `[{"op":"replace","path":"/01","value":3}]`, ``, ErrInvalidPatch},
+		{"negative index", `[1,2]`, `[{"op":"remove","path":"/-1"}]`, ``, ErrInvalidPatch},
+		{"member name as index", `[1,2]`, `[{"op":"remove","path":"/a"}]`, ``, ErrInvalidPatch},
+		{"remove then add shifts", `[1,2,3]`, `[{"op":"remove","path":"/0"},{"op":"add","path":"/1","value":9}]`, `[2,9,3]`, nil},
+
+		// Objects and the whole document.
+		{"add replaces a member", `{"a":1}`, `[{"op":"add","path":"/a","value":2}]`, `{"a":2}`, nil},
+		{"add null", `{}`, `[{"op":"add","path":"/a","value":null}]`, `{"a":null}`, nil},
+		{"replace a missing member", `{"a":1}`, `[{"op":"replace","path":"/b","value":2}]`, ``, ErrInvalidPatch},
+		{"remove a missing member", `{"a":1}`, `[{"op":"remove","path":"/b"}]`, ``, ErrInvalidPatch},
+		{"replace the document", `{"a":1}`, `[{"op":"replace","path":"","value":[1]}]`, `[1]`, nil},
+		{"add the document", `{"a":1}`, `[{"op":"add","path":"","value":{"b":2}}]`, `{"b":2}`, nil},
+		{"remove the document", `{"a":1}`, `[{"op":"remove","path":""}]`, ``, ErrInvalidPatch},
+		{"empty member name", `{"":1}`, `[{"op":"replace","path":"/","value":2}]`, `{"":2}`, nil},
+		{"descend into a scalar", `{"a":1}`, `[{"op":"add","path":"/a/b","value":2}]`, ``, ErrInvalidPatch},
+
+		// Escaping.
+		{"~1 is a slash", `{"a/b":1}`, `[{"op":"replace","path":"/a~1b","value":2}]`, `{"a/b":2}`, nil},
+		{"~0 is a tilde", `{"m~n":1}`, `[{"op":"remove","path":"/m~0n"}]`, `{}`, nil},
+		{"~0~1 is not a slash", `{"~/":1,"/":2}`, `[{"op":"test","path":"/~0~1","value":1}]`, `{"~/":1,"/":2}`, nil},
+		{"~ alone is invalid", `{"~":1}`, `[{"op":"remove","path":"/~"}]`, ``, ErrInvalidPatch},
+		{"~2 is invalid", `{"~2":1}`, `[{"op":"remove","path":"/~2"}]`, ``, ErrInvalidPatch},
+
+		// Tests.
+		{"test a missing path", `{"a":1}`, `[{"op":"test","path":"/b","value":1}]`, ``, ErrInvalidPatch},
+		{"test an object", `{"a":{"b":[1,"x",null]}}`, `[{"op":"test","path":"/a","value":{"b":[1,"x",null]}}]`, `{"a":{"b":[1,"x",null]}}`, nil},
+		{"test array order", `{"a":[1,2]}`, `[{"op":"test","path":"/a","value":[2,1]}]`, ``, ErrTestFailed},
+		{"test 1 and 1.0", `{"a":1}`, `[{"op":"test","path":"/a","value":1.0}]`, `{"a":1}`, nil},
+		{"test null", `{"a":null}`, `[{"op":"test","path":"/a","value":null}]`, `{"a":null}`, nil},
+		{"test the document", `[1]`, `[{"op":"test","path":"","value":[1]}]`, `[1]`, nil},
+		{"failed test rolls back", `{"a":1}`, `[{"op":"replace","path":"/a","value":2},{"op":"test","path":"/a","value":1}]`, ``, ErrTestFailed},
+
+		// Move and copy.
+		{"copy a member", `{"a":{"b":1}}`, `[{"op":"copy","from":"/a","path":"/c"}]`, `{"a":{"b":1},"c":{"b":1}}`, nil},
+		{"copy is deep", `{"a":{"b":1}}`, `[{"op":"copy","from":"/a","path":"/c"},{"op":"replace","path":"/c/b","value":2}]`, `{"a":{"b":1},"c":{"b":2}}`, nil},
+		{"copy into an array", `{"a":[1,2]}`, `[{"op":"copy","from":"/a/1","path":"/a/0"}]`, `{"a":[2,1,2]}`, nil},
+		{"copy from a missing path", `{"a":1}`, `[{"op":"copy","from":"/b","path":"/c"}]`, ``, ErrInvalidPatch},
+		{"move to itself", `{"a":1}`, `[{"op":"move","from":"/a","path":"/a"}]`, `{"a":1}`, nil},
+		{"move into its own child", `{"a":{"b":1}}`, `[{"op":"move","from":"/a","path":"/a/c"}]`, ``, ErrInvalidPatch},
+		{"move to a sibling prefix", `{"a":1}`, `[{"op":"move","from":"/a","path":"/ab"}]`, `{"ab":1}`, nil},
+		{"move an array element earlier", `["a","b","c"]`, `[{"op":"move","from":"/2","path":"/0"}]`, `["c","a","b"]`, nil},
+		{"move between arrays", `{"x":[1,2],"y":[]}`, `[{"op":"move","from":"/x/0","path":"/y/-"}]`, `{"x":[2],"y":[1]}`, nil},
+		{"move from a missing path", `{"a":1}`, `[{"op":"move","from":"/b","path":"/c"}]`, ``, ErrInvalidPatch},
+
+		// Malformed patches.
+		{"not an array", `{}`, `{"op":"add","path":"/a","value":1}`, ``, ErrInvalidPatch},
+		{"unknown op", `{}`, `[{"op":"merge","path":"/a","value":1}]`, ``, ErrInvalidPatch},
+		{"missing path", `{}`, `[{"op":"add","value":1}]`, ``, ErrInvalidPatch},
+		{"missing value", `{}`, `[{"op":"add","path":"/a"}]`, ``, ErrInvalidPatch},
+		{"missing from", `{"a":1}`, `[{"op":"move","path":"/b"}]`, ``, ErrInvalidPatch},
+		{"pointer without a slash", `{"a":1}`, `[{"op":"remove","path":"a"}]`, ``, ErrInvalidPatch},
+		{"from without a slash", `{"a":1}`, `[{"op":"copy","from":"a","path":"/b"}]`, ``, ErrInvalidPatch},
+		{"empty patch", `{"a":1}`, `[]`, `{"a":1}`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := JSONPatch([]byte(tt.doc), []byte(tt.patch))
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("got %s, %v; want %v", got, err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("JSONPatch: %v", err)
+			}
+			if !equalJSON(t, got, []byte(tt.want)) {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestMergePatchRFCExamples runs the examples of RFC 7396, appendix A.
+func TestMergePatchRFCExamples(t *testing.T) {
+	tests := []struct {
+		doc, patch, want string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+	for _, tt := range tests {
+		got, err := MergePatch([]byte(tt.doc), []byte(tt.patch))
+		if err != nil {
+			t.Errorf("MergePatch(%s, %s): %v", tt.doc, tt.patch, err)
+			continue
+		}
+		if !equalJSON(t, got, []byte(tt.want)) {
+			t.Errorf("MergePatch(%s, %s): got %s, want %s", tt.doc, tt.patch, got, tt.want)
+		}
+	}
+
+	if _, err := MergePatch([]byte(`{}`), []byte(`{"a":`)); !errors.Is(err, ErrInvalidPatch) {
+		t.Errorf("MergePatch with malformed patch: got %v, want ErrInvalidPatch", err)
+	}
+}
@@ -0,0 +1,33 @@
+package ratelimit
+
+import "log"
+
+// Fallback counts in primary and switches to secondary for any call where
+// primary fails, e.g. Redis going away after startup. While it does, each
+// replica limits on its own.
+type Fallback struct {
+	primary   Limiter
+	secondary Limiter
+}
+
+func NewFallback(primary, secondary Limiter) *Fallback {
+	return &Fallback{primary: primary, secondary: secondary}
+}
+
+func (f *Fallback) Allow(key string, limit Limit) (Result, error) {
+	result, err := f.primary.Allow(key, limit)
+	if err == nil {
+		return result, nil
+	}
+	log.Printf("Primary rate limiter failed, using fallback: %v", err)
+	return f.secondary.Allow(key, limit)
+}
+
+func (f *Fallback) Peek(key string, limit Limit) (Result, error) {
+	result, err := f.primary.Peek(key, limit)
+	if err == nil {
+		return result, nil
+	}
+	log.Printf("Primary rate limiter failed, using fallback: %v", err)
+	return f.secondary.Peek(key, limit)
+}
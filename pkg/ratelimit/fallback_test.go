@@ -0,0 +1,69 @@
+package ratelimit
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/shani34/book-management-system/pkg/redis"
+)
+
+// brokenLimiter fails every call, like a limit store that is down.
+type brokenLimiter struct {
+	calls int
+}
+
+func (b *brokenLimiter) Allow(key string, limit Limit) (Result, error) {
+	b.calls++
+	return Result{}, errors.New("connection refused")
+}
+
+func (b *brokenLimiter) Peek(key string, limit Limit) (Result, error) {
+	b.calls++
+	return Result{}, errors.New("connection refused")
+}
+
+func TestFallbackUsesSecondaryWhenPrimaryFails(t *testing.T) {
+	primary := &brokenLimiter{}
+	secondary, _ := newTestMemory()
+	f := NewFallback(primary, secondary)
+	limit := Limit{Requests: 2, Window: time.Minute}
+
+	for i := 0; i < 2; i++ {
+		result, err := f.Allow("k", limit)
+		if err != nil || !result.Allowed {
+			t.Fatalf("request %d: allowed %v, %v; want allowed by the fallback", i+1, result.Allowed, err)
+		}
+	}
+	if result, err := f.Allow("k", limit); err != nil || result.Allowed {
+		t.Errorf("third request: allowed %v, %v; want refused by the fallback", result.Allowed, err)
+	}
+	if result, err := f.Peek("k", limit); err != nil || result.Allowed {
+		t.Errorf("peek: allowed %v, %v; want refused by the fallback", result.Allowed, err)
+	}
+	if primary.calls != 4 {
+		t.Errorf("primary tried %d times, want every call", primary.calls)
+	}
+}
+
+func TestFallbackWhenRedisErrors(t *testing.T) {
+	// Nothing listens on port 1, so every script call fails.
+	client := goredis.NewClient(&goredis.Options{Addr: "127.0.0.1:1", MaxRetries: -1, DialTimeout: 100 * time.Millisecond})
+	defer client.Close()
+	r := NewRedis(&redis.RedisClient{Client: client})
+
+	limit := Limit{Requests: 1, Window: time.Minute}
+	if _, err := r.Allow("k", limit); err == nil {
+		t.Fatal("Redis limiter without a server succeeded")
+	}
+
+	secondary, _ := newTestMemory()
+	f := NewFallback(r, secondary)
+	if result, err := f.Allow("k", limit); err != nil || !result.Allowed {
+		t.Fatalf("first request: allowed %v, %v; want allowed by the fallback", result.Allowed, err)
+	}
+	if result, err := f.Allow("k", limit); err != nil || result.Allowed {
+		t.Errorf("second request: allowed %v, %v; want refused by the fallback", result.Allowed, err)
+	}
+}
@@ -0,0 +1,51 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often Memory drops the keys of callers whose
+// allowance is full again.
+const sweepInterval = time.Minute
+
+// Memory keeps the counts in process, so each replica limits on its own.
+type Memory struct {
+	mu        sync.Mutex
+	tats      map[string]time.Time
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func NewMemory() *Memory {
+	return &Memory{tats: make(map[string]time.Time), lastSweep: time.Now(), now: time.Now}
+}
+
+func (m *Memory) Allow(key string, limit Limit) (Result, error) {
+	return m.check(key, limit, true)
+}
+
+func (m *Memory) Peek(key string, limit Limit) (Result, error) {
+	return m.check(key, limit, false)
+}
+
+func (m *Memory) check(key string, limit Limit, count bool) (Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	if now.Sub(m.lastSweep) >= sweepInterval {
+		for k, tat := range m.tats {
+			if !tat.After(now) {
+				delete(m.tats, k)
+			}
+		}
+		m.lastSweep = now
+	}
+
+	tat, result := decide(m.tats[key], now, limit)
+	if count {
+		m.tats[key] = tat
+	}
+	return result, nil
+}
@@ -0,0 +1,116 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+// clock is a fake time source that only moves when told to.
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time { return c.now }
+
+func (c *clock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func newTestMemory() (*Memory, *clock) {
+	c := &clock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	m := NewMemory()
+	m.now = c.Now
+	m.lastSweep = c.now
+	return m, c
+}
+
+func TestMemoryBurst(t *testing.T) {
+	m, _ := newTestMemory()
+	limit := Limit{Requests: 5, Window: time.Second}
+
+	for i := 0; i < 5; i++ {
+		result, err := m.Allow("k", limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !result.Allowed {
+			t.Fatalf("request %d refused within the burst", i+1)
+		}
+		if want := 4 - i; result.Remaining != want {
+			t.Errorf("request %d: remaining %d, want %d", i+1, result.Remaining, want)
+		}
+		if want := time.Duration(i+1) * 200 * time.Millisecond; result.Reset != want {
+			t.Errorf("request %d: reset %s, want %s", i+1, result.Reset, want)
+		}
+	}
+
+	result, _ := m.Allow("k", limit)
+	if result.Allowed {
+		t.Fatal("request past the burst allowed")
+	}
+	if result.Remaining != 0 || result.RetryAfter != 200*time.Millisecond || result.Reset != time.Second {
+		t.Errorf("refused: remaining %d, retry after %s, reset %s; want 0, 200ms, 1s",
+			result.Remaining, result.RetryAfter, result.Reset)
+	}
+
+	// Other keys have their own allowance.
+	if result, _ := m.Allow("other", limit); !result.Allowed {
+		t.Error("other key refused")
+	}
+}
+
+func TestMemoryRefill(t *testing.T) {
+	m, c := newTestMemory()
+	limit := Limit{Requests: 5, Window: time.Second}
+	for i := 0; i < 5; i++ {
+		m.Allow("k", limit)
+	}
+
+	c.Advance(199 * time.Millisecond)
+	result, _ := m.Allow("k", limit)
+	if result.Allowed {
+		t.Fatal("allowed before one request was earned back")
+	}
+	if result.RetryAfter != time.Millisecond {
+		t.Errorf("retry after %s, want 1ms", result.RetryAfter)
+	}
+
+	c.Advance(time.Millisecond)
+	if result, _ := m.Allow("k", limit); !result.Allowed || result.Remaining != 0 {
+		t.Fatalf("after 200ms: allowed %v, remaining %d; want one request back", result.Allowed, result.Remaining)
+	}
+	if result, _ := m.Allow("k", limit); result.Allowed {
+		t.Fatal("second request allowed after earning back one")
+	}
+
+	c.Advance(time.Second)
+	if result, _ := m.Allow("k", limit); !result.Allowed || result.Remaining != 4 {
+		t.Errorf("after a full window: allowed %v, remaining %d; want the full burst back", result.Allowed, result.Remaining)
+	}
+}
+
+func TestMemoryPeekDoesNotCount(t *testing.T) {
+	m, _ := newTestMemory()
+	limit := Limit{Requests: 2, Window: time.Second}
+
+	for i := 0; i < 5; i++ {
+		if result, _ := m.Peek("k", limit); !result.Allowed || result.Remaining != 1 {
+			t.Fatalf("peek %d: allowed %v, remaining %d; want 1 left", i+1, result.Allowed, result.Remaining)
+		}
+	}
+	m.Allow("k", limit)
+	m.Allow("k", limit)
+	if result, _ := m.Peek("k", limit); result.Allowed || result.RetryAfter != 500*time.Millisecond {
+		t.Errorf("peek when spent: allowed %v, retry after %s; want refused, 500ms", result.Allowed, result.RetryAfter)
+	}
+}
+
+func TestMemorySweep(t *testing.T) {
+	m, c := newTestMemory()
+	limit := Limit{Requests: 2, Window: time.Second}
+	m.Allow("k", limit)
+
+	c.Advance(sweepInterval)
+	m.Allow("other", limit)
+	if _, ok := m.tats["k"]; ok {
+		t.Error("full key not swept")
+	}
+}
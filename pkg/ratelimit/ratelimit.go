@@ -0,0 +1,171 @@
+package ratelimit
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shani34/book-management-system/config"
+	"github.com/shani34/book-management-system/pkg/redis"
+)
+
+// KeyPrefix starts every key the limiters store.
+const KeyPrefix = "ratelimit:"
+
+// DefaultGroup names the limit applied to route groups without their own.
+const DefaultGroup = "default"
+
+// AuthFailuresGroup names the limit on failed authentication attempts per
+// client IP. Unlike route groups it doesn't fall back to the default
+// limit, but to DefaultAuthFailures.
+const AuthFailuresGroup = "auth"
+
+// DefaultLimits is used when RATE_LIMITS is empty.
+const DefaultLimits = "default=300/1m;admin=60/1m"
+
+// DefaultAuthFailures is the limit on failed authentication attempts when
+// RATE_LIMITS sets none.
+var DefaultAuthFailures = Limit{Requests: 10, Window: time.Minute}
+
+// Limit allows Requests per Window, in bursts of up to Requests.
+type Limit struct {
+	Requests int
+	Window   time.Duration
+}
+
+// interval is the time one request takes to earn back.
+func (l Limit) interval() time.Duration {
+	return l.Window / time.Duration(l.Requests)
+}
+
+func (l Limit) String() string {
+	return fmt.Sprintf("%d/%s", l.Requests, l.Window)
+}
+
+// Result is the outcome of one Allow call. Reset is how long until the
+// caller's full allowance is back; RetryAfter, for refused requests, how
+// long until the next one will be let through.
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+// Limiter counts requests against a limit per key.
+type Limiter interface {
+	Allow(key string, limit Limit) (Result, error)
+	// Peek returns what Allow would, without counting a request.
+	Peek(key string, limit Limit) (Result, error)
+}
+
+var (
+	_ Limiter = (*Memory)(nil)
+	_ Limiter = (*Redis)(nil)
+	_ Limiter = (*Fallback)(nil)
+)
+
+// InitLimiter builds the limiter selected by RATE_LIMIT_DRIVER. Redis shares
+// the counts between replicas; when it cannot be reached, each replica
+// counts on its own instead.
+func InitLimiter() Limiter {
+	cfg := config.Get().RateLimit
+
+	memory := NewMemory()
+	if cfg.Driver == "memory" {
+		return memory
+	}
+	client, err := redis.InitRedis()
+	if err != nil {
+		log.Printf("Redis unavailable, falling back to in-process rate limits: %v", err)
+		return memory
+	}
+	return NewFallback(NewRedis(client), memory)
+}
+
+// decide is the generic cell rate algorithm, a token bucket kept as the
+// single time tat at which the bucket will be full again. It returns the
+// new tat and the result of one request at now.
+func decide(tat, now time.Time, limit Limit) (time.Time, Result) {
+	if tat.Before(now) {
+		tat = now
+	}
+	next := tat.Add(limit.interval())
+	ahead := next.Sub(now)
+	if ahead > limit.Window {
+		return tat, Result{
+			Limit:      limit.Requests,
+			Reset:      tat.Sub(now),
+			RetryAfter: ahead - limit.Window,
+		}
+	}
+	return next, Result{
+		Allowed:   true,
+		Limit:     limit.Requests,
+		Remaining: int((limit.Window - ahead) / limit.interval()),
+		Reset:     ahead,
+	}
+}
+
+// Limits maps route groups to their limit.
+type Limits map[string]Limit
+
+// For returns the limit of group, or the default one. ok is false when
+// neither is set and the group is not limited.
+func (l Limits) For(group string) (limit Limit, ok bool) {
+	if limit, ok = l[group]; ok {
+		return limit, true
+	}
+	limit, ok = l[DefaultGroup]
+	return limit, ok
+}
+
+// ParseLimits reads limits written as "group=requests/window;group=...",
+// e.g. "default=300/1m;admin=60/1m". A window of "s", "m" or "h" means one
+// of that unit.
+func ParseLimits(spec string) (Limits, error) {
+	limits := Limits{}
+	for _, entry := range strings.Split(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		group, value, found := strings.Cut(entry, "=")
+		group = strings.TrimSpace(group)
+		if !found || group == "" {
+			return nil, fmt.Errorf("rate limit %q: want group=requests/window", entry)
+		}
+		limit, err := ParseLimit(value)
+		if err != nil {
+			return nil, fmt.Errorf("rate limit for %s: %w", group, err)
+		}
+		limits[group] = limit
+	}
+	return limits, nil
+}
+
+// ParseLimit reads one limit such as "300/1m".
+func ParseLimit(value string) (Limit, error) {
+	count, window, found := strings.Cut(strings.TrimSpace(value), "/")
+	if !found {
+		return Limit{}, fmt.Errorf("%q: want requests/window", value)
+	}
+	requests, err := strconv.Atoi(count)
+	if err != nil || requests <= 0 {
+		return Limit{}, fmt.Errorf("%q: requests must be a positive number", value)
+	}
+	if window != "" && strings.IndexAny(window[:1], "0123456789") < 0 {
+		window = "1" + window
+	}
+	duration, err := time.ParseDuration(window)
+	if err != nil || duration <= 0 {
+		return Limit{}, fmt.Errorf("%q: window must be a positive duration", value)
+	}
+	if duration/time.Duration(requests) < time.Millisecond {
+		return Limit{}, fmt.Errorf("%q: window too short for that many requests", value)
+	}
+	return Limit{Requests: requests, Window: duration}, nil
+}
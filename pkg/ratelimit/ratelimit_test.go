@@ -0,0 +1,51 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseLimits(t *testing.T) {
+	limits, err := ParseLimits(" default=300/1m; books = 10/s ;admin=60/30s;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Limits{
+		"default": {Requests: 300, Window: time.Minute},
+		"books":   {Requests: 10, Window: time.Second},
+		"admin":   {Requests: 60, Window: 30 * time.Second},
+	}
+	if len(limits) != len(want) {
+		t.Fatalf("got %v, want %v", limits, want)
+	}
+	for group, limit := range want {
+		if limits[group] != limit {
+			t.Errorf("%s: got %v, want %v", group, limits[group], limit)
+		}
+	}
+
+	if limit, ok := limits.For("loans"); !ok || limit != want["default"] {
+		t.Errorf("For(loans): got %v, %v; want the default", limit, ok)
+	}
+	if _, ok := (Limits{}).For("loans"); ok {
+		t.Error("For without a default reported a limit")
+	}
+}
+
+func TestParseLimitsErrors(t *testing.T) {
+	for _, spec := range []string{
+		"default",
+		"=10/1m",
+		"default=10",
+		"default=0/1m",
+		"default=-1/1m",
+		"default=x/1m",
+		"default=10/0s",
+		"default=10/fortnight",
+		"default=10000/1s",
+	} {
+		if _, err := ParseLimits(spec); err == nil {
+			t.Errorf("ParseLimits(%q) succeeded", spec)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package ratelimit
+
+import (
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/shani34/book-management-system/pkg/redis"
+)
+
+// allowScript is decide in Lua, so that reading and writing a key is one
+// atomic step. Times are microseconds on the Redis clock, which all
+// replicas share. The request is only counted when ARGV[3] is 1. It
+// returns allowed (0 or 1), remaining, reset and retry after.
+var allowScript = goredis.NewScript(`
+local clock = redis.call("TIME")
+local now = tonumber(clock[1]) * 1000000 + tonumber(clock[2])
+local interval = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+
+local tat = tonumber(redis.call("GET", KEYS[1]) or now)
+if tat < now then
+	tat = now
+end
+local ahead = tat + interval - now
+if ahead > window then
+	return {0, 0, tat - now, ahead - window}
+end
+if ARGV[3] == "1" then
+	redis.call("SET", KEYS[1], string.format("%d", tat + interval), "PX", math.ceil(ahead / 1000))
+end
+return {1, math.floor((window - ahead) / interval), ahead, 0}
+`)
+
+// Redis keeps the counts in Redis, shared by every replica.
+type Redis struct {
+	client *redis.RedisClient
+}
+
+func NewRedis(client *redis.RedisClient) *Redis {
+	return &Redis{client: client}
+}
+
+func (r *Redis) Allow(key string, limit Limit) (Result, error) {
+	return r.check(key, limit, true)
+}
+
+func (r *Redis) Peek(key string, limit Limit) (Result, error) {
+	return r.check(key, limit, false)
+}
+
+func (r *Redis) check(key string, limit Limit, count bool) (Result, error) {
+	counted := 0
+	if count {
+		counted = 1
+	}
+	values, err := allowScript.Run(redis.Ctx, r.client.Client, []string{key},
+		limit.interval().Microseconds(), limit.Window.Microseconds(), counted).Int64Slice()
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{
+		Allowed:    values[0] == 1,
+		Limit:      limit.Requests,
+		Remaining:  int(values[1]),
+		Reset:      time.Duration(values[2]) * time.Microsecond,
+		RetryAfter: time.Duration(values[3]) * time.Microsecond,
+	}, nil
+}
@@ -42,4 +42,27 @@ func(r *RedisClient) Get(key string) (string, error) {
 
 func(r *RedisClient) Delete(keys ...string) error {
 	return r.Client.Del(Ctx, keys...).Err()
-}
\ No newline at end of file
+}
+
+// DeletePrefix removes every key starting with prefix. It walks the keyspace
+// with SCAN rather than KEYS so large databases are not blocked.
+func (r *RedisClient) DeletePrefix(prefix string) error {
+	iter := r.Client.Scan(Ctx, 0, prefix+"*", 100).Iterator()
+	var batch []string
+	for iter.Next(Ctx) {
+		batch = append(batch, iter.Val())
+		if len(batch) == 100 {
+			if err := r.Client.Del(Ctx, batch...).Err(); err != nil {
+				return err
+			}
+			batch = batch[:0]
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	if len(batch) > 0 {
+		return r.Client.Del(Ctx, batch...).Err()
+	}
+	return nil
+}